@@ -0,0 +1,38 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:12:44
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:12:44
+ */
+
+package circuit
+
+import "context"
+
+////////////////////////////////
+/// 适配器模式
+/// 函数式断路器(way 1)处理的是 Circuit，对象式断路器(way 2)处理的是 Work
+/// 两种写法通过下面的适配器互相转换
+////////////////////////////////
+
+//FromCircuit adapt a Circuit to the work of RequestBreaker, the result is always nil
+func FromCircuit(c Circuit) Work {
+	return func(ctx context.Context) (interface{}, error) {
+		return nil, c(ctx)
+	}
+}
+
+//AsCircuit guard c with rb and return it as a Circuit,
+//it can take the place of Breaker(c, threshold) with the full RequestBreaker behind it
+func AsCircuit(rb *RequestBreaker, c Circuit) Circuit {
+	return func(ctx context.Context) error {
+		return rb.DoCircuit(ctx, c)
+	}
+}
+
+//DoCircuit is like DoContext but runs a Circuit
+func (rb *RequestBreaker) DoCircuit(ctx context.Context, c Circuit) error {
+	_, err := rb.DoContext(ctx, FromCircuit(c))
+	return err
+}
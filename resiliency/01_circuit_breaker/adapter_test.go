@@ -0,0 +1,54 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCircuitRoundTrip(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("adapted"))
+
+	fail := false
+	backend := Circuit(func(ctx context.Context) error {
+		if fail {
+			return errBackendDown
+		}
+		return nil
+	})
+
+	circuit := AsCircuit(rb, backend)
+
+	for i := 0; i < 2; i++ {
+		if err := circuit(context.Background()); err != nil {
+			t.Fatalf("expected success, got %v", err)
+		}
+	}
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 2 {
+		t.Errorf("successes should be counted by the breaker, got %+v", cnt)
+	}
+
+	fail = true
+	for i := 0; i < 3; i++ {
+		if err := circuit(context.Background()); err != errBackendDown {
+			t.Fatalf("expected backend error, got %v", err)
+		}
+	}
+	if rb.State() != StateOpen {
+		t.Fatalf("failures through the circuit should trip the breaker, got %s", rb.State())
+	}
+
+	fail = false
+	if err := circuit(context.Background()); err != ErrServiceUnavailable {
+		t.Errorf("open breaker should reject the circuit, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.RejectedOpen != 1 {
+		t.Errorf("rejection should be counted, got %+v", cnt)
+	}
+
+	//back to a Work, it keeps the error of the circuit
+	res, err := FromCircuit(backend)(context.Background())
+	if res != nil || err != nil {
+		t.Errorf("unexpected %v %v", res, err)
+	}
+}
@@ -0,0 +1,45 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAdaptiveTimeoutGrowsAndResets(t *testing.T) {
+
+	rb := NewRequestBreaker(
+		WithAdaptiveTimeout(2*time.Millisecond, 10*time.Millisecond, 2),
+		WithBreakCondition(func(state State, cnter Counts) bool {
+			return state == StateHalfOpen || cnter.ConsecutiveFailures > 2
+		}))
+
+	failing := func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+	ok := func(ctx context.Context) (interface{}, error) { return nil, nil }
+
+	tripBreaker(t, rb)
+
+	want := []time.Duration{2, 4, 8, 10, 10}
+	for i, w := range want {
+		if rb.openFor != w*time.Millisecond {
+			t.Fatalf("trip %d: expected open for %v, got %v", i, w*time.Millisecond, rb.openFor)
+		}
+		time.Sleep(rb.openFor + time.Millisecond)
+		//the probe fails and the breaker opens again
+		rb.Do(failing)
+		if rb.State() != StateOpen {
+			t.Fatalf("trip %d: breaker should reopen, got %s", i, rb.State())
+		}
+	}
+
+	time.Sleep(rb.openFor + time.Millisecond)
+	rb.Do(ok)
+	if rb.State() != StateClosed {
+		t.Fatalf("breaker should recover, got %s", rb.State())
+	}
+
+	tripBreaker(t, rb)
+	if rb.openFor != 2*time.Millisecond {
+		t.Errorf("open duration should reset to base after recovery, got %v", rb.openFor)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:45:06
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:45:06
+ */
+
+package circuit
+
+import "time"
+
+////////////////////////////////
+/// 断路器自身带来的等待
+/// 请求从不排队等试探名额(半开状态满了立即拒绝)，等待只来自锁的争用
+/// 统计每个走加锁路径的请求等了多久，闭合状态的无锁路径不等待，不统计
+////////////////////////////////
+
+//waitBounds are the upper bounds of the buckets of WaitHistogram, the last bucket has no bound
+var waitBounds = []time.Duration{
+	time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond,
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond,
+}
+
+//WaitHistogram is the distribution of the time requests waited for admission
+type WaitHistogram struct {
+	//Bounds[i] is the upper bound of Counts[i], Counts has one more bucket for longer waits
+	Bounds []time.Duration
+	Counts []uint64
+	Count  uint64
+	Total  time.Duration
+	Max    time.Duration
+}
+
+type waitRecorder struct {
+	counts []uint64
+	count  uint64
+	total  time.Duration
+	max    time.Duration
+}
+
+func (w *waitRecorder) record(wait time.Duration) {
+	if w.counts == nil {
+		w.counts = make([]uint64, len(waitBounds)+1)
+	}
+	bucket := len(waitBounds)
+	for i, bound := range waitBounds {
+		if wait <= bound {
+			bucket = i
+			break
+		}
+	}
+	w.counts[bucket]++
+	w.count++
+	w.total += wait
+	if wait > w.max {
+		w.max = wait
+	}
+}
+
+//AdmissionWaits return how long requests admitted under the mutex waited for the admission decision,
+//rejected requests included, the lock-free requests of closed state never wait and are not recorded
+func (rb *RequestBreaker) AdmissionWaits() WaitHistogram {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	h := WaitHistogram{
+		Bounds: append([]time.Duration(nil), waitBounds...),
+		Counts: make([]uint64, len(waitBounds)+1),
+		Count:  rb.waits.count,
+		Total:  rb.waits.total,
+		Max:    rb.waits.max,
+	}
+	copy(h.Counts, rb.waits.counts)
+	return h
+}
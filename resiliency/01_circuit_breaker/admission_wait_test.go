@@ -0,0 +1,53 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAdmissionWaitsOfSaturatedHalfOpen(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(10*time.Millisecond), MaxRequests(1))
+	tripBreaker(t, rb)
+	time.Sleep(20 * time.Millisecond)
+
+	//占住唯一的试探名额
+	release := make(chan struct{})
+	probing := make(chan struct{})
+	go rb.Do(func(ctx context.Context) (interface{}, error) {
+		close(probing)
+		<-release
+		return nil, nil
+	})
+	<-probing
+	defer close(release)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := rb.Do(succeedWork); err != ErrTooManyRequests {
+				t.Errorf("expected ErrTooManyRequests, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	h := rb.AdmissionWaits()
+	if h.Count < 51 {
+		t.Fatalf("the probe and every rejected caller should be recorded, got %d", h.Count)
+	}
+	if h.Total <= 0 || h.Max <= 0 {
+		t.Errorf("contended callers should report their waits, got total %v max %v", h.Total, h.Max)
+	}
+	var sum uint64
+	for _, n := range h.Counts {
+		sum += n
+	}
+	if sum != h.Count || len(h.Counts) != len(h.Bounds)+1 {
+		t.Errorf("buckets should add up to the count: %+v", h)
+	}
+}
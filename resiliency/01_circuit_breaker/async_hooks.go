@@ -0,0 +1,111 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:41:06
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:41:06
+ */
+
+package circuit
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+////////////////////////////////
+/// 异步执行结果回调
+/// OnResult 和 OutcomeSink 比较重的时候(比如同步写指标)，每个请求都要等它们
+/// 放到有界队列里由一个专门的goroutine执行，队列满了丢最老的，请求永远不等
+////////////////////////////////
+
+// WithAsyncHooks runs OnResult, the logged result metadata and OutcomeSink on a goroutine of the breaker,
+// through a queue of bufferSize events. When the queue is full the oldest event is dropped,
+// so a request never waits for a slow hook, see DroppedHooks. The hooks of one breaker still run
+// one at a time and in the order of the requests, but after the request has returned.
+// State change callbacks and OutcomeHooks stay synchronous. Call StopHooks when done with the breaker,
+// the goroutine also stops once the scope of NewRequestBreakerWithContext is done.
+func WithAsyncHooks(bufferSize int) Option {
+	return func(opts *Options) {
+		opts.AsyncHooks = bufferSize
+	}
+}
+
+//hookQueue is the bounded queue of WithAsyncHooks
+type hookQueue struct {
+	mutex   sync.RWMutex //入队持读锁，关闭持写锁，不会向关闭的channel发送
+	closed  bool
+	events  chan func()
+	done    chan struct{}
+	dropped uint64
+}
+
+func newHookQueue(size int) *hookQueue {
+	q := &hookQueue{events: make(chan func(), size), done: make(chan struct{})}
+	go q.run()
+	return q
+}
+
+func (q *hookQueue) run() {
+	defer close(q.done)
+	for fn := range q.events {
+		fn()
+	}
+}
+
+//push queue fn without blocking, dropping the oldest events when full, false once the queue is stopped
+func (q *hookQueue) push(fn func()) bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	if q.closed {
+		return false
+	}
+	for {
+		select {
+		case q.events <- fn:
+			return true
+		default:
+		}
+		select {
+		case <-q.events:
+			atomic.AddUint64(&q.dropped, 1)
+		default:
+		}
+	}
+}
+
+//stop run the queued events and wait for the goroutine to return
+func (q *hookQueue) stop() {
+	q.mutex.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.events)
+	}
+	q.mutex.Unlock()
+	<-q.done
+}
+
+//hook run fn on the hook goroutine with WithAsyncHooks, or right away
+func (rb *RequestBreaker) hook(fn func()) {
+	if rb.hooks == nil || !rb.hooks.push(fn) {
+		fn()
+	}
+}
+
+//StopHooks run the hooks still queued by WithAsyncHooks and stop its goroutine,
+//hooks of later requests run synchronously. It does nothing without WithAsyncHooks.
+func (rb *RequestBreaker) StopHooks() {
+	rb.lazyInit()
+	if rb.hooks != nil {
+		rb.hooks.stop()
+	}
+}
+
+//DroppedHooks return how many hook events WithAsyncHooks dropped because its queue was full
+func (rb *RequestBreaker) DroppedHooks() uint64 {
+	rb.lazyInit()
+	if rb.hooks == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&rb.hooks.dropped)
+}
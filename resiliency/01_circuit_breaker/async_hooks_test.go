@@ -0,0 +1,93 @@
+package circuit
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAsyncHooksRunForEveryOutcome(t *testing.T) {
+
+	var results, outcomes int64
+	rb := NewRequestBreaker(
+		WithAsyncHooks(64),
+		WithOnResult(func(name string, err error, meta map[string]string) { atomic.AddInt64(&results, 1) }),
+		WithOutcomeSink(func(record OutcomeRecord) { atomic.AddInt64(&outcomes, 1) }),
+	)
+
+	for i := 0; i < 10; i++ {
+		rb.Do(succeedWork)
+	}
+	rb.Do(failWork)
+
+	//StopHooks 会先执行完队列里的回调
+	rb.StopHooks()
+	if atomic.LoadInt64(&results) != 11 || atomic.LoadInt64(&outcomes) != 11 {
+		t.Fatalf("hooks should run for every outcome, got %d results and %d outcomes", results, outcomes)
+	}
+
+	rb.Do(succeedWork)
+	if atomic.LoadInt64(&results) != 12 || atomic.LoadInt64(&outcomes) != 12 {
+		t.Errorf("hooks should run synchronously after StopHooks, got %d results and %d outcomes", results, outcomes)
+	}
+}
+
+func TestAsyncHooksNeverBlockRequests(t *testing.T) {
+
+	const requests = 100
+
+	release := make(chan struct{})
+	var once sync.Once
+	var ran int64
+	rb := NewRequestBreaker(
+		WithAsyncHooks(4),
+		WithOutcomeSink(func(record OutcomeRecord) {
+			once.Do(func() { <-release }) //第一个回调卡住，队列一直是满的
+			atomic.AddInt64(&ran, 1)
+		}),
+	)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < requests; i++ {
+			rb.Do(succeedWork)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("requests should not wait for a stuck hook")
+	}
+
+	close(release)
+	rb.StopHooks()
+
+	dropped := rb.DroppedHooks()
+	if dropped == 0 {
+		t.Error("a full queue should drop events")
+	}
+	if got := uint64(atomic.LoadInt64(&ran)) + dropped; got != requests {
+		t.Errorf("every event should either run or be dropped, got %d of %d", got, requests)
+	}
+	if atomic.LoadInt64(&ran) < 4 {
+		t.Errorf("StopHooks should run the queued events, only %d ran", ran)
+	}
+}
+
+func TestAsyncHooksStopWithScope(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+
+	scope, cancel := context.WithCancel(context.Background())
+	rb := NewRequestBreakerWithContext(scope, WithAsyncHooks(8), WithOutcomeSink(func(OutcomeRecord) {}))
+	rb.Do(succeedWork)
+	cancel()
+
+	if after := waitGoroutines(before); after > before {
+		t.Errorf("the hook goroutine should stop with the scope, %d goroutines before and %d after", before, after)
+	}
+}
@@ -0,0 +1,97 @@
+package circuit
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func succeedWork(ctx context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+func BenchmarkDoClosedSuccess(b *testing.B) {
+
+	rb := NewRequestBreaker(Interval(time.Hour), Expiry(time.Now().Add(time.Hour)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rb.Do(succeedWork)
+	}
+}
+
+func BenchmarkDoParallel(b *testing.B) {
+
+	rb := NewRequestBreaker(Interval(time.Hour), Expiry(time.Now().Add(time.Hour)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			rb.Do(succeedWork)
+		}
+	})
+}
+
+//BenchmarkDoParallelSharded is BenchmarkDoParallel with the successes spread over shards,
+//compare them with -cpu 1,4,16
+func BenchmarkDoParallelSharded(b *testing.B) {
+
+	rb := NewRequestBreaker(Interval(time.Hour), Expiry(time.Now().Add(time.Hour)),
+		WithSuccessShards(runtime.GOMAXPROCS(0)))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			rb.Do(succeedWork)
+		}
+	})
+}
+
+//BenchmarkCounts should report 0 allocs/op, counters is returned by value
+func BenchmarkCounts(b *testing.B) {
+
+	rb := NewRequestBreaker()
+	rb.Do(succeedWork)
+	rb.Do(failWork)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	var sink uint32
+	for i := 0; i < b.N; i++ {
+		sink += rb.Counts().Requests
+	}
+	_ = sink
+}
+
+func TestFastPathKeepsTransitions(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(time.Millisecond))
+
+	for i := 0; i < 100; i++ {
+		rb.Do(succeedWork)
+	}
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 100 || cnt.ConsecutiveSuccesses != 100 {
+		t.Fatalf("lock-free successes should be counted, got %+v", cnt)
+	}
+
+	tripBreaker(t, rb)
+
+	//successes admitted before the trip must not leak into the open state
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Fatalf("expected rejection, got %v", err)
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	rb.Do(succeedWork)
+	if rb.State() != StateClosed {
+		t.Fatalf("breaker should recover, got %s", rb.State())
+	}
+	rb.Do(succeedWork)
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 1 {
+		t.Errorf("new generation should start from scratch, got %+v", cnt)
+	}
+}
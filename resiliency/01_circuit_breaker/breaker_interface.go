@@ -0,0 +1,29 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:00:54
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:00:54
+ */
+
+package circuit
+
+import "context"
+
+//IBreaker is what most callers need from a breaker, depend on it to inject fakes in tests,
+//see NoopBreaker and AlwaysOpenBreaker in package circuittest
+type IBreaker interface {
+	Do(work func(ctx context.Context) (interface{}, error)) (interface{}, error)
+	DoContext(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error)
+	State() State
+	AllowRequest() bool
+	Name() string
+}
+
+var _ IBreaker = (*RequestBreaker)(nil)
+
+//Name return the name of breaker set by ActionName
+func (rb *RequestBreaker) Name() string {
+	rb.lazyInit()
+	return rb.options.Name
+}
@@ -3,18 +3,26 @@
  * @Author: Edward
  * @Date: 2020-06-02 23:57:40
  * @Last Modified by: Edward
- * @Last Modified time: 2020-06-03 23:50:17
+ * @Last Modified time: 2026-10-14 18:28:57
  */
 
 package circuit
 
 import (
 	"context"
+	"log/slog"
+	"math/rand"
 	"time"
 )
 
-//BreakConditionWatcher check state
-type BreakConditionWatcher func(state State, cnter counters) bool
+//BreakConditionWatcher check state, cnter is an immutable copy of the counters taken under the mutex, callers may keep it
+type BreakConditionWatcher func(state State, cnter Counts) bool
+
+//StateCheckerContextHandler check state knowing which breaker and state it is called for
+type StateCheckerContextHandler func(name string, state State, counts Counts) bool
+
+//TripDecisionHandler decide whether to open the breaker and how long to keep it open, 0 openFor means the configured Timeout
+type TripDecisionHandler func(state State, counts Counts) (trip bool, openFor time.Duration)
 
 //StateChangedEventHandler set event handle
 type StateChangedEventHandler func(name string, from State, to State)
@@ -22,17 +30,111 @@ type StateChangedEventHandler func(name string, from State, to State)
 //Option set Options
 type Option func(opts *Options)
 
-//Options for breaker
+// Options for breaker.
+//
+// The callbacks of a request run in this order, one after another:
+//  1. OutcomeHooks Classify, then Record
+//  2. OnResult, then the logged result metadata
+//  3. if counting the outcome changes the state: the transition log, OnStateChanged,
+//     OnStateChangedReason, then the observers of Events in the order they subscribed
+//  4. OutcomeSink, with the state after counting
+//
+// With WithAsyncHooks, step 2 and 4 run later on the hook goroutine, still in this order.
+//
+// A transition when the request is admitted, such as open to half-open, runs step 3 before the work.
+// A rejected request runs none of them, only the rejection log then OnReject.
 type Options struct {
 	Name               string
 	Expiry             time.Time
 	Interval, Timeout  time.Duration
 	MaxRequests        uint32
-	CanOpen            BreakConditionWatcher //是否应该断开电路(打开电路开关)
-	CanClose           BreakConditionWatcher //if we should close switch
+	CanOpen            BreakConditionWatcher      //是否应该断开电路(打开电路开关)
+	CanOpenContext     StateCheckerContextHandler //设置后代替CanOpen
+	CanOpenFor         TripDecisionHandler        //设置后代替CanOpenContext和CanOpen，还能决定打开多久
+	CanClose           BreakConditionWatcher      //if we should close switch
 	OnStateChanged     StateChangedEventHandler
 	ShoulderHalfToOpen uint32
+	CountProbes        bool //半开状态的试探结果是否计入TotalSuccesses/TotalFailures
+	AdaptiveTimeout    *AdaptiveTimeout
+	MaxInFlight        uint32 //闭合状态下最大并发请求数，超过的请求被丢弃，0表示不限制
 	Ctx                context.Context
+	Clock              Clock
+	Counter            ICounter      //为空时使用内置的计数器
+	ResponseCacheTTL   time.Duration //DoWithCache 缓存的成功结果的有效期
+	Logger             *slog.Logger
+	IntervalJitter     float64       //每次闭合周期的长度在 Interval±IntervalJitter*Interval 之间随机
+	JitterRand         *rand.Rand    //为空时使用全局随机数
+	HealthAlpha        float64       //健康分EWMA中最新一次结果的权重
+	LatencyTarget      time.Duration //超过目标延迟的成功按比例扣分，0表示不考虑延迟
+	ChaosRate          float64       //放行的请求中注入故障的比例，0表示关闭
+	ChaosRand          *rand.Rand
+	Warmup             time.Duration //创建或Reset之后的这段时间内只计数，不打开
+	RequestTimeout     time.Duration //每个请求默认的超时，0表示不限制
+	HistorySize        int           //保留最近多少次状态变化，0表示不保留
+	ErrorGroup         func(error) string
+	MinErrorGroups     uint32 //至少有这么多种错误失败过才能打开
+	OnResult           ResultHandler
+	//半开状态下允许失败的试探次数，达到后重新打开，0表示仍由CanOpen决定
+	HalfOpenFailureTolerance uint32
+	PriorityProbeGrace       time.Duration //半开状态开始的这段时间里，只有高优先级的请求可以试探
+	LatencyQuantile          float64       //闭合周期内延迟的这个分位数超过LatencyThreshold就打开，0表示关闭
+	LatencyThreshold         time.Duration
+	Tracer                   RequestTracer
+	ReservationTTL           time.Duration //Prepare 得到的令牌的有效期
+	IgnoreCanceled           bool          //调用方取消的请求不计数，默认开启
+	EligibleProbesOnly       bool          //半开状态只放行WithProbeEligible标记的请求
+	NilResult                NilResultPolicy
+	RecoveryVerifier         func(ctx context.Context) bool //从半开状态闭合之前的最后确认
+	Fallbacks                []Fallback
+	FallbackOnFailure        bool //请求执行失败也走降级链，默认只有被拒绝时才走
+	ManualRecoveryOnly       bool //打开之后只有Reset才能闭合
+	OutcomeHooks             OutcomeHooks
+	FlappingWindow           time.Duration //在这个窗口内打开超过FlappingThreshold次就调用OnFlapping
+	FlappingThreshold        int
+	OnFlapping               FlappingHandler
+	ForceTimeout             bool   //请求的context结束就返回，不等忽略取消的work
+	CloseSuccesses           uint32 //半开状态最近CloseWindow次试探里成功CloseSuccesses次就闭合，0表示按连续成功
+	CloseWindow              uint32
+	MaxBackoff               time.Duration //函数式断路器(Breaker)等待时间的上限
+	OutcomeSink              OutcomeSink
+	AverageWindow            int //最近这么多次请求的平均延迟超过AverageThreshold就打开，0表示关闭
+	AverageThreshold         time.Duration
+	CancelInflightOnTrip     bool //打开时取消本次打开之前放行、还在执行的请求
+	Severity                 func(error) Severity
+	WeightedThreshold        uint32 //本代失败的加权和达到这个值就打开，0表示关闭
+	PersistentWeight         uint32 //一次持续性失败算几次
+	//断路器的生命周期，结束后拒绝所有请求并取消执行中的请求
+	Scope context.Context
+	//CanOpen 对应的注册过的打开条件，为空表示自定义的条件
+	TripPolicy string
+	//每次打开的时长在 ±TimeoutJitter 的比例内随机
+	TimeoutJitter float64
+	//状态变化时连同原因一起通知
+	OnStateChangedReason StateChangedReasonHandler
+	//无锁计数成功的分片数，不大于1表示不分片
+	SuccessShards int
+	//请求被拒绝(没有执行)时调用，失败不会调用
+	OnReject RejectHandler
+	//构造之后的第一个请求总是作为试探放行
+	InitialProbe bool
+	//只运行状态机，不拒绝请求
+	DryRun bool
+	//闭合周期结束时成功率低于这个值就打开，0表示关闭
+	MinIntervalSuccessRatio float64
+	//请求数少于这个值的周期不判断成功率
+	MinIntervalRequests uint32
+	//大于0时结果回调在断路器的goroutine里异步执行，这是队列长度
+	AsyncHooks int
+	//放行时离调用方的deadline不到这么久的请求，失败不计数
+	MinBudget time.Duration
+	//打开阈值的初始值，为空表示由 CanOpen 决定
+	TripThreshold *TripThreshold
+}
+
+//AdaptiveTimeout grows the open duration on repeated trips, just like exponential backoff
+type AdaptiveTimeout struct {
+	Base, Max time.Duration
+	Factor    float64
 }
 
 //ActionName of breaker
@@ -92,6 +194,25 @@ func WithStateChanged(handler StateChangedEventHandler) Option {
 func WithBreakCondition(whenCondition BreakConditionWatcher) Option {
 	return func(opts *Options) {
 		opts.CanOpen = whenCondition
+		opts.TripPolicy = ""
+	}
+}
+
+//WithReadyToTripContext set the condition to open the breaker with the name and state of breaker,
+//it takes precedence over WithBreakCondition
+func WithReadyToTripContext(whenCondition StateCheckerContextHandler) Option {
+	return func(opts *Options) {
+		opts.CanOpenContext = whenCondition
+	}
+}
+
+// WithReadyToTrip sets a condition which also decides how long the breaker stays open,
+// e.g. trip and stay open for 5 minutes because this looks like a hard outage.
+// A zero openFor falls back to Timeout or WithAdaptiveTimeout.
+// It takes precedence over WithReadyToTripContext and WithBreakCondition.
+func WithReadyToTrip(whenCondition TripDecisionHandler) Option {
+	return func(opts *Options) {
+		opts.CanOpenFor = whenCondition
 	}
 }
 
@@ -101,3 +222,136 @@ func WithCloseCondition(whenCondition BreakConditionWatcher) Option {
 		opts.CanClose = whenCondition
 	}
 }
+
+//WithCountProbes set whether outcomes of half-open trial requests are folded into
+//TotalSuccesses/TotalFailures, by default they are counted apart as ProbeSuccesses/ProbeFailures
+func WithCountProbes(countProbes bool) Option {
+	return func(opts *Options) {
+		opts.CountProbes = countProbes
+	}
+}
+
+//WithAdaptiveTimeout make the open duration start at base and multiply by factor on each
+//consecutive trip up to max, it falls back to base after a clean recovery.
+//Timeout is ignored when adaptive timeout is set
+func WithAdaptiveTimeout(base, max time.Duration, factor float64) Option {
+	return func(opts *Options) {
+		opts.AdaptiveTimeout = &AdaptiveTimeout{Base: base, Max: max, Factor: factor}
+	}
+}
+
+//WithLoadShedding shed requests beyond maxInFlight concurrent ones in closed state with ErrLoadShed
+func WithLoadShedding(maxInFlight uint32) Option {
+	return func(opts *Options) {
+		opts.MaxInFlight = maxInFlight
+	}
+}
+
+//WithClock set the clock used for all timing of breaker
+func WithClock(clock Clock) Option {
+	return func(opts *Options) {
+		opts.Clock = clock
+	}
+}
+
+//WithCounter set the ICounter of breaker, each breaker needs its own counter
+func WithCounter(counter ICounter) Option {
+	return func(opts *Options) {
+		opts.Counter = counter
+	}
+}
+
+//WithResponseCache let DoWithCache serve the last successful result for ttl while the breaker rejects requests
+func WithResponseCache(ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.ResponseCacheTTL = ttl
+	}
+}
+
+//WithIntervalJitter randomize each closed Interval within ±fraction of the configured value,
+//so that replicas started together do not reset their counters at the same moment, fraction is clamped to [0, maxJitter]
+func WithIntervalJitter(fraction float64) Option {
+	return func(opts *Options) {
+		opts.IntervalJitter = clampJitter(fraction)
+	}
+}
+
+//maxJitter is the largest jitter fraction, a jittered duration stays at least a tenth of the configured one
+const maxJitter = 0.9
+
+//clampJitter keep fraction within [0, maxJitter], at 1 or more a jittered duration could be 0 or negative
+func clampJitter(fraction float64) float64 {
+	if fraction < 0 {
+		return 0
+	}
+	if fraction > maxJitter {
+		return maxJitter
+	}
+	return fraction
+}
+
+//WithTimeoutJitter randomize how long each trip stays open within ±fraction of Timeout or the adaptive timeout,
+//so that replicas tripped together do not all probe the recovering backend at the same moment,
+//fraction is clamped to [0, maxJitter] like WithIntervalJitter
+func WithTimeoutJitter(fraction float64) Option {
+	return func(opts *Options) {
+		opts.TimeoutJitter = clampJitter(fraction)
+	}
+}
+
+//WithJitterRand set the random source of interval and timeout jitter, it is only used with the mutex held
+func WithJitterRand(rnd *rand.Rand) Option {
+	return func(opts *Options) {
+		opts.JitterRand = rnd
+	}
+}
+
+//WithWarmup keep the breaker from tripping for d after it is created or Reset,
+//outcomes are still counted
+func WithWarmup(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.Warmup = d
+	}
+}
+
+//WithRequestTimeout give every request a deadline of d unless its context has an earlier one,
+//a request running out of time is counted as a failure, context.Cause of it is ErrTimeout
+func WithRequestTimeout(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.RequestTimeout = d
+	}
+}
+
+//WithHalfOpenFailureTolerance reopen a half-open breaker once n probes have failed in the half-open window,
+//instead of asking the break condition, successes still count toward closing
+func WithHalfOpenFailureTolerance(n uint32) Option {
+	return func(opts *Options) {
+		opts.HalfOpenFailureTolerance = n
+	}
+}
+
+//WithIgnoreContextCanceled set whether a request canceled by its caller is ignored, true by default,
+//an ignored request counts as neither success nor failure and gives back its half-open probe slot.
+//Only the caller's own cancellation is ignored, a deadline, even the one of WithRequestTimeout, still counts as failure
+func WithIgnoreContextCanceled(ignore bool) Option {
+	return func(opts *Options) {
+		opts.IgnoreCanceled = ignore
+	}
+}
+
+//WithRecoveryVerifier ask verify before a half-open breaker closes, such as whether the health endpoint
+//of the dependency is green. While verify returns false the breaker stays half-open and keeps probing.
+//verify is called with the mutex of breaker held, it must be fast and must not use the breaker
+func WithRecoveryVerifier(verify func(ctx context.Context) bool) Option {
+	return func(opts *Options) {
+		opts.RecoveryVerifier = verify
+	}
+}
+
+//WithManualRecoveryOnly keep a tripped breaker open until Reset is called when manual is true,
+//it never turns half-open by itself, so nothing probes a dependency on which silent recovery is dangerous
+func WithManualRecoveryOnly(manual bool) Option {
+	return func(opts *Options) {
+		opts.ManualRecoveryOnly = manual
+	}
+}
@@ -0,0 +1,166 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:10:29
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:30:41
+ */
+
+package circuit
+
+import "time"
+
+////////////////////////////////
+/// 状态模式
+/// 每个状态一个对象，各自决定请求能否放行、成功或失败之后转到哪个状态
+/// 断路器只负责计数和执行状态转换(changeStateTo)
+////////////////////////////////
+
+//breakerState is the behaviour of breaker in one state,
+//the returned State is the state to move to, CurrentState() means stay
+type breakerState interface {
+	CurrentState() State
+	//OnRequest decide whether a request tagged with tags is admitted at now, must be called with the mutex held
+	OnRequest(rb *RequestBreaker, now time.Time, tags requestTags) (State, error)
+	//OnSuccess decide the next state after a success has been counted, cnt is the snapshot taken right after counting
+	OnSuccess(rb *RequestBreaker, cnt Counts) State
+	//OnFailure decide the next state after a failure has been counted, cnt is the snapshot taken right after counting
+	OnFailure(rb *RequestBreaker, cnt Counts) State
+}
+
+//状态对象没有自己的数据，全部共享
+var (
+	closed   breakerState = closedState{}
+	open     breakerState = openState{}
+	halfOpen breakerState = halfOpenState{}
+)
+
+//current return the state object of current state
+func (rb *RequestBreaker) current() breakerState {
+	switch rb.state {
+	case StateOpen:
+		return open
+	case StateHalfOpen:
+		return halfOpen
+	}
+	return closed
+}
+
+type closedState struct{}
+
+func (closedState) CurrentState() State { return StateClosed }
+
+func (closedState) OnRequest(rb *RequestBreaker, now time.Time, tags requestTags) (State, error) {
+	//统计周期到期，计数器重新开始，成功率太低的周期直接打开
+	if rb.expiresAt.Before(now) {
+		if rb.intervalTrip(now) {
+			return StateOpen, nil
+		}
+		rb.newGeneration()
+		rb.setExpiry(now.Add(rb.nextInterval()))
+	}
+	if rb.options.MaxInFlight > 0 && rb.inflightCount() >= int32(rb.options.MaxInFlight) {
+		return StateClosed, ErrLoadShed
+	}
+	return StateClosed, nil
+}
+
+func (closedState) OnSuccess(rb *RequestBreaker, cnt Counts) State {
+	if rb.latencyTrip() {
+		return StateOpen
+	}
+	return StateClosed
+}
+
+func (closedState) OnFailure(rb *RequestBreaker, cnt Counts) State {
+	if rb.latencyTrip() || rb.weightedTrip() || rb.canOpen(StateClosed, cnt) {
+		return StateOpen
+	}
+	return StateClosed
+}
+
+type openState struct{}
+
+func (openState) CurrentState() State { return StateOpen }
+
+func (openState) OnRequest(rb *RequestBreaker, now time.Time, tags requestTags) (State, error) {
+	//打开的时间到了，转到半开状态，放行这个试探请求
+	if rb.canRecover(now) {
+		return StateHalfOpen, nil
+	}
+	return StateOpen, ErrServiceUnavailable
+}
+
+//canRecover report whether an open breaker may turn half-open at now, must be called with the mutex held
+func (rb *RequestBreaker) canRecover(now time.Time) bool {
+	return !rb.options.ManualRecoveryOnly && rb.expiresAt.Before(now)
+}
+
+//打开状态下只有绕过断路器的请求会执行，结果只计数，不改变状态
+func (openState) OnSuccess(rb *RequestBreaker, cnt Counts) State { return StateOpen }
+
+func (openState) OnFailure(rb *RequestBreaker, cnt Counts) State { return StateOpen }
+
+type halfOpenState struct{}
+
+func (halfOpenState) CurrentState() State { return StateHalfOpen }
+
+func (halfOpenState) OnRequest(rb *RequestBreaker, now time.Time, tags requestTags) (State, error) {
+	//半开刚开始的一段时间，试探名额留给高优先级的请求
+	if grace := rb.options.PriorityProbeGrace; grace > 0 && tags.priority < PriorityHigh && now.Before(rb.stateSince.Add(grace)) {
+		return StateHalfOpen, ErrTooManyRequests
+	}
+	//只用标记过的轻量请求试探
+	if rb.options.EligibleProbesOnly && !tags.probeEligible {
+		return StateHalfOpen, ErrTooManyRequests
+	}
+	//半开状态下只允许有限的试探请求
+	maxRequests := rb.options.MaxRequests
+	if maxRequests == 0 {
+		maxRequests = 1
+	}
+	if rb.probes >= maxRequests {
+		return StateHalfOpen, ErrTooManyRequests
+	}
+	return StateHalfOpen, nil
+}
+
+func (halfOpenState) OnSuccess(rb *RequestBreaker, cnt Counts) State {
+	ratio, next, reason := rb.closeByRatio(true)
+	if ratio && next != StateClosed {
+		rb.reason = reason
+		return next
+	}
+	if !ratio && cnt.ConsecutiveSuccesses < rb.options.ShoulderHalfToOpen {
+		return StateHalfOpen
+	}
+	if !rb.verifyRecovery() {
+		//应用层的检查还没通过，归还这个试探名额，继续试探
+		rb.releaseProbe(rb.generation)
+		return StateHalfOpen
+	}
+	//确定要闭合了才记下原因，不然没通过检查的原因会留给下一次变化
+	if ratio {
+		rb.reason = reason
+	}
+	return StateClosed
+}
+
+func (halfOpenState) OnFailure(rb *RequestBreaker, cnt Counts) State {
+	if ratio, next, reason := rb.closeByRatio(false); ratio {
+		rb.reason = reason
+		return next
+	}
+	//容忍有限次数的试探失败，用完了才重新打开
+	if tolerance := rb.options.HalfOpenFailureTolerance; tolerance > 0 {
+		if cnt.ProbeFailures+cnt.TotalFailures >= tolerance {
+			rb.because("%d probe failures reached the tolerance of %d", cnt.ProbeFailures+cnt.TotalFailures, tolerance)
+			return StateOpen
+		}
+		return StateHalfOpen
+	}
+	if rb.canOpen(StateHalfOpen, cnt) {
+		return StateOpen
+	}
+	return StateHalfOpen
+}
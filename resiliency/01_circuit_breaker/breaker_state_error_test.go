@@ -0,0 +1,117 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBreakerWithStateRetryAfterShrinks(t *testing.T) {
+
+	circuit := BreakerWithState(func(ctx context.Context) error {
+		return errBackendDown
+	}, 1)
+
+	if err := circuit(context.Background()); err != errBackendDown {
+		t.Fatalf("first call should reach the backend, got %v", err)
+	}
+
+	err := circuit(context.Background())
+
+	var openErr *OpenCircuitError
+	if !errors.As(err, &openErr) {
+		t.Fatalf("expected *OpenCircuitError, got %v", err)
+	}
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Error("OpenCircuitError should match ErrServiceUnavailable")
+	}
+	if openErr.State != StateOpen {
+		t.Errorf("expected open state, got %s", openErr.State)
+	}
+
+	first := openErr.RetryAfter()
+	if first <= 0 || first > time.Second {
+		t.Fatalf("RetryAfter should be within the cool-off, got %v", first)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if later := openErr.RetryAfter(); later >= first {
+		t.Errorf("RetryAfter should shrink over time, %v then %v", first, later)
+	}
+}
+
+func TestBreakerConcurrentCalls(t *testing.T) {
+
+	const workers, calls = 8, 100
+
+	var backend int64
+	circuit := Breaker(func(ctx context.Context) error {
+		atomic.AddInt64(&backend, 1)
+		return errBackendDown
+	}, workers*calls, WithClock(newFakeClock()))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < calls; i++ {
+				circuit(context.Background())
+			}
+		}()
+	}
+	wg.Wait()
+
+	//每一次失败都记上了，刚好达到阈值，下一次直接拒绝
+	if backend != workers*calls {
+		t.Fatalf("every call below the threshold should reach the backend, got %d", backend)
+	}
+	if err := circuit(context.Background()); err != ErrServiceUnavailable {
+		t.Errorf("all %d failures should be counted, got %v", workers*calls, err)
+	}
+}
+
+func TestBackoffPlateausAtCap(t *testing.T) {
+
+	const threshold = 3
+	cnt := simpleCounter{}
+
+	var last time.Duration
+	for failures := uint32(threshold); failures < threshold+64; failures++ {
+		cnt.ConsecutiveFailures = failures
+		backoff := backoffFor(cnt, threshold, 30*time.Second)
+		if backoff < last || backoff > 30*time.Second {
+			t.Fatalf("%d failures: backoff %v should grow up to the cap, last %v", failures, backoff, last)
+		}
+		last = backoff
+	}
+	if last != 30*time.Second {
+		t.Errorf("backoff should plateau at the cap, got %v", last)
+	}
+
+	//没有上限时照旧翻倍
+	cnt.ConsecutiveFailures = threshold + 5
+	if backoff := backoffFor(cnt, threshold, 0); backoff != 32*time.Second {
+		t.Errorf("uncapped backoff should double, got %v", backoff)
+	}
+}
+
+func TestBreakerWithStateHonorsMaxBackoff(t *testing.T) {
+
+	circuit := BreakerWithState(func(ctx context.Context) error {
+		return errBackendDown
+	}, 1, WithClock(newFakeClock()), WithMaxBackoff(100*time.Millisecond))
+
+	circuit(context.Background())
+
+	var openErr *OpenCircuitError
+	if err := circuit(context.Background()); !errors.As(err, &openErr) {
+		t.Fatalf("expected *OpenCircuitError, got %v", err)
+	}
+	if wait := openErr.RetryAfter(); wait != 100*time.Millisecond {
+		t.Errorf("wait should be capped at 100ms, got %v", wait)
+	}
+}
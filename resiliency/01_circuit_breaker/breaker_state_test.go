@@ -0,0 +1,146 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//stateFixture return a breaker used only as the context of state objects, states never change it
+func stateFixture(clock *fakeClock, opts ...Option) *RequestBreaker {
+	opts = append([]Option{WithClock(clock), Interval(time.Minute), Expiry(clock.Now().Add(time.Minute))}, opts...)
+	return NewRequestBreaker(opts...)
+}
+
+func countN(rb *RequestBreaker, s OperationState, n int) {
+	for i := 0; i < n; i++ {
+		rb.counter().Count(s, true)
+	}
+}
+
+func TestClosedStateDecisions(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := stateFixture(clock, WithLoadShedding(1))
+
+	if next, err := closed.OnRequest(rb, clock.Now(), requestTags{}); next != StateClosed || err != nil {
+		t.Errorf("closed should admit, got %s %v", next, err)
+	}
+
+	rb.inflight = 1
+	if _, err := closed.OnRequest(rb, clock.Now(), requestTags{}); err != ErrLoadShed {
+		t.Errorf("closed should shed at MaxInFlight, got %v", err)
+	}
+	rb.inflight = 0
+
+	countN(rb, FailureState, 2)
+	if next := closed.OnFailure(rb, rb.snapshot()); next != StateClosed {
+		t.Errorf("2 failures should not trip, got %s", next)
+	}
+	countN(rb, FailureState, 1)
+	if next := closed.OnFailure(rb, rb.snapshot()); next != StateOpen {
+		t.Errorf("3 failures should trip, got %s", next)
+	}
+	if next := closed.OnSuccess(rb, rb.snapshot()); next != StateClosed {
+		t.Errorf("success keeps closed, got %s", next)
+	}
+
+	//周期到期，计数器清零
+	generation := rb.generation
+	clock.Advance(2 * time.Minute)
+	closed.OnRequest(rb, clock.Now(), requestTags{})
+	if rb.generation != generation+1 || rb.Counts().ConsecutiveFailures != 0 {
+		t.Error("expired interval should start a new generation")
+	}
+	if rb.state != StateClosed {
+		t.Errorf("state object should not change state itself, got %s", rb.state)
+	}
+}
+
+func TestOpenStateDecisions(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := stateFixture(clock)
+	rb.state = StateOpen
+
+	if next, err := open.OnRequest(rb, clock.Now(), requestTags{}); next != StateOpen || err != ErrServiceUnavailable {
+		t.Errorf("open should reject before expiry, got %s %v", next, err)
+	}
+
+	clock.Advance(2 * time.Minute)
+	if next, err := open.OnRequest(rb, clock.Now(), requestTags{}); next != StateHalfOpen || err != nil {
+		t.Errorf("open should move to half-open after expiry, got %s %v", next, err)
+	}
+
+	if open.OnSuccess(rb, rb.snapshot()) != StateOpen || open.OnFailure(rb, rb.snapshot()) != StateOpen {
+		t.Error("outcomes should not move an open breaker")
+	}
+}
+
+func TestHalfOpenStateDecisions(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := stateFixture(clock, MaxRequests(2), WithShoulderHalfToOpen(2))
+	rb.state = StateHalfOpen
+
+	rb.probes = 1
+	if next, err := halfOpen.OnRequest(rb, clock.Now(), requestTags{}); next != StateHalfOpen || err != nil {
+		t.Errorf("half-open should admit under MaxRequests, got %s %v", next, err)
+	}
+	rb.probes = 2
+	if _, err := halfOpen.OnRequest(rb, clock.Now(), requestTags{}); err != ErrTooManyRequests {
+		t.Errorf("half-open should limit probes, got %v", err)
+	}
+
+	countN(rb, SuccessState, 1)
+	if next := halfOpen.OnSuccess(rb, rb.snapshot()); next != StateHalfOpen {
+		t.Errorf("1 success should stay half-open, got %s", next)
+	}
+	countN(rb, SuccessState, 1)
+	if next := halfOpen.OnSuccess(rb, rb.snapshot()); next != StateClosed {
+		t.Errorf("2 successes should close, got %s", next)
+	}
+
+	countN(rb, FailureState, 3)
+	if next := halfOpen.OnFailure(rb, rb.snapshot()); next != StateOpen {
+		t.Errorf("failures should reopen, got %s", next)
+	}
+}
+
+func TestCurrentStateObject(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	for _, s := range []State{StateClosed, StateOpen, StateHalfOpen} {
+		rb.state = s
+		if got := rb.current().CurrentState(); got != s {
+			t.Errorf("state object of %s reports %s", s, got)
+		}
+	}
+}
+
+func TestHalfOpenFailureTolerance(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithHalfOpenFailureTolerance(2))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	fail := func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+
+	rb.Do(fail)
+	if rb.State() != StateHalfOpen {
+		t.Fatalf("first failed probe should be tolerated, got %s", rb.State())
+	}
+	rb.Do(fail)
+	if rb.State() != StateOpen {
+		t.Fatalf("second failed probe should reopen, got %s", rb.State())
+	}
+
+	//successes still close the breaker in between
+	clock.Advance(2 * time.Second)
+	rb.Do(fail)
+	rb.Do(succeedWork)
+	if rb.State() != StateClosed {
+		t.Errorf("a good probe should close, got %s", rb.State())
+	}
+}
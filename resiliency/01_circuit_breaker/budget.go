@@ -0,0 +1,49 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:49:43
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:49:43
+ */
+
+package circuit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+////////////////////////////////
+/// 请求的时间预算
+/// 调用方给的deadline开始时就快用完了，请求超时是调用方的问题，不是后端的
+/// 放行时记下还剩多少时间，分类的时候可以减轻或者忽略这种失败
+////////////////////////////////
+
+//Unbudgeted is the Budget of a request whose context has no deadline
+const Unbudgeted time.Duration = math.MaxInt64
+
+//WithMinBudget ignore the failures of requests admitted with less than d left before the deadline of the caller,
+//they are neither successes nor failures. OutcomeHooks see the budget in Completion.Budget and may still count them
+func WithMinBudget(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.MinBudget = d
+	}
+}
+
+//budgetOf return the time left before the deadline of ctx, 0 once passed
+func budgetOf(ctx context.Context) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return Unbudgeted
+	}
+	if left := time.Until(deadline); left > 0 {
+		return left
+	}
+	return 0
+}
+
+//starved report whether a failure says more about the budget of the caller than about the backend
+func (rb *RequestBreaker) starved(budget time.Duration, err error) bool {
+	return err != nil && budget < rb.options.MinBudget
+}
@@ -0,0 +1,68 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//timeoutWork fail like a backend that took the whole budget
+func timeoutWork(ctx context.Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestMinBudgetIgnoresStarvedFailures(t *testing.T) {
+
+	rb := NewRequestBreaker(WithMinBudget(50 * time.Millisecond))
+
+	for i := 0; i < 5; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if _, err := rb.DoContext(ctx, timeoutWork); err != context.DeadlineExceeded {
+			t.Fatalf("the caller should still see its deadline, got %v", err)
+		}
+		cancel()
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 0 || rb.State() != StateClosed {
+		t.Fatalf("failures of starved requests should not count, got %+v in %s", cnt, rb.State())
+	}
+
+	//预算足够的失败照常计数
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		rb.DoContext(ctx, failWork)
+		cancel()
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("failures with enough budget should trip, got %s", rb.State())
+	}
+}
+
+func TestClassifySeesBudget(t *testing.T) {
+
+	var budgets []time.Duration
+	hooks := &budgetHooks{seen: &budgets}
+	rb := NewRequestBreaker(WithOutcomeHooks(hooks))
+
+	rb.Do(succeedWork)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	rb.DoContext(ctx, succeedWork)
+
+	if len(budgets) != 2 || budgets[0] != Unbudgeted {
+		t.Fatalf("a request without deadline should be Unbudgeted, got %v", budgets)
+	}
+	if budgets[1] <= 59*time.Minute || budgets[1] > time.Hour {
+		t.Errorf("the budget should be what is left at admission, got %v", budgets[1])
+	}
+}
+
+type budgetHooks struct {
+	DefaultOutcomeHooks
+	seen *[]time.Duration
+}
+
+func (h *budgetHooks) Classify(c Completion) Outcome {
+	*h.seen = append(*h.seen, c.Budget)
+	return c.Default
+}
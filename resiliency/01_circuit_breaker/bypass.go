@@ -0,0 +1,26 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:00:34
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:00:34
+ */
+
+package circuit
+
+import "context"
+
+type bypassKey struct{}
+
+// WithBypass return a context which makes DoContext admit the request whatever the breaker state is,
+// the outcome is still recorded.
+// Use it only for a few critical requests, such as health checks or admin operations,
+// bypassing the breaker for normal traffic hammers a backend that is already down.
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func isBypass(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
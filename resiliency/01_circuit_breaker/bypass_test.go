@@ -0,0 +1,33 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBypassAdmitsWhenOpen(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("bypass"))
+	tripBreaker(t, rb)
+
+	ran := false
+	work := func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return "pong", nil
+	}
+
+	if _, err := rb.DoContext(context.Background(), work); err != ErrServiceUnavailable || ran {
+		t.Fatalf("normal request should be rejected, got %v", err)
+	}
+
+	res, err := rb.DoContext(WithBypass(context.Background()), work)
+	if err != nil || !ran || res != "pong" {
+		t.Fatalf("bypass request should run, got %v %v", res, err)
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("bypass should not change state, got %s", rb.State())
+	}
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 1 {
+		t.Errorf("bypass outcome should be recorded, got %+v", cnt)
+	}
+}
@@ -0,0 +1,75 @@
+package circuit
+
+import (
+	"fmt"
+	"testing"
+)
+
+//orderHooks log Classify and Record into calls
+type orderHooks struct {
+	DefaultOutcomeHooks
+	calls *[]string
+}
+
+func (h orderHooks) Classify(c Completion) Outcome {
+	*h.calls = append(*h.calls, "classify")
+	return c.Default
+}
+
+func (h orderHooks) Record(c Completion, outcome Outcome) {
+	*h.calls = append(*h.calls, "record")
+}
+
+func TestCallbackOrder(t *testing.T) {
+
+	var calls []string
+	logCall := func(name string) { calls = append(calls, name) }
+
+	rb := NewRequestBreaker(
+		WithOutcomeHooks(orderHooks{calls: &calls}),
+		WithOnResult(func(name string, err error, meta map[string]string) { logCall("OnResult") }),
+		WithStateChanged(func(name string, from, to State) { logCall("OnStateChanged") }),
+		WithStateChangedReason(func(name string, from, to State, reason string) { logCall("OnStateChangedReason") }),
+		WithOutcomeSink(func(record OutcomeRecord) { logCall("OutcomeSink " + record.State.String()) }),
+	)
+	for i := 0; i < 5; i++ {
+		i := i
+		rb.Events().Subscribe(ObserverFunc[StateChange](func(change StateChange) {
+			logCall(fmt.Sprintf("observer %d", i))
+		}))
+	}
+
+	rb.Do(failWork)
+	rb.Do(failWork)
+	calls = nil
+	rb.Do(failWork) //第三次失败打开断路器
+
+	want := []string{
+		"classify", "record",
+		"OnResult",
+		"OnStateChanged", "OnStateChangedReason",
+		"observer 0", "observer 1", "observer 2", "observer 3", "observer 4",
+		"OutcomeSink open",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("callbacks:\n got %v\nwant %v", calls, want)
+	}
+}
+
+func TestUnsubscribeKeepsOrder(t *testing.T) {
+
+	var subject Subject[int]
+	var got []int
+	unsubscribe := make([]func(), 4)
+	for i := range unsubscribe {
+		i := i
+		unsubscribe[i] = subject.Subscribe(ObserverFunc[int](func(event int) { got = append(got, i) }))
+	}
+	unsubscribe[1]()
+	subject.Subscribe(ObserverFunc[int](func(event int) { got = append(got, 4) }))
+
+	subject.Notify(0)
+	if fmt.Sprint(got) != "[0 2 3 4]" {
+		t.Errorf("observers should be notified in the order they subscribed, got %v", got)
+	}
+}
@@ -0,0 +1,81 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+//causeOf run one request waiting on its context and return context.Cause seen by work,
+//cancel is called once work runs
+func causeOf(t *testing.T, rb *RequestBreaker, ctx context.Context, cancel func()) error {
+	t.Helper()
+
+	started := make(chan struct{})
+	causes := make(chan error, 1)
+	go rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			causes <- context.Cause(ctx)
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			causes <- nil
+			return "late", nil
+		}
+	})
+	<-started
+	cancel()
+
+	select {
+	case cause := <-causes:
+		return cause
+	case <-time.After(2 * time.Second):
+		t.Fatal("work did not return")
+	}
+	return nil
+}
+
+func TestCauseOfTripCancel(t *testing.T) {
+
+	rb := NewRequestBreaker(WithCancelInflightOnTrip(true))
+
+	cause := causeOf(t, rb, context.Background(), func() { tripBreaker(t, rb) })
+	if !errors.Is(cause, ErrTripped) {
+		t.Errorf("a request canceled by the trip should see ErrTripped, got %v", cause)
+	}
+}
+
+func TestCauseOfRequestTimeout(t *testing.T) {
+
+	rb := NewRequestBreaker(WithRequestTimeout(10 * time.Millisecond))
+
+	cause := causeOf(t, rb, context.Background(), func() {})
+	if !errors.Is(cause, ErrTimeout) {
+		t.Errorf("a request over RequestTimeout should see ErrTimeout, got %v", cause)
+	}
+}
+
+func TestCauseOfScopeIsKept(t *testing.T) {
+
+	errShutdown := errors.New("shutting down")
+	scope, stop := context.WithCancelCause(context.Background())
+	rb := NewRequestBreakerWithContext(scope)
+
+	cause := causeOf(t, rb, context.Background(), func() { stop(errShutdown) })
+	if !errors.Is(cause, errShutdown) {
+		t.Errorf("the cause of the scope should reach work, got %v", cause)
+	}
+}
+
+func TestCauseOfCallerCancel(t *testing.T) {
+
+	rb := NewRequestBreaker(WithCancelInflightOnTrip(true), WithRequestTimeout(time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cause := causeOf(t, rb, ctx, cancel)
+	if cause != context.Canceled {
+		t.Errorf("a request canceled by its caller should see context.Canceled, got %v", cause)
+	}
+}
@@ -0,0 +1,46 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:17:52
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:17:52
+ */
+
+package circuit
+
+import "context"
+
+////////////////////////////////
+/// 用断路器保护管道(channel)中的一级
+/// 成功处理的数据发往下游，失败和被拒绝的请求发往错误通道
+/// 断路器打开时输入照常被取走，不再处理，直接得到拒绝错误
+////////////////////////////////
+
+// GuardChannel processes each item of in through rb.
+// Items processed without error are forwarded to the returned item channel,
+// errors of process and rejections of rb go to the returned error channel.
+// While rb is open the input keeps being drained and every item is rejected.
+// Both channels are closed once in is closed, the caller must read both of them.
+func GuardChannel[T any](in <-chan T, rb *RequestBreaker, process func(T) error) (<-chan T, <-chan error) {
+
+	out := make(chan T)
+	errs := make(chan error)
+
+	go func() {
+		defer close(out)
+		defer close(errs)
+
+		for item := range in {
+			_, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+				return nil, process(item)
+			})
+			if err != nil {
+				errs <- err
+				continue
+			}
+			out <- item
+		}
+	}()
+
+	return out, errs
+}
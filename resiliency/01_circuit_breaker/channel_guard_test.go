@@ -0,0 +1,58 @@
+package circuit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGuardChannelOpensOnFailures(t *testing.T) {
+
+	in := make(chan int)
+	go func() {
+		defer close(in)
+		for i := 0; i < 10; i++ {
+			in <- i
+		}
+	}()
+
+	//2,3,4 fail and trip the breaker, the items after them are rejected
+	out, errs := GuardChannel(in, NewRequestBreaker(), func(i int) error {
+		if i >= 2 && i <= 4 {
+			return errBackendDown
+		}
+		return nil
+	})
+
+	var forwarded []int
+	var failures, rejections int
+	for out != nil || errs != nil {
+		select {
+		case item, ok := <-out:
+			if !ok {
+				out = nil
+				continue
+			}
+			forwarded = append(forwarded, item)
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			switch {
+			case errors.Is(err, errBackendDown):
+				failures++
+			case errors.Is(err, ErrServiceUnavailable):
+				rejections++
+			default:
+				t.Errorf("unexpected error %v", err)
+			}
+		}
+	}
+
+	if len(forwarded) != 2 || forwarded[0] != 0 || forwarded[1] != 1 {
+		t.Errorf("only items before the failures should be forwarded, got %v", forwarded)
+	}
+	if failures != 3 || rejections != 5 {
+		t.Errorf("expected 3 failures and 5 rejections, got %d and %d", failures, rejections)
+	}
+}
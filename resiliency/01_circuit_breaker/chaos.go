@@ -0,0 +1,46 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:14:23
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package circuit
+
+import "math/rand"
+
+////////////////////////////////
+/// 混沌注入
+/// 按比例让放行的请求直接失败(不执行work)，失败照常计数，可能让断路器打开
+/// 用来验证调用方的降级和容错逻辑，默认关闭
+////////////////////////////////
+
+//ErrChaosInjected is returned instead of running work when chaos mode injects a failure
+var ErrChaosInjected = newBreakerError("chaos injected failure")
+
+//WithChaos fail failureRate of admitted requests with ErrChaosInjected without running work,
+//rnd may be nil to use the global random source. Never enable it in production by accident
+func WithChaos(failureRate float64, rnd *rand.Rand) Option {
+	return func(opts *Options) {
+		opts.ChaosRate = failureRate
+		opts.ChaosRand = rnd
+	}
+}
+
+//injectChaos decide whether to inject a failure into current request
+func (rb *RequestBreaker) injectChaos() bool {
+	rate := rb.options.ChaosRate
+	if rate <= 0 {
+		return false
+	}
+
+	rnd := rb.options.ChaosRand
+	if rnd == nil {
+		return rand.Float64() < rate
+	}
+
+	rb.chaos.Lock()
+	defer rb.chaos.Unlock()
+	return rnd.Float64() < rate
+}
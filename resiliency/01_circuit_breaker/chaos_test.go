@@ -0,0 +1,61 @@
+package circuit
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestChaosInjectionRate(t *testing.T) {
+
+	//never trip, only measure the rate
+	rb := NewRequestBreaker(WithChaos(0.3, rand.New(rand.NewSource(7))),
+		WithBreakCondition(func(State, Counts) bool { return false }))
+
+	const total = 2000
+	injected, ran := 0, 0
+	for i := 0; i < total; i++ {
+		_, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+			ran++
+			return nil, nil
+		})
+		if err == ErrChaosInjected {
+			injected++
+		}
+	}
+
+	if rate := float64(injected) / total; math.Abs(rate-0.3) > 0.03 {
+		t.Errorf("expected about 30%% injected failures, got %.3f", rate)
+	}
+	if ran+injected != total {
+		t.Errorf("injected requests should not run work, ran %d injected %d", ran, injected)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != uint32(injected) {
+		t.Errorf("injected failures should be counted, got %+v", cnt)
+	}
+}
+
+func TestChaosTripsBreaker(t *testing.T) {
+
+	rb := NewRequestBreaker(WithChaos(1, rand.New(rand.NewSource(1))))
+
+	for i := 0; i < 3; i++ {
+		if _, err := rb.Do(succeedWork); err != ErrChaosInjected {
+			t.Fatalf("expected ErrChaosInjected, got %v", err)
+		}
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("chaos failures should trip the breaker, got %s", rb.State())
+	}
+}
+
+func TestChaosOffByDefault(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	for i := 0; i < 100; i++ {
+		if _, err := rb.Do(succeedWork); err != nil {
+			t.Fatalf("no failure should be injected by default, got %v", err)
+		}
+	}
+}
@@ -3,7 +3,7 @@
  * @Author: Edward
  * @Date: 2020-05-10 22:00:58
  * @Last Modified by: Edward
- * @Last Modified time: 2020-06-03 23:54:29
+ * @Last Modified time: 2026-10-14 19:03:05
  */
 
 package circuit
@@ -11,8 +11,10 @@ package circuit
 import (
 	"context"
 	"errors"
-	"fmt"
+	"math"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,8 +25,10 @@ import (
 
 //ErrServiceUnavailable for error
 var (
-	ErrTooManyRequests    = errors.New("too many requests")
-	ErrServiceUnavailable = errors.New("service unavailable")
+	ErrTooManyRequests    = NewRejectionError("too many requests")
+	ErrServiceUnavailable = NewRejectionError("service unavailable")
+	ErrLoadShed           = NewRejectionError("request shed")
+	ErrNilWork            = newBreakerError("nil work")
 	FailureThreshold      = 10 //最大失败次数--->失败阈值
 )
 
@@ -40,26 +44,115 @@ const (
 // 支持多工作者模式
 ////////////////////////////////
 
+//fast 的位布局: 高32位是generation，第31位标记闭合状态，低31位是尚未合并到计数器的成功次数
+const (
+	fastClosed    uint64 = 1 << 31
+	fastCountMask uint64 = fastClosed - 1
+)
+
 //RequestBreaker for protection
 type RequestBreaker struct {
-	options  Options
+	//fast 让闭合状态下成功的请求不用加锁，必须是第一个字段以保证64位对齐
+	fast     uint64
+	expiry   int64  //闭合状态的Expiry，距epoch的纳秒数，给无锁路径读取
+	health   uint64 //健康分的float64位，由HealthScore读取
+	skew     int64  //时钟回拨的总量，加到之后的每次读数上
+	seen     int64  //锁内最近一次读到的时间，距epoch的纳秒数
+	options  *Options
 	mutex    sync.Mutex
 	state    State
-	cnter    counters
+	cnter    ICounter
+	cnterOne sync.Once
 	preState State
+	openFor  time.Duration //当前这次打开持续的时间
+	inflight int32         //正在执行的请求数，只在开启限流时统计
+	probes   uint32        //本轮半开状态已放行的试探请求数
+	//generation 每次计数器重置(状态变化或者闭合状态的周期到期)加一
+	//请求结果只记录到放行它的那一代
+	generation uint32
+	shared     sharedGroup
+	events     Subject[StateChange]
+	cache      responseCache
+	chaos      sync.Mutex //保护 ChaosRand
+	warmUntil  time.Time  //预热结束之前不会打开
+	//每个状态累计停留的时间，不含当前状态从stateSince开始的这一段
+	inState    map[State]time.Duration
+	stateSince time.Time
+	history    transitionHistory
+	//errorGroups 本代失败按错误分组的次数，只在开启分组时统计
+	errorGroups map[string]uint32
+	initOnce    sync.Once       //零值的断路器在第一次使用时按默认配置初始化
+	latency     *TDigestLatency //本代请求的延迟分布，只在开启分位数打开时统计
+	//Prepare 占用的名额，按令牌编号
+	reservations map[uint64]Token
+	lastToken    uint64
+	waits        waitRecorder //加锁放行的请求等待锁的时间
+	trips        []time.Time  //窗口内打开的时间，只在开启频繁打开告警时记录
+	//CanOpenFor 要求的下一次打开的时长，0表示按Timeout或者退避计算
+	requestedOpen time.Duration
+	expiresAt     time.Time   //闭合状态的周期或者打开状态到期的时间
+	window        probeWindow //本轮半开状态最近的试探结果，只在按比例闭合时记录
+	//最近的请求的平均延迟，只在开启平均延迟打开时统计
+	average *SimpleMovingAverage
+	//还在执行的请求共用的context，打开时取消，只在开启打开时取消请求时使用
+	tripCtx        context.Context
+	tripCancel     context.CancelCauseFunc
+	tripGeneration uint32 //最近一次打开之后的generation
+	//本代按严重程度统计的失败次数，下标是Severity
+	severities [2]uint32
+	//打开和恢复的信号，用到时才创建
+	openSignal    chan struct{}
+	recoverSignal chan struct{}
+	lastErr       error //最近一次计数的失败，恢复闭合时清空
+	//下一次状态变化的原因，没人写的话由 transitionReason 描述
+	reason     string
+	lastReason string
+	shards     []successShard //WithSuccessShards 的分片，为空表示只用fast
+	//断路器自己的时间，见 now
+	epoch   time.Time
+	lastNow time.Time
+	//WithInitialProbe 的第一个请求已经发出
+	initialProbed uint32
+	hooks         *hookQueue //WithAsyncHooks 的队列，为空表示同步执行
+	//当前的打开阈值，为空表示由 CanOpen 决定，见 Reconfigure
+	threshold atomic.Pointer[TripThreshold]
+	retired   retiredCounter //SwapCounter 换下的计数器，切换时还在执行的请求记到它上面
 }
 
-//NewRequestBreaker return a breaker
+// NewRequestBreaker return a breaker.
+// The zero value of RequestBreaker is also ready to use,
+// it behaves like NewRequestBreaker() with no options once first used.
 func NewRequestBreaker(opts ...Option) *RequestBreaker {
+	rb := &RequestBreaker{}
+	rb.initOnce.Do(func() { rb.init(opts) })
+	return rb
+}
+
+//lazyInit apply the default options to a zero value breaker, must be called before touching any field
+func (rb *RequestBreaker) lazyInit() {
+	rb.initOnce.Do(func() { rb.init(nil) })
+}
+
+func (rb *RequestBreaker) init(opts []Option) {
+	options := newOptions(opts)
+	rb.initWith(&options)
+}
+
+//newOptions apply opts to the default options
+func newOptions(opts []Option) Options {
 
 	defaultOptions := Options{
 		Name:           "defaultBreakerName",
-		Expiry:         time.Now().Add(time.Second * 20),
+		Clock:          systemClock{},
 		Interval:       time.Second * 10, // interval to check  closed status,default 10 seconds
 		Timeout:        time.Second * 60, //timeout to check open, default 60 seconds
 		MaxRequests:    5,
-		CanOpen:        func(current State, cnter counters) bool { return cnter.ConsecutiveFailures > 2 },
-		CanClose:       func(current State, cnter counters) bool { return cnter.ConsecutiveSuccesses > 2 },
+		ReservationTTL: time.Second * 30,
+		IgnoreCanceled: true,
+		HealthAlpha:    0.1,
+		CanOpen:        func(current State, cnter Counts) bool { return cnter.ConsecutiveFailures > 2 },
+		TripPolicy:     defaultTripPolicy,
+		CanClose:       func(current State, cnter Counts) bool { return cnter.ConsecutiveSuccesses > 2 },
 		OnStateChanged: func(name string, fromPre State, toCurrent State) {},
 	}
 
@@ -67,104 +160,657 @@ func NewRequestBreaker(opts ...Option) *RequestBreaker {
 		setOption(&defaultOptions)
 
 	}
+	return defaultOptions
+}
+
+//initWith set up rb with options, they may be shared with other breakers and are never changed
+func (rb *RequestBreaker) initWith(options *Options) {
+
+	now := options.Clock.Now()
+	expiry := options.Expiry
+	if expiry.IsZero() {
+		expiry = now.Add(time.Second * 20)
+	}
+
+	rb.options = options
+	rb.epoch, rb.lastNow = now, now
+	rb.cnter = options.Counter
+	rb.state = StateClosed
+	rb.preState = StateClosed
+	rb.setExpiry(expiry)
+	if options.SuccessShards > 1 {
+		rb.shards = make([]successShard, options.SuccessShards)
+	}
+	rb.resetFast()
+	rb.health = math.Float64bits(1)
+	rb.warmUntil = now.Add(options.Warmup)
+	rb.inState = make(map[State]time.Duration)
+	rb.stateSince = now
+	rb.history.changes = make([]StateChange, 0, options.HistorySize)
+	if options.LatencyQuantile > 0 {
+		rb.latency = NewTDigestLatency(defaultCompression)
+	}
+	if options.AverageWindow > 0 {
+		rb.average = NewSimpleMovingAverage(options.AverageWindow)
+	}
+	if options.TripThreshold != nil {
+		threshold := *options.TripThreshold
+		rb.threshold.Store(&threshold)
+	}
+	if options.AsyncHooks > 0 {
+		rb.hooks = newHookQueue(options.AsyncHooks)
+		if options.Scope != nil {
+			context.AfterFunc(options.Scope, rb.hooks.stop)
+		}
+	}
+}
+
+//Counts return a copy of current counters
+func (rb *RequestBreaker) Counts() Counts {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.snapshot()
+}
+
+//snapshot merge the lock-free successes and copy counters, must be called with the mutex held
+func (rb *RequestBreaker) snapshot() Counts {
+	rb.flushFast()
+	return rb.counter().Snapshot()
+}
+
+//counter return the ICounter of breaker, the built-in one is created on first use
+func (rb *RequestBreaker) counter() ICounter {
+	rb.cnterOne.Do(func() {
+		if rb.cnter == nil {
+			rb.cnter = &Counts{}
+		}
+		useClock(rb.cnter, rb.options.Clock)
+	})
+	return rb.cnter
+}
+
+//StateDurations return how long the breaker has spent in each state since it was created
+func (rb *RequestBreaker) StateDurations() map[State]time.Duration {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	durations := map[State]time.Duration{StateClosed: 0, StateHalfOpen: 0, StateOpen: 0}
+	for state, d := range rb.inState {
+		durations[state] = d
+	}
+	durations[rb.state] += rb.now().Sub(rb.stateSince)
+	return durations
+}
+
+//Reset close the breaker with fresh counters and start the warm-up again
+func (rb *RequestBreaker) Reset() {
+	rb.ResetWithReason("manual reset")
+}
+
+//ResetWithReason is Reset, reason is recorded as why the breaker closed, such as "reset by admin"
+func (rb *RequestBreaker) ResetWithReason(reason string) {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	now := rb.now()
+	rb.warmUntil = now.Add(rb.options.Warmup)
+	if rb.state != StateClosed {
+		rb.reason = reason
+		rb.changeStateTo(StateClosed)
+		return
+	}
+	rb.newGeneration()
+	rb.setExpiry(now.Add(rb.nextInterval()))
+}
+
+//State return current state of breaker
+func (rb *RequestBreaker) State() State {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.state
+}
+
+//LastError return the last failure counted by the breaker, nil once it has recovered to closed
+func (rb *RequestBreaker) LastError() error {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.lastErr
+}
 
-	return &RequestBreaker{
-		options:  defaultOptions,
-		cnter:    counters{},
-		state:    StateUnknown,
-		preState: StateUnknown,
+//RetryNever is returned by RetryAfter when an open breaker will not turn half-open by itself
+const RetryNever time.Duration = -1
+
+// RetryAfter return how long until an open breaker may turn half-open, 0 when it is not open or may already.
+// The adaptive timeout and the timeout jitter of this trip are included,
+// with ManualRecoveryOnly it returns RetryNever until Reset, with WithDryRun it is always 0.
+func (rb *RequestBreaker) RetryAfter() time.Duration {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	if rb.state != StateOpen || rb.options.DryRun {
+		return 0
+	}
+	if rb.options.ManualRecoveryOnly {
+		return RetryNever
 	}
+	if wait := rb.expiresAt.Sub(rb.now()); wait > 0 {
+		return wait
+	}
+	return 0
 }
 
 func (rb *RequestBreaker) changeStateTo(state State) {
+	var last Counts
+	if rb.options.Logger != nil {
+		last = rb.snapshot()
+	}
+
+	now := rb.now()
+	reason := rb.transitionReason(state, now)
+	rb.lastReason = reason
+	rb.inState[rb.state] += now.Sub(rb.stateSince)
+	rb.stateSince = now
+
 	rb.preState = rb.state
 	rb.state = state
-	rb.cnter.Reset()
+	rb.probes = 0
+	rb.window.reset()
+	rb.newGeneration()
+	if rb.average != nil {
+		rb.average.Reset()
+	}
+
+	switch state {
+	case StateOpen:
+		rb.openFor = rb.nextOpenDuration()
+		//退避按没有抖动的时长计算，抖动只影响这一次到期的时间
+		rb.setExpiry(now.Add(rb.jitter(rb.openFor, rb.options.TimeoutJitter)))
+	case StateClosed:
+		rb.openFor = 0 //恢复成功，退避重新开始
+		rb.lastErr = nil
+		rb.setExpiry(now.Add(rb.nextInterval()))
+	}
+
+	rb.logTransition(rb.preState, rb.state, reason, last)
+	from, to := rb.preState, rb.state
+	rb.guard("OnStateChanged", func() { rb.options.OnStateChanged(rb.options.Name, from, to) })
+	if handler := rb.options.OnStateChangedReason; handler != nil {
+		rb.guard("OnStateChangedReason", func() { handler(rb.options.Name, from, to, reason) })
+	}
+	change := StateChange{Name: rb.options.Name, From: from, To: to, At: now, Reason: reason, DryRun: rb.options.DryRun}
+	rb.history.record(change)
+	rb.guard("observer", func() { rb.events.Notify(change) })
+	if to == StateOpen {
+		rb.recordTrip(now)
+		rb.cancelInflight()
+	}
+	rb.signal()
+}
+
+//newGeneration reset the counters, outcomes of requests admitted before are dropped
+func (rb *RequestBreaker) newGeneration() {
+	rb.counter().Reset()
+	rb.nextGeneration()
+}
+
+//nextGeneration start a new generation keeping what the counter holds, must be called with the mutex held
+func (rb *RequestBreaker) nextGeneration() {
+	rb.generation++
+	rb.resetFast()
+	rb.errorGroups = nil
+	rb.severities = [2]uint32{}
+	if rb.latency != nil {
+		rb.latency.Reset()
+	}
+}
+
+func (rb *RequestBreaker) setExpiry(expiry time.Time) {
+	rb.expiresAt = expiry
+	atomic.StoreInt64(&rb.expiry, int64(expiry.Sub(rb.epoch)))
+}
+
+//resetFast publish current generation and state to the lock-free path and drop pending successes
+func (rb *RequestBreaker) resetFast() {
+	word := uint64(rb.generation) << 32
+	if rb.state == StateClosed {
+		word |= fastClosed
+	}
+	atomic.StoreUint64(&rb.fast, word)
+	rb.resetShards()
+}
+
+//flushFast fold successes counted without lock into the counter, must be called with the mutex held
+func (rb *RequestBreaker) flushFast() {
+	rb.flushShards()
+	for {
+		word := atomic.LoadUint64(&rb.fast)
+		pending := word & fastCountMask
+		if pending == 0 {
+			return
+		}
+		if atomic.CompareAndSwapUint64(&rb.fast, word, word&^fastCountMask) {
+			rb.counter().CountSuccesses(uint32(pending))
+			return
+		}
+	}
+}
+
+//fastAdmit admit a request of closed state without lock,
+//it gives up whenever there is something to do under the mutex
+func (rb *RequestBreaker) fastAdmit() (uint32, bool) {
+
+	word := atomic.LoadUint64(&rb.fast)
+	if word&fastClosed == 0 {
+		return 0, false
+	}
+	//闭合状态的周期到期，或者时钟回拨了，需要在锁内处理
+	if now, ok := rb.sinceEpoch(); !ok || now > atomic.LoadInt64(&rb.expiry) {
+		return 0, false
+	}
+	if max := rb.options.MaxInFlight; max > 0 {
+		if atomic.AddInt32(&rb.inflight, 1) > int32(max) {
+			atomic.AddInt32(&rb.inflight, -1)
+			return 0, false
+		}
+	}
+
+	return uint32(word >> 32), true
+}
+
+//fastSuccess count a success of closed state without lock,
+//it fails when the generation or state has changed since admission
+func (rb *RequestBreaker) fastSuccess(generation uint32) bool {
+	if rb.shards != nil {
+		return rb.shardSuccess(generation)
+	}
+	for {
+		word := atomic.LoadUint64(&rb.fast)
+		if uint32(word>>32) != generation || word&fastClosed == 0 || word&fastCountMask == fastCountMask {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&rb.fast, word, word+1) {
+			return true
+		}
+	}
+}
+
+//nextInterval return the length of next closed interval, jittered on every rollover
+func (rb *RequestBreaker) nextInterval() time.Duration {
+	return rb.jitter(rb.options.Interval, rb.options.IntervalJitter)
 }
 
-func (rb *RequestBreaker) beforeRequest() error {
+//jitter randomize d within ±fraction of it, must be called with the mutex held
+func (rb *RequestBreaker) jitter(d time.Duration, fraction float64) time.Duration {
+	if fraction <= 0 {
+		return d
+	}
+
+	random := rand.Float64
+	if rb.options.JitterRand != nil {
+		random = rb.options.JitterRand.Float64
+	}
+	//[-fraction, +fraction)
+	return d + time.Duration((random()*2-1)*fraction*float64(d))
+}
+
+//nextOpenDuration return how long the breaker stays open for this trip
+//with adaptive timeout each trip without a recovery in between multiplies the duration by factor
+func (rb *RequestBreaker) nextOpenDuration() time.Duration {
+	if requested := rb.requestedOpen; requested > 0 {
+		rb.requestedOpen = 0
+		return requested
+	}
+
+	adaptive := rb.options.AdaptiveTimeout
+	if adaptive == nil {
+		return rb.options.Timeout
+	}
+
+	if rb.openFor == 0 {
+		return adaptive.Base
+	}
+
+	next := time.Duration(float64(rb.openFor) * adaptive.Factor)
+	if next > adaptive.Max {
+		next = adaptive.Max
+	}
+	return next
+}
 
+//requestTags is what the caller tells the breaker about a request through its context
+type requestTags struct {
+	bypass        bool
+	priority      Priority
+	probeEligible bool
+}
+
+func tagsOf(ctx context.Context) requestTags {
+	return requestTags{bypass: isBypass(ctx), priority: priorityOf(ctx), probeEligible: isProbeEligible(ctx)}
+}
+
+func (rb *RequestBreaker) beforeRequest(tags requestTags) (uint32, error) {
+
+	start := rb.options.Clock.Now()
 	rb.mutex.Lock()
 	defer rb.mutex.Unlock()
-	fmt.Println("before do request:", rb.cnter.Total())
+	rb.waits.record(elapsed(rb.options.Clock, start))
 
-	switch rb.state {
-	case StateOpen:
-		//如果是断开状态，并且超时了，转到半开状态，记录
-		if rb.options.Expiry.Before(time.Now()) {
-			rb.changeStateTo(StateHalfOpen)
-			rb.options.Expiry = time.Now().Add(rb.options.Timeout)
-			return nil
-		}
-		return ErrTooManyRequests
-	case StateClosed:
-		if rb.options.Expiry.Before(time.Now()) {
-			rb.cnter.Reset()
-			rb.options.Expiry = time.Now().Add(rb.options.Interval)
+	//到期的令牌先归还名额
+	rb.sweepReservations()
+	if err := rb.admit(tags); err != nil && !tags.bypass {
+		rb.counter().CountRejection(err)
+		rb.logRejection(err)
+		if !rb.options.DryRun {
+			rb.notifyReject(err)
 		}
+		return rb.generation, err
+	}
+
+	if rb.options.MaxInFlight > 0 {
+		atomic.AddInt32(&rb.inflight, 1)
+	}
+	if rb.state == StateHalfOpen {
+		rb.probes++
 	}
 
-	return nil
+	return rb.generation, nil
+
+}
 
+//admit decide whether current request can go, must be called with the mutex held
+func (rb *RequestBreaker) admit(tags requestTags) error {
+	now := rb.now()
+	next, err := rb.current().OnRequest(rb, now, tags)
+	if next != rb.state {
+		rb.changeStateTo(next)
+		//刚转到新状态，由新状态决定这个请求能否放行
+		if err == nil {
+			_, err = rb.current().OnRequest(rb, now, tags)
+		}
+	}
+	return err
+}
+
+//inflightCount return the number of running requests, only tracked with load shedding
+func (rb *RequestBreaker) inflightCount() int32 {
+	return atomic.LoadInt32(&rb.inflight)
 }
 
 // Do the given requested work if the RequestBreaker accepts it.
 // Do returns an error instantly if the RequestBreaker rejects the request.
 // Otherwise, Execute returns the result of the request.
 // If a panic occurs in the request, the RequestBreaker handles it as an error and causes the same panic again.
+// A nil work returns ErrNilWork and leaves the breaker untouched.
 func (rb *RequestBreaker) Do(work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
 
+	rb.lazyInit()
+	ctx := rb.options.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return rb.DoContext(ctx, work)
+}
+
+// DoContext is like Do but hands ctx to the work.
+// If ctx is done when work returns, the request is counted as a failure
+// even if work swallowed the deadline and returned a nil error,
+// in that case ctx.Err() is returned to the caller.
+// DoContext never waits for a probe slot, a saturated half-open breaker rejects
+// with ErrTooManyRequests at once, so a short deadline of ctx is never spent waiting.
+func (rb *RequestBreaker) DoContext(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	_, result, err := rb.do(ctx, work, true)
+	return result, err
+}
+
+// TryDo is like Do but also reports whether the request was admitted.
+// admitted is false when the breaker short-circuits the request, err is then the rejection error,
+// it is true whenever work actually ran, even if work returned an error.
+// The fallback chain is not run, the caller sees what the breaker did.
+func (rb *RequestBreaker) TryDo(work func(ctx context.Context) (interface{}, error)) (admitted bool, result interface{}, err error) {
+
+	rb.lazyInit()
+	ctx := rb.options.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return rb.do(ctx, work, false)
+}
+
+//do run work through the breaker, the fallback chain handles the error only when fallback is true
+func (rb *RequestBreaker) do(ctx context.Context, work func(ctx context.Context) (interface{}, error), fallback bool) (bool, interface{}, error) {
+
+	rb.lazyInit()
+	if work == nil {
+		return false, nil, ErrNilWork
+	}
+	if scope := rb.options.Scope; scope != nil && scope.Err() != nil {
+		return false, nil, scope.Err()
+	}
+	var (
+		admitted bool
+		result   interface{}
+		err      error
+	)
+	if rb.initialProbe() {
+		admitted, result, err = rb.probe(ctx, work, "initial probe")
+	} else {
+		admitted, result, err = rb.trace(ctx, work)
+	}
+	if fallback {
+		result, err = rb.withFallback(admitted, result, err)
+	}
+	return admitted, result, err
+}
+
+//trace run execute in a span when there is a tracer
+func (rb *RequestBreaker) trace(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (bool, interface{}, error) {
+	tracer := rb.options.Tracer
+	if tracer == nil {
+		return rb.execute(ctx, work)
+	}
+
+	ctx, span := tracer.Start(ctx, rb.options.Name)
+	admitted, result, err := rb.execute(ctx, work)
+	span.End(rb.State(), admitted, err)
+	return admitted, result, err
+}
+
+func (rb *RequestBreaker) execute(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (bool, interface{}, error) {
+
 	//before
+	//闭合状态下先走无锁的快速路径，其他情况加锁
+	generation, fast := rb.fastAdmit()
+	if !fast {
+		var err error
+		if generation, err = rb.beforeRequest(tagsOf(ctx)); err != nil {
+			if rb.options.DryRun {
+				//空跑：本来会被拒绝的请求照常执行，结果不记录，就像没有执行过
+				result, err := rb.run(ctx, work)
+				return true, result, err
+			}
+			return false, nil, err
+		}
+	}
+
+	//work panic 或者 runtime.Goexit 的时候也要归还放行时占用的名额，记一次失败，panic 照旧交给调用方
+	finished := false
+	defer func() {
+		if !finished {
+			r := recover()
+			rb.abandon(generation, r)
+			if r != nil {
+				panic(r)
+			}
+		}
+	}()
 
-	if err := rb.beforeRequest(); err != nil {
-		return nil, err
+	//生命周期结束取消的请求和调用方自己取消的一样不计数
+	if rb.options.Scope != nil {
+		var stop func()
+		ctx, stop = cancelWith(ctx, rb.options.Scope)
+		defer stop()
+	}
+	caller := ctx
+	//放行时调用方还剩多少时间，只在有人看的时候才算
+	budget := Unbudgeted
+	if rb.options.MinBudget > 0 || rb.options.OutcomeHooks != nil {
+		budget = budgetOf(caller)
+	}
+	//断路器默认的请求超时，调用方更早的deadline优先，到期的原因是ErrTimeout
+	if timeout := rb.options.RequestTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrTimeout)
+		defer cancel()
+	}
+	if rb.options.CancelInflightOnTrip {
+		var stop func()
+		ctx, stop = rb.withTrip(ctx, generation)
+		defer stop()
+	}
+
+	measure := rb.options.LatencyTarget > 0 || rb.latency != nil || rb.average != nil || rb.options.OutcomeSink != nil
+	var start time.Time
+	if measure {
+		start = rb.options.Clock.Now()
 	}
 
 	//do work
 	//do work from requested user
-	result, err := work(rb.options.Ctx)
+	var result interface{}
+	var err error
+	if rb.injectChaos() {
+		err = ErrChaosInjected
+	} else {
+		result, err = rb.run(ctx, work)
+	}
+	finished = true
+
+	//work 忽略了超时或者取消，也要算作失败
+	if err == nil && ctx.Err() != nil {
+		err = ctx.Err()
+	}
+
+	//调用方自己取消的请求、按策略忽略的(nil, nil)和开始时预算就不够的失败不说明后端的好坏，既不算成功也不算失败
+	completion := Completion{Name: rb.options.Name, Result: result, Err: err, Default: OutcomeSuccess, Budget: budget}
+	canceled := rb.options.IgnoreCanceled && errors.Is(err, context.Canceled) && errors.Is(caller.Err(), context.Canceled)
+	switch {
+	case canceled || rb.ignoreNilResult(result, err) || rb.starved(budget, err):
+		completion.Default = OutcomeIgnore
+	case err != nil:
+		completion.Default = OutcomeFailure
+	}
+	outcome, counted := rb.classify(completion)
+
+	var latency time.Duration
+	if measure {
+		latency = elapsed(rb.options.Clock, start)
+	}
+
+	if outcome == OutcomeIgnore {
+		rb.reportResult(ctx, err)
+		if rb.options.MaxInFlight > 0 {
+			atomic.AddInt32(&rb.inflight, -1)
+		}
+		state := StateClosed
+		if !fast {
+			rb.mutex.Lock()
+			rb.releaseProbe(generation)
+			state = rb.state
+			rb.mutex.Unlock()
+		}
+		rb.emitOutcome(start, latency, outcome, state, err)
+		return true, result, err
+	}
+
+	//统计延迟时成功也要加锁，延迟和计数一样只记到放行这个请求的那一代
+	tracked := rb.latency != nil || rb.average != nil
+	rb.observeHealth(counted, latency)
+	rb.reportResult(ctx, err)
+
+	if rb.options.MaxInFlight > 0 {
+		atomic.AddInt32(&rb.inflight, -1)
+	}
 
 	//after work
-	rb.afterRequest(err)
+	//闭合状态下成功不会引起状态变化，只需要计数
+	if fast && counted == nil && !tracked && rb.fastSuccess(generation) {
+		rb.emitOutcome(start, latency, outcome, StateClosed, err)
+		return true, result, err
+	}
+	//没有统计延迟时不把延迟交给计数，输出的记录里照样带上测到的延迟
+	counts := latency
+	if !tracked {
+		counts = unmeasured
+	}
+	state := rb.afterRequest(generation, counted, counts)
+	rb.emitOutcome(start, latency, outcome, state, err)
 
-	return result, err
+	return true, result, err
 }
 
-func (rb *RequestBreaker) afterRequest(resultErr error) {
+//unmeasured is passed to afterRequest for an outcome without latency, such as a Commit
+const unmeasured time.Duration = -1
+
+// afterRequest counts the outcome of a request admitted in generation and returns the state after it.
+// The outcome and its latency always belong to the generation which admitted the request:
+// when an Interval rollover or a transition has started a new generation while the request ran,
+// they are dropped with the counters of their generation and never leak into the fresh one,
+// whichever path admitted the request and however the race with the rollover went.
+func (rb *RequestBreaker) afterRequest(generation uint32, resultErr error, latency time.Duration) State {
 
 	rb.mutex.Lock()
 	defer rb.mutex.Unlock()
 	//after
-	fmt.Println("after do request:", rb.cnter.Total())
+	rb.flushFast()
 
+	//请求执行期间计数器已经重置，结果属于旧的一代，不能污染新一代的计数
+	if generation != rb.generation {
+		rb.retired.record(generation, resultErr, rb.options.CountProbes)
+		return rb.state
+	}
+	if latency != unmeasured {
+		if rb.latency != nil {
+			rb.latency.Add(latency)
+		}
+		if rb.average != nil {
+			rb.average.Add(latency)
+		}
+	}
+
+	//计完数只拷贝一次计数器，状态判断和打开条件看到的是同一份完整的快照，
+	//不会出现 Requests 已经加一而 ConsecutiveFailures 还没更新的中间状态
+	var next State
 	if resultErr != nil {
 		//失败了,handle 失败
-		rb.cnter.Count(FailureState, rb.preState == rb.state)
-		switch rb.state {
-		case StateHalfOpen, StateClosed:
-			if rb.options.CanOpen(rb.state, rb.cnter) {
-				rb.changeStateTo(StateOpen)                                     //打开开关
-				rb.options.OnStateChanged(rb.options.Name, rb.state, StateOpen) //关闭到打开
-			}
-		}
+		rb.count(FailureState)
+		rb.countErrorGroup(resultErr)
+		rb.countSeverity(resultErr)
+		rb.lastErr = resultErr
+		next = rb.current().OnFailure(rb, rb.snapshot())
 	} else {
 		//success !
-		rb.cnter.Count(SuccessState, rb.preState == rb.state)
-
-		switch rb.state {
-		case StateHalfOpen:
-			if rb.preState == StateOpen {
-				//	rb.changeStateTo(StateHalfOpen) //already handled in beforeRequest,Only fire StateChange Event
-				rb.options.OnStateChanged(rb.options.Name, StateOpen, StateHalfOpen) //打开到半开
-			}
-			if rb.cnter.ConsecutiveSuccesses >= rb.options.ShoulderHalfToOpen {
-				rb.changeStateTo(StateClosed)
-				rb.options.Expiry = time.Now().Add(rb.options.Interval)
-				rb.options.OnStateChanged(rb.options.Name, StateHalfOpen, StateClosed) //半开到关闭
-			}
-
-		}
+		rb.count(SuccessState)
+		next = rb.current().OnSuccess(rb, rb.snapshot())
+	}
 
+	if next != rb.state {
+		rb.changeStateTo(next)
 	}
+	return rb.state
+}
 
+func (rb *RequestBreaker) count(statue OperationState) {
+	if rb.state == StateHalfOpen && !rb.options.CountProbes {
+		rb.counter().CountProbe(statue)
+		return
+	}
+	rb.counter().Count(statue, true)
 }
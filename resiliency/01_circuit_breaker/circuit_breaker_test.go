@@ -3,7 +3,7 @@
  * @Author: Edward
  * @Date: 2020-05-11 10:55:28
  * @Last Modified by: Edward
- * @Last Modified time: 2020-05-22 17:21:06
+ * @Last Modified time: 2026-10-14 18:27:44
  */
 
 package circuit
@@ -23,7 +23,7 @@ var onStateChangeEvent = func(name string, from, to State) {
 	fmt.Println("name:", name, "from:", from, "to", to)
 }
 
-var canOpenSwitch = func(current State, cnter counters) bool {
+var canOpenSwitch = func(current State, cnter Counts) bool {
 
 	if current == StateHalfOpen {
 		return cnter.ConsecutiveFailures > 2
@@ -34,7 +34,7 @@ var canOpenSwitch = func(current State, cnter counters) bool {
 	return cnter.Requests >= 3 && failureRatio >= 0.6
 }
 
-var canCloseSwitch = func(current State, cnter counters) bool {
+var canCloseSwitch = func(current State, cnter Counts) bool {
 	//失败率，可以由用户自己定义
 	if cnter.ConsecutiveSuccesses > 2 {
 		return true
@@ -3,7 +3,7 @@
  * @Author: Edward
  * @Date: 2020-05-22 12:41:54
  * @Last Modified by: Edward
- * @Last Modified time: 2020-05-22 17:22:35
+ * @Last Modified time: 2026-10-14 19:03:05
  */
 
 package circuit
@@ -28,58 +28,148 @@ const (
 	StateUnknown
 )
 
+//String of State
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateHalfOpen:
+		return "half-open"
+	case StateOpen:
+		return "open"
+	}
+	return "unknown"
+}
+
 //OperationState of current 某一次操作的结果状态
 type OperationState int
 
 //ICounter interface
 type ICounter interface {
 	Count(OperationState, bool)
+	CountSuccesses(n uint32)
+	CountProbe(OperationState)
+	CountRejection(error)
 	LastActivity() time.Time
 	Reset()
 	Total() uint32
+	Snapshot() Counts
 }
 
-type counters struct {
+//Counts is the default ICounter and the snapshot every ICounter returns, what the break conditions decide on
+type Counts struct {
 	Requests             uint32 //连续的请求次数
 	lastActivity         time.Time
 	TotalFailures        uint32
 	TotalSuccesses       uint32
 	ConsecutiveSuccesses uint32
 	ConsecutiveFailures  uint32
+	ProbeSuccesses       uint32 //半开状态下试探请求的结果
+	ProbeFailures        uint32
+	//被拒绝的请求,按原因区分
+	RejectedOpen            uint32 //断路器打开
+	RejectedTooManyRequests uint32 //半开状态试探请求已满
+	RejectedShed            uint32 //并发过高被丢弃
+	clock                   Clock  //断路器的时钟，快照里不带
 }
 
-func (c *counters) Total() uint32 {
+func (c *Counts) Total() uint32 {
 	return c.Requests
 }
 
-func (c *counters) LastActivity() time.Time {
+//Snapshot return a copy of counters
+func (c *Counts) Snapshot() Counts {
+	snapshot := *c
+	snapshot.clock = nil
+	return snapshot
+}
+
+func (c *Counts) LastActivity() time.Time {
 	return c.lastActivity
 }
 
-func (c *counters) Reset() {
-	ct := &counters{}
-	ct.lastActivity = c.lastActivity
-	c = ct
+func (c *Counts) Reset() {
+	*c = Counts{lastActivity: c.lastActivity, clock: c.clock}
+}
+
+//now tell the time by the clock of breaker, by the system clock for counters used on their own
+func (c *Counts) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+//useClock make the built-in counter tell LastActivity by clock, other ICounters keep their own time
+func useClock(counter ICounter, clock Clock) {
+	if c, ok := counter.(*Counts); ok && c.clock == nil {
+		c.clock = clock
+	}
 }
 
 //Count the failure and success
-func (c *counters) Count(statue OperationState, isConsecutive bool) {
+func (c *Counts) Count(statue OperationState, isConsecutive bool) {
 
 	switch statue {
 	case FailureState:
 		c.TotalFailures++
+		c.ConsecutiveSuccesses = 0
 		if isConsecutive || c.ConsecutiveFailures == 0 {
 			c.ConsecutiveFailures++
 		}
 	case SuccessState:
 		c.TotalSuccesses++
+		c.ConsecutiveFailures = 0
 		if isConsecutive || c.ConsecutiveSuccesses == 0 {
 			c.ConsecutiveSuccesses++
 		}
 	}
 	c.Requests++
-	c.lastActivity = time.Now() //更新活动时间
+	c.lastActivity = c.now() //更新活动时间
 	//	c.lastOpResult = statue
 	//handle status change
 
 }
+
+//CountSuccesses count n successes in a row at once
+func (c *Counts) CountSuccesses(n uint32) {
+	if n == 0 {
+		return
+	}
+	c.TotalSuccesses += n
+	c.ConsecutiveFailures = 0
+	c.ConsecutiveSuccesses += n
+	c.Requests += n
+	c.lastActivity = c.now()
+}
+
+//CountProbe count the result of a trial request in half-open state,
+//it is kept apart from TotalFailures and TotalSuccesses
+func (c *Counts) CountProbe(statue OperationState) {
+
+	switch statue {
+	case FailureState:
+		c.ProbeFailures++
+		c.ConsecutiveSuccesses = 0
+		c.ConsecutiveFailures++
+	case SuccessState:
+		c.ProbeSuccesses++
+		c.ConsecutiveFailures = 0
+		c.ConsecutiveSuccesses++
+	}
+	c.Requests++
+	c.lastActivity = c.now()
+}
+
+//CountRejection count a request short-circuited by the breaker
+func (c *Counts) CountRejection(reason error) {
+
+	switch reason {
+	case ErrServiceUnavailable:
+		c.RejectedOpen++
+	case ErrTooManyRequests:
+		c.RejectedTooManyRequests++
+	case ErrLoadShed:
+		c.RejectedShed++
+	}
+}
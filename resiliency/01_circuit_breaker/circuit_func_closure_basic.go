@@ -3,13 +3,16 @@
  * @Author: Edward
  * @Date: 2020-05-22 12:42:34
  * @Last Modified by: Edward
- * @Last Modified time: 2020-05-22 17:21:40
+ * @Last Modified time: 2026-10-14 18:58:54
  */
 
 package circuit
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"sync"
 	"time"
 )
 
@@ -31,6 +34,7 @@ const (
 )
 
 type simpleCounter struct {
+	clock                Clock
 	lastOpResult         OperationState
 	lastActivity         time.Time
 	ConsecutiveSuccesses uint32
@@ -57,7 +61,7 @@ func (c *simpleCounter) Count(lastState OperationState) {
 	case SuccessState:
 		c.ConsecutiveSuccesses++
 	}
-	c.lastActivity = time.Now() //更新活动时间
+	c.lastActivity = c.clock.Now() //更新活动时间
 	c.lastOpResult = lastState
 	//handle status change
 }
@@ -67,37 +71,123 @@ func (c *simpleCounter) Count(lastState OperationState) {
 type Circuit func(context.Context) error
 
 //失败达到阈值后,过两秒重试
-var canRetry = func(cnt simpleCounter, failureThreshold uint32) bool {
+var canRetry = func(cnt simpleCounter, failureThreshold uint32, maxBackoff time.Duration) bool {
+	return cnt.clock.Now().After(retryAt(cnt, failureThreshold, maxBackoff))
+}
+
+// Calculates when should the circuit breaker resume propagating requests
+// to the service
+func retryAt(cnt simpleCounter, failureThreshold uint32, maxBackoff time.Duration) time.Time {
+	return cnt.LastActivity().Add(backoffFor(cnt, failureThreshold, maxBackoff))
+}
+
+//backoffFor double the wait for every failure over the threshold, up to maxBackoff if it is set
+func backoffFor(cnt simpleCounter, failureThreshold uint32, maxBackoff time.Duration) time.Duration {
 	backoffLevel := cnt.ConsecutiveFailures - failureThreshold
-	// Calculates when should the circuit breaker resume propagating requests
-	// to the service
-	shouldRetryAt := cnt.LastActivity().Add(time.Second << backoffLevel)
-	return time.Now().After(shouldRetryAt)
+	//移位超过32次就溢出了
+	backoff := time.Duration(math.MaxInt64)
+	if backoffLevel < 32 {
+		backoff = time.Second << backoffLevel
+	}
+	if maxBackoff > 0 && backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return backoff
+}
+
+//WithMaxBackoff cap the wait of Breaker and BreakerWithState after repeated failures,
+//so the wait stops growing during a long outage, 0 means no cap
+func WithMaxBackoff(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.MaxBackoff = d
+	}
+}
+
+//OpenCircuitError is returned by BreakerWithState when it fails fast
+type OpenCircuitError struct {
+	State   State
+	retryAt time.Time
+	clock   Clock
+}
+
+func (e *OpenCircuitError) Error() string {
+	return fmt.Sprintf("%v: circuit is %s, retry after %v", ErrServiceUnavailable, e.State, e.RetryAfter())
 }
 
-//Breaker return a closure wrapper to hold Circuit Request
-func Breaker(c Circuit, failureThreshold uint32) Circuit {
+//RetryAfter return how long to wait before the circuit lets requests go again
+func (e *OpenCircuitError) RetryAfter() time.Duration {
+	if d := e.retryAt.Sub(e.clock.Now()); d > 0 {
+		return d
+	}
+	return 0
+}
+
+//Unwrap make errors.Is(err, ErrServiceUnavailable) hold, so IsRejection and IsBreakerError do too
+func (e *OpenCircuitError) Unwrap() error {
+	return ErrServiceUnavailable
+}
+
+//Breaker return a closure wrapper to hold Circuit Request,
+//only the WithClock and WithMaxBackoff options apply to it
+func Breaker(c Circuit, failureThreshold uint32, opts ...Option) Circuit {
+	options := circuitOptions(opts)
+	return wrapCircuit(c, failureThreshold, options, func(time.Time) error {
+		return ErrServiceUnavailable
+	})
+}
+
+//BreakerWithState is like Breaker, but when failing fast it returns an *OpenCircuitError
+//which tells the state of circuit and the remaining cool-off time
+func BreakerWithState(c Circuit, failureThreshold uint32, opts ...Option) Circuit {
+	options := circuitOptions(opts)
+	return wrapCircuit(c, failureThreshold, options, func(at time.Time) error {
+		return &OpenCircuitError{State: StateOpen, retryAt: at, clock: options.Clock}
+	})
+}
+
+//circuitOptions apply opts to the defaults of the functional breaker
+func circuitOptions(opts []Option) Options {
+	options := Options{Clock: systemClock{}}
+	for _, setOption := range opts {
+		setOption(&options)
+	}
+	return options
+}
+
+func wrapCircuit(c Circuit, failureThreshold uint32, options Options, reject func(retryAt time.Time) error) Circuit {
 
 	//闭包内部的全局计数器 和状态标志
-	cnt := simpleCounter{}
+	//返回的Circuit可能被多个goroutine同时调用，计数器由mutex保护
+	var mutex sync.Mutex
+	cnt := simpleCounter{clock: options.Clock}
+	maxBackoff := options.MaxBackoff
 
 	//ctx can be used hold parameters
 	return func(ctx context.Context) error {
 
 		//阻止请求
+		mutex.Lock()
 		if cnt.ConsecutiveFailures >= failureThreshold {
-			if !canRetry(cnt, failureThreshold) {
+			if !canRetry(cnt, failureThreshold, maxBackoff) {
 				// Fails fast instead of propagating requests to the circuit since
 				// not enough time has passed since the last failure to retry
-				return ErrServiceUnavailable
+				at := retryAt(cnt, failureThreshold, maxBackoff)
+				mutex.Unlock()
+				return reject(at)
 			}
 			//reset mark for failures
 			cnt.ConsecutiveFailures = 0
 		}
+		mutex.Unlock()
 
 		// Unless the failure threshold is exceeded the wrapped service mimics the
 		// old behavior and the difference in behavior is seen after consecutive failures
-		if err := c(ctx); err != nil {
+		//执行请求时不持有锁
+		err := c(ctx)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		if err != nil {
 			//连续失败会增大backoff 时间
 			cnt.Count(FailureState)
 			return err
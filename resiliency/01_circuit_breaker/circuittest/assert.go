@@ -0,0 +1,34 @@
+// Package circuittest provides helpers for asserting breaker behavior in tests.
+package circuittest
+
+import (
+	"testing"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+)
+
+//AssertTransition run action and fail t if rb did not move from wantFrom to wantTo
+func AssertTransition(t testing.TB, rb *circuit.RequestBreaker, action func(), wantFrom, wantTo circuit.State) {
+	t.Helper()
+
+	from := rb.State()
+	action()
+	to := rb.State()
+
+	if from != wantFrom || to != wantTo {
+		t.Errorf("expected transition %s -> %s, observed %s -> %s", wantFrom, wantTo, from, to)
+	}
+}
+
+//AssertNoTransition run action and fail t if the state of rb changed
+func AssertNoTransition(t testing.TB, rb *circuit.RequestBreaker, action func()) {
+	t.Helper()
+
+	from := rb.State()
+	action()
+	to := rb.State()
+
+	if from != to {
+		t.Errorf("expected no transition from %s, observed %s -> %s", from, from, to)
+	}
+}
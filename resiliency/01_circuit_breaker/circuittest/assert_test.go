@@ -0,0 +1,73 @@
+package circuittest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+)
+
+var errFail = errors.New("fail")
+
+func fail(rb *circuit.RequestBreaker) func() {
+	return func() {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errFail })
+	}
+}
+
+func succeed(rb *circuit.RequestBreaker) func() {
+	return func() {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+	}
+}
+
+func TestAssertBreakerCycle(t *testing.T) {
+
+	rb := circuit.NewRequestBreaker(circuit.ActionName("cycle"),
+		circuit.Timeout(10*time.Millisecond),
+		circuit.WithShoulderHalfToOpen(2))
+
+	AssertNoTransition(t, rb, fail(rb))
+	AssertNoTransition(t, rb, fail(rb))
+	AssertTransition(t, rb, fail(rb), circuit.StateClosed, circuit.StateOpen)
+	AssertNoTransition(t, rb, succeed(rb))
+
+	time.Sleep(20 * time.Millisecond)
+
+	AssertTransition(t, rb, succeed(rb), circuit.StateOpen, circuit.StateHalfOpen)
+	AssertTransition(t, rb, succeed(rb), circuit.StateHalfOpen, circuit.StateClosed)
+}
+
+//recorder catch failures reported by the helpers
+type recorder struct {
+	testing.TB
+	failed bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.failed = true
+}
+
+func TestAssertReportsMismatch(t *testing.T) {
+
+	rb := circuit.NewRequestBreaker()
+
+	mock := &recorder{TB: t}
+	AssertTransition(mock, rb, succeed(rb), circuit.StateClosed, circuit.StateOpen)
+	if !mock.failed {
+		t.Error("AssertTransition should fail when no transition happened")
+	}
+
+	mock = &recorder{TB: t}
+	for i := 0; i < 2; i++ {
+		fail(rb)()
+	}
+	AssertNoTransition(mock, rb, fail(rb))
+	if !mock.failed {
+		t.Error("AssertNoTransition should fail when breaker tripped")
+	}
+}
@@ -0,0 +1,73 @@
+package circuittest
+
+import (
+	"context"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+)
+
+//NoopBreaker is a circuit.IBreaker which admits every request and never changes state
+type NoopBreaker struct {
+	name string
+}
+
+//NewNoopBreaker return a NoopBreaker named name
+func NewNoopBreaker(name string) *NoopBreaker {
+	return &NoopBreaker{name: name}
+}
+
+//Do run work
+func (b *NoopBreaker) Do(work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return b.DoContext(context.Background(), work)
+}
+
+//DoContext run work with ctx
+func (b *NoopBreaker) DoContext(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if work == nil {
+		return nil, circuit.ErrNilWork
+	}
+	return work(ctx)
+}
+
+//State is always closed
+func (b *NoopBreaker) State() circuit.State { return circuit.StateClosed }
+
+//AllowRequest is always true
+func (b *NoopBreaker) AllowRequest() bool { return true }
+
+//Name return the name given to NewNoopBreaker
+func (b *NoopBreaker) Name() string { return b.name }
+
+//AlwaysOpenBreaker is a circuit.IBreaker which rejects every request with circuit.ErrServiceUnavailable
+type AlwaysOpenBreaker struct {
+	name string
+}
+
+//NewAlwaysOpenBreaker return an AlwaysOpenBreaker named name
+func NewAlwaysOpenBreaker(name string) *AlwaysOpenBreaker {
+	return &AlwaysOpenBreaker{name: name}
+}
+
+//Do reject work
+func (b *AlwaysOpenBreaker) Do(work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return nil, circuit.ErrServiceUnavailable
+}
+
+//DoContext reject work
+func (b *AlwaysOpenBreaker) DoContext(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	return nil, circuit.ErrServiceUnavailable
+}
+
+//State is always open
+func (b *AlwaysOpenBreaker) State() circuit.State { return circuit.StateOpen }
+
+//AllowRequest is always false
+func (b *AlwaysOpenBreaker) AllowRequest() bool { return false }
+
+//Name return the name given to NewAlwaysOpenBreaker
+func (b *AlwaysOpenBreaker) Name() string { return b.name }
+
+var (
+	_ circuit.IBreaker = (*NoopBreaker)(nil)
+	_ circuit.IBreaker = (*AlwaysOpenBreaker)(nil)
+)
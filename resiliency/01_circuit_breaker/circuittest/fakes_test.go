@@ -0,0 +1,78 @@
+package circuittest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+)
+
+//loadProfile is consumer code, it only knows the interface and falls back to a cached profile
+func loadProfile(cb circuit.IBreaker, fetch func() (string, error)) (string, error) {
+	res, err := cb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return fetch()
+	})
+	if errors.Is(err, circuit.ErrServiceUnavailable) {
+		return "cached:" + cb.Name(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return res.(string), nil
+}
+
+func TestConsumerWithBreakers(t *testing.T) {
+
+	fetch := func() (string, error) { return "fresh", nil }
+
+	tripped := circuit.NewRequestBreaker(circuit.ActionName("profiles"))
+	for i := 0; i < 3; i++ {
+		tripped.Do(func(ctx context.Context) (interface{}, error) { return nil, errFail })
+	}
+
+	cases := []struct {
+		cb   circuit.IBreaker
+		want string
+	}{
+		{circuit.NewRequestBreaker(circuit.ActionName("profiles")), "fresh"},
+		{tripped, "cached:profiles"},
+		{NewNoopBreaker("noop"), "fresh"},
+		{NewAlwaysOpenBreaker("down"), "cached:down"},
+	}
+	for _, c := range cases {
+		got, err := loadProfile(c.cb, fetch)
+		if err != nil || got != c.want {
+			t.Errorf("%s: expected %s, got %s %v", c.cb.Name(), c.want, got, err)
+		}
+	}
+
+	if NewAlwaysOpenBreaker("down").AllowRequest() || NewAlwaysOpenBreaker("down").State() != circuit.StateOpen {
+		t.Error("AlwaysOpenBreaker should look open")
+	}
+	if !NewNoopBreaker("noop").AllowRequest() || NewNoopBreaker("noop").State() != circuit.StateClosed {
+		t.Error("NoopBreaker should look closed")
+	}
+}
+
+//recordingCounter is an ICounter of another package, it wraps the default counter
+type recordingCounter struct {
+	circuit.Counts
+	snapshots int
+}
+
+func (c *recordingCounter) Snapshot() circuit.Counts {
+	c.snapshots++
+	return c.Counts.Snapshot()
+}
+
+func TestCounterOutsidePackage(t *testing.T) {
+
+	counter := &recordingCounter{}
+	rb := circuit.NewRequestBreaker(circuit.WithCounter(counter))
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 1 || counter.snapshots == 0 {
+		t.Errorf("the breaker should count through the custom counter, got %+v", cnt)
+	}
+}
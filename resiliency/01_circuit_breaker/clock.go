@@ -0,0 +1,69 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:03:11
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+import (
+	"sync"
+	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
+)
+
+//Clock tell the breaker what time it is and make its timers, replace it to travel in time in tests.
+//clock.Clock and clock.ManualClock of the resiliency/clock package satisfy it,
+//share one ManualClock with the other patterns to move them together
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) clock.Timer
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) clock.Timer {
+	return clock.Real.NewTimer(d)
+}
+
+//afterFunc call f in its own goroutine once c has advanced d, like time.AfterFunc,
+//stop prevents f from being called if the timer has not fired yet
+func afterFunc(c Clock, d time.Duration, f func()) (stop func() bool) {
+	if _, ok := c.(systemClock); ok {
+		return time.AfterFunc(d, f).Stop
+	}
+
+	timer := c.NewTimer(d)
+	stopped := make(chan struct{})
+	var once sync.Once
+	go func() {
+		select {
+		case <-timer.C():
+			f()
+		case <-stopped:
+		}
+	}()
+	return func() bool {
+		pending := timer.Stop()
+		once.Do(func() { close(stopped) })
+		return pending
+	}
+}
+
+//clock return the clock of rb
+func (rb *RequestBreaker) clock() Clock {
+	rb.lazyInit()
+	return rb.options.Clock
+}
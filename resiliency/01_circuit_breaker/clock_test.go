@@ -0,0 +1,126 @@
+package circuit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
+)
+
+//fakeClock only moves when Advance is called, its timers fire as it moves
+type fakeClock struct {
+	*clock.ManualClock
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{clock.NewManual(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))}
+}
+
+//waitTimer block until a goroutine is waiting on a timer of clock
+func waitTimer(t *testing.T, clock *fakeClock) {
+	t.Helper()
+	for i := 0; clock.Waiters() == 0; i++ {
+		if i == 1000 {
+			t.Fatal("nothing is waiting on the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLastActivityUsesClock(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock))
+
+	clock.Advance(time.Hour)
+	rb.Do(succeedWork)
+	if cnt := rb.Counts(); !cnt.LastActivity().Equal(clock.Now()) {
+		t.Errorf("last activity should be told by the clock of breaker, got %v", cnt.LastActivity())
+	}
+
+	rb.SwapCounter(&Counts{}, nil)
+	clock.Advance(time.Hour)
+	rb.Do(failWork)
+	if cnt := rb.Counts(); !cnt.LastActivity().Equal(clock.Now()) {
+		t.Errorf("a swapped in counter should use the clock too, got %v", cnt.LastActivity())
+	}
+}
+
+func TestHedgerUsesClock(t *testing.T) {
+
+	clock := newFakeClock()
+	h := NewHedger(NewRequestBreaker(WithClock(clock)), time.Minute)
+
+	var calls int32
+	result := make(chan interface{}, 1)
+	go func() {
+		res, _ := h.Do(context.Background(), func(ctx context.Context) (interface{}, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return "hedge", nil
+		})
+		result <- res
+	}()
+
+	//对冲只在时钟走过delay之后才发出
+	waitTimer(t, clock)
+	if n := atomic.LoadInt32(&calls); n > 1 {
+		t.Fatalf("no hedge before the clock moves, got %d calls", n)
+	}
+	clock.Advance(time.Minute)
+	if res := <-result; res != "hedge" {
+		t.Errorf("expected the hedge to win, got %v", res)
+	}
+}
+
+func TestCoalescerUsesClock(t *testing.T) {
+
+	clock := newFakeClock()
+	c := NewCoalescer(NewRequestBreaker(WithClock(clock)), func(ctx context.Context, items []int) ([]int, error) {
+		return items, nil
+	}, time.Minute, 0)
+
+	result := make(chan int, 1)
+	go func() {
+		res, _ := c.Add(context.Background(), 7)
+		result <- res
+	}()
+
+	waitTimer(t, clock)
+	select {
+	case <-result:
+		t.Fatal("batch should wait for the window on the clock")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Minute)
+	if res := <-result; res != 7 {
+		t.Errorf("expected 7, got %d", res)
+	}
+}
+
+func TestRetryDecoratorUsesClock(t *testing.T) {
+
+	clock := newFakeClock()
+	calls := 0
+	result := make(chan error, 1)
+	go func() {
+		_, err := RetryDecorator(2, time.Hour, WithClock(clock))(func(ctx context.Context) (interface{}, error) {
+			calls++
+			if calls == 1 {
+				return nil, errBackendDown
+			}
+			return "ok", nil
+		})(context.Background())
+		result <- err
+	}()
+
+	waitTimer(t, clock)
+	clock.Advance(time.Hour)
+	if err := <-result; err != nil || calls != 2 {
+		t.Errorf("expected success after the backoff on the clock, got %v after %d calls", err, calls)
+	}
+}
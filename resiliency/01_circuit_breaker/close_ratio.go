@@ -0,0 +1,78 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:52:06
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:30:41
+ */
+
+package circuit
+
+import "fmt"
+
+////////////////////////////////
+/// 半开状态按比例闭合
+/// 连续成功才闭合太脆弱，一次抖动就要从头再来
+/// 这里只看最近 m 次试探里成功了几次
+////////////////////////////////
+
+// WithCloseOnSuccessRatio closes a half-open breaker once at least n of the last m probes succeeded,
+// instead of requiring ShoulderHalfToOpen successes in a row.
+// A failed probe reopens the breaker once n successes are out of reach, that is when more than
+// m-n of the last m probes failed. MaxRequests should be at least m, or the window never fills.
+func WithCloseOnSuccessRatio(n, m uint32) Option {
+	return func(opts *Options) {
+		opts.CloseSuccesses = n
+		opts.CloseWindow = m
+	}
+}
+
+//probeWindow remember the outcomes of the last probes, guarded by the mutex of breaker
+type probeWindow struct {
+	outcomes []bool //true表示成功，环形缓冲区
+	next     int
+}
+
+func (w *probeWindow) add(size uint32, success bool) {
+	if len(w.outcomes) < int(size) {
+		w.outcomes = append(w.outcomes, success)
+		return
+	}
+	w.outcomes[w.next] = success
+	w.next = (w.next + 1) % len(w.outcomes)
+}
+
+func (w *probeWindow) count() (successes, failures uint32) {
+	for _, success := range w.outcomes {
+		if success {
+			successes++
+		} else {
+			failures++
+		}
+	}
+	return successes, failures
+}
+
+func (w *probeWindow) reset() {
+	w.outcomes = w.outcomes[:0]
+	w.next = 0
+}
+
+// closeByRatio reports whether the ratio mode is on, the next state after a probe and why, it must be called with the mutex held.
+// The reason is only returned, the caller records it once the transition is decided.
+func (rb *RequestBreaker) closeByRatio(success bool) (bool, State, string) {
+	n, m := rb.options.CloseSuccesses, rb.options.CloseWindow
+	if n == 0 || m == 0 {
+		return false, StateHalfOpen, ""
+	}
+
+	rb.window.add(m, success)
+	successes, failures := rb.window.count()
+	switch {
+	case successes >= n:
+		return true, StateClosed, fmt.Sprintf("%d of the last %d probes succeeded", successes, m)
+	case failures > m-n:
+		return true, StateOpen, fmt.Sprintf("%d of the last %d probes failed, %d successes are needed", failures, m, n)
+	}
+	return true, StateHalfOpen, ""
+}
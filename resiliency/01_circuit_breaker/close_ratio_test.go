@@ -0,0 +1,72 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func failWork(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+
+func TestCloseOnSuccessRatio(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithCloseOnSuccessRatio(3, 4))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	probes := []struct {
+		work func(ctx context.Context) (interface{}, error)
+		want State
+	}{
+		{succeedWork, StateHalfOpen},
+		{succeedWork, StateHalfOpen},
+		{failWork, StateHalfOpen}, //一次抖动不会前功尽弃
+		{succeedWork, StateClosed},
+	}
+	for i, probe := range probes {
+		rb.Do(probe.work)
+		if got := rb.State(); got != probe.want {
+			t.Fatalf("after probe %d expected %s, got %s", i+1, probe.want, got)
+		}
+	}
+}
+
+func TestCloseOnSuccessRatioReopens(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithCloseOnSuccessRatio(3, 4))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	rb.Do(failWork)
+	if rb.State() != StateHalfOpen {
+		t.Fatalf("one failure still leaves 3 of 4 reachable, got %s", rb.State())
+	}
+	rb.Do(failWork)
+	if rb.State() != StateOpen {
+		t.Errorf("two failures make 3 of 4 unreachable, got %s", rb.State())
+	}
+}
+
+func TestCloseOnSuccessRatioReasonAfterVerification(t *testing.T) {
+
+	clock := newFakeClock()
+	healthy := false
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithCloseOnSuccessRatio(1, 1),
+		WithRecoveryVerifier(func(ctx context.Context) bool { return healthy }))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	//比例达到了但是检查没通过，不能留下闭合的原因
+	rb.Do(succeedWork)
+	if rb.State() != StateHalfOpen || rb.reason != "" {
+		t.Fatalf("a rejected recovery should not record a reason, got %s %q", rb.State(), rb.reason)
+	}
+
+	healthy = true
+	rb.Do(succeedWork)
+	if rb.State() != StateClosed || rb.LastTransitionReason() != "1 of the last 1 probes succeeded" {
+		t.Errorf("unexpected close %s %q", rb.State(), rb.LastTransitionReason())
+	}
+}
@@ -0,0 +1,136 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:26:49
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 批量合并 + 断路器
+/// 一小段时间内到达的单个调用攒成一批，只调用一次后端的批量接口，结果再分发给每个调用者
+/// 断路器把一批当作一个请求，打开时整批的调用者一起被拒绝
+////////////////////////////////
+
+//ErrBatchResults is returned to every caller of a batch when the batch work returns a wrong number of results
+var ErrBatchResults = errors.New("batch work returned a wrong number of results")
+
+//ErrBatchPanicked is returned to every caller of a batch whose work panicked, wrapped with the value of the panic
+var ErrBatchPanicked = errors.New("batch work panicked")
+
+//BatchWork run one batched call, it returns one result per item in the same order
+type BatchWork[I, R any] func(ctx context.Context, items []I) ([]R, error)
+
+type batch[I, R any] struct {
+	items   []I
+	results []R
+	err     error
+	stop    func() bool //停掉窗口的定时器
+	done    chan struct{}
+}
+
+//Coalescer gather the items added within a window into one batch run through a breaker
+type Coalescer[I, R any] struct {
+	rb      *RequestBreaker
+	work    BatchWork[I, R]
+	window  time.Duration
+	maxSize int
+
+	mutex   sync.Mutex
+	pending *batch[I, R] //正在攒的一批，为空表示还没有
+}
+
+// NewCoalescer return a coalescer running work through rb, a batch is sent window after its first item
+// or as soon as it has maxSize items, maxSize <= 0 means no cap.
+func NewCoalescer[I, R any](rb *RequestBreaker, work BatchWork[I, R], window time.Duration, maxSize int) *Coalescer[I, R] {
+	return &Coalescer[I, R]{rb: rb, work: work, window: window, maxSize: maxSize}
+}
+
+// Add put item in the pending batch and wait for its result.
+// The batch is one request to the breaker: its error, or the rejection of an open breaker, is returned to every item in it.
+// The batched work does not see the ctx of any caller, a caller whose ctx is done stops waiting with ctx.Err()
+// but its item stays in the batch.
+func (c *Coalescer[I, R]) Add(ctx context.Context, item I) (R, error) {
+
+	c.mutex.Lock()
+	b := c.pending
+	if b == nil {
+		b = &batch[I, R]{done: make(chan struct{})}
+		b.stop = afterFunc(c.rb.clock(), c.window, func() { c.flush(b) })
+		c.pending = b
+	}
+	index := len(b.items)
+	b.items = append(b.items, item)
+	full := c.maxSize > 0 && len(b.items) >= c.maxSize
+	c.mutex.Unlock()
+
+	//攒满了不等窗口，由这个调用者发出
+	if full {
+		b.stop()
+		c.flush(b)
+	}
+
+	var zero R
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+	if b.err != nil {
+		return zero, b.err
+	}
+	return b.results[index], nil
+}
+
+//flush send b through the breaker once, whichever of the timer and a full batch comes first
+func (c *Coalescer[I, R]) flush(b *batch[I, R]) {
+
+	c.mutex.Lock()
+	if c.pending != b {
+		c.mutex.Unlock()
+		return
+	}
+	c.pending = nil
+	c.mutex.Unlock()
+
+	//work panic 的时候也要放开这一批的调用者，panic 作为错误交给它们，不让定时器的 goroutine 崩溃
+	finished := false
+	defer func() {
+		if !finished {
+			b.results, b.err = nil, ErrBatchPanicked
+			if r := recover(); r != nil {
+				b.err = fmt.Errorf("%w: %v", ErrBatchPanicked, r)
+			}
+		}
+		close(b.done)
+	}()
+
+	//摘下来之后没有人再改 items
+	result, err := c.rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		results, err := c.work(ctx, b.items)
+		if err != nil {
+			return nil, err
+		}
+		if len(results) != len(b.items) {
+			return nil, ErrBatchResults
+		}
+		return results, nil
+	})
+	results, ok := result.([]R)
+	if err == nil && !ok {
+		//OutcomeHooks 把失败算成了成功，调用者仍然拿不到结果
+		err = ErrBatchResults
+	}
+	b.results, b.err = results, err
+	finished = true
+}
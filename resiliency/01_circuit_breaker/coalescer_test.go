@@ -0,0 +1,131 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//timesTen record every batch and return item*10 for each item
+type timesTen struct {
+	mutex   sync.Mutex
+	batches [][]int
+}
+
+func (b *timesTen) work(ctx context.Context, items []int) ([]int, error) {
+	b.mutex.Lock()
+	b.batches = append(b.batches, append([]int(nil), items...))
+	b.mutex.Unlock()
+
+	results := make([]int, len(items))
+	for i, item := range items {
+		results[i] = item * 10
+	}
+	return results, nil
+}
+
+func (b *timesTen) calls() int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return len(b.batches)
+}
+
+func TestCoalescerSharesOneBatch(t *testing.T) {
+
+	backend := &timesTen{}
+	rb := NewRequestBreaker()
+
+	for _, c := range []struct {
+		name    string
+		window  time.Duration
+		maxSize int
+	}{
+		{"window", 100 * time.Millisecond, 0},
+		{"size cap", time.Hour, 3},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			backend.batches = nil
+			coalescer := NewCoalescer[int, int](rb, backend.work, c.window, c.maxSize)
+
+			var wg sync.WaitGroup
+			for item := 1; item <= 3; item++ {
+				wg.Add(1)
+				go func(item int) {
+					defer wg.Done()
+					result, err := coalescer.Add(context.Background(), item)
+					if err != nil || result != item*10 {
+						t.Errorf("item %d: expected %d, got %v %v", item, item*10, result, err)
+					}
+				}(item)
+			}
+			wg.Wait()
+
+			if backend.calls() != 1 || len(backend.batches[0]) != 3 {
+				t.Errorf("items should share one batched call, got %v", backend.batches)
+			}
+		})
+	}
+
+	if cnt := rb.Counts(); cnt.Requests != 2 {
+		t.Errorf("the breaker should see one request per batch, got %+v", cnt)
+	}
+}
+
+func TestCoalescerRejectsBatchWhenOpen(t *testing.T) {
+
+	backend := &timesTen{}
+	rb := NewRequestBreaker()
+	tripBreaker(t, rb)
+
+	coalescer := NewCoalescer[int, int](rb, backend.work, time.Hour, 2)
+	errs := make(chan error, 2)
+	for item := 1; item <= 2; item++ {
+		go func(item int) {
+			_, err := coalescer.Add(context.Background(), item)
+			errs <- err
+		}(item)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != ErrServiceUnavailable {
+			t.Errorf("every caller of the batch should be rejected, got %v", err)
+		}
+	}
+	if backend.calls() != 0 {
+		t.Errorf("an open breaker should not run the batch, got %v", backend.batches)
+	}
+}
+
+func TestCoalescerWrongResultCount(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	coalescer := NewCoalescer[int, int](rb, func(ctx context.Context, items []int) ([]int, error) {
+		return nil, nil
+	}, time.Millisecond, 0)
+
+	if _, err := coalescer.Add(context.Background(), 1); err != ErrBatchResults {
+		t.Errorf("expected ErrBatchResults, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("a batch with missing results is a failure, got %+v", cnt)
+	}
+}
+
+func TestCoalescerBatchPanics(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	coalescer := NewCoalescer[int, int](rb, func(ctx context.Context, items []int) ([]int, error) {
+		panic("boom")
+	}, time.Millisecond, 0)
+
+	//定时器发出的这一批 panic 了，调用者拿到错误而不是一直等
+	_, err := coalescer.Add(context.Background(), 1)
+	if !errors.Is(err, ErrBatchPanicked) || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected ErrBatchPanicked with the panic, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("a panicking batch is a failure, got %+v", cnt)
+	}
+}
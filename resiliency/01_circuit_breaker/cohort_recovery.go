@@ -0,0 +1,130 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:06:50
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:55:42
+ */
+
+package circuit
+
+import (
+	"math"
+	"sync"
+)
+
+////////////////////////////////
+/// 分批恢复
+/// 很多key一起失败之后，如果打开时间一到就一起试探，后端可能又被压垮
+/// 按key的哈希分批，一批试探成功了再放开下一批，每批翻倍
+////////////////////////////////
+
+// WithCohortRecovery lets the keys of a MultiBreaker recover in cohorts instead of all at once.
+// Once the open Timeout of a key has passed, it may only turn half-open if its hash falls in the
+// first fraction of the key space, e.g. 0.1 for 10% of the keys, the others keep being rejected
+// with ErrServiceUnavailable and stay open. When every tripped key of the cohort has closed,
+// the fraction doubles for the next cohort, and it keeps doubling while no tripped key falls in the cohort.
+// A new trip of a closed key starts over from fraction.
+// A fraction out of (0, 1) recovers all keys at once.
+func WithCohortRecovery(fraction float64) MultiOption {
+	return func(mb *MultiBreaker) {
+		if fraction > 0 && fraction < 1 {
+			mb.cohort = &cohortRecovery{initial: fraction, fraction: fraction, tripped: make(map[string]float64)}
+		}
+	}
+}
+
+type cohortRecovery struct {
+	mutex    sync.Mutex
+	initial  float64
+	fraction float64 //当前可以恢复的key占哈希空间的比例
+	//打开了还没有闭合的key，和它在哈希空间的位置
+	tripped  map[string]float64
+	inCohort int //tripped 里位置在fraction之前的key数
+}
+
+//position return where key falls in the hash space, in [0, 1)
+func position(key string) float64 {
+	//FNV-1a 的高位主要由最后一个字节决定，"key-1"、"key-2"这样的key会挤在一起，
+	//用murmur3的fmix32打散
+	h := keyHash(key)
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return float64(h) / (1 << 32)
+}
+
+//watch follow the transitions of the breaker of key
+func (c *cohortRecovery) watch(key string, rb *RequestBreaker) {
+	pos := position(key)
+	//通知时断路器持有自己的锁，这里只动cohortRecovery自己的状态
+	rb.Events().Subscribe(ObserverFunc[StateChange](func(change StateChange) {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		switch {
+		case change.From == StateClosed && change.To == StateOpen:
+			c.tripped[key] = pos
+			if c.fraction != c.initial {
+				c.fraction = c.initial
+				c.recount()
+			} else if pos < c.fraction {
+				c.inCohort++
+			}
+			//打开的key都不在这一批里的话，没有哪个会闭合来放开下一批
+			c.advance()
+		case change.To == StateClosed:
+			if _, ok := c.tripped[key]; !ok {
+				return
+			}
+			delete(c.tripped, key)
+			if pos < c.fraction {
+				c.inCohort--
+			}
+			//这一批都闭合了，放开下一批
+			c.advance()
+		}
+	}))
+}
+
+//advance double the fraction until the cohort holds a tripped key, must be called with the mutex held
+func (c *cohortRecovery) advance() {
+	//下一批可能没有打开的key，接着翻倍
+	for c.inCohort == 0 && c.fraction < 1 {
+		c.fraction = math.Min(1, c.fraction*2)
+		c.recount()
+	}
+}
+
+//recount count the tripped keys of current cohort, must be called with the mutex held
+func (c *cohortRecovery) recount() {
+	c.inCohort = 0
+	for _, pos := range c.tripped {
+		if pos < c.fraction {
+			c.inCohort++
+		}
+	}
+}
+
+//admit report whether a request for key may reach rb, only a breaker about to recover is held back
+func (c *cohortRecovery) admit(key string, rb *RequestBreaker) bool {
+	if rb.State() != StateOpen || rb.RetryAfter() != 0 || rb.options.DryRun {
+		return true
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return position(key) < c.fraction
+}
+
+//RecoveryFraction return the share of keys currently allowed to recover, 1 without cohort recovery
+func (mb *MultiBreaker) RecoveryFraction() float64 {
+	if mb.cohort == nil {
+		return 1
+	}
+	mb.cohort.mutex.Lock()
+	defer mb.cohort.mutex.Unlock()
+	return mb.cohort.fraction
+}
@@ -0,0 +1,152 @@
+package circuit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCohortRecovery(t *testing.T) {
+
+	const keys = 200
+
+	clock := newFakeClock()
+	mb := NewMultiBreaker(func(req interface{}) string { return req.(string) },
+		func(key string) *RequestBreaker {
+			//两次试探成功才闭合，一轮请求里一批key不会全部闭合
+			return NewRequestBreaker(ActionName(key), WithClock(clock), Timeout(time.Second), WithShoulderHalfToOpen(2))
+		},
+		WithCohortRecovery(0.1))
+
+	for i := 0; i < keys; i++ {
+		for j := 0; j < 3; j++ {
+			mb.Do(fmt.Sprint("key-", i), failWork)
+		}
+	}
+	if sum := mb.Summary(); sum.Open != keys {
+		t.Fatalf("all keys should be open, got %+v", sum)
+	}
+	clock.Advance(2 * time.Second)
+
+	//每一轮每个key请求一次，数一数这一轮有多少个key的请求放进来了
+	var admitted []int
+	for round := 0; round < 20 && mb.Summary().Closed < keys; round++ {
+		n := 0
+		for i := 0; i < keys; i++ {
+			if _, err := mb.Do(fmt.Sprint("key-", i), succeedWork); err == nil {
+				n++
+			}
+		}
+		admitted = append(admitted, n)
+	}
+
+	if len(admitted) < 3 {
+		t.Fatalf("keys should recover over several cohorts, got %v", admitted)
+	}
+	if first := admitted[0]; first == 0 || first > keys/5 {
+		t.Errorf("only about 10%% of keys should probe first, got %d of %d", first, keys)
+	}
+	for i := 1; i < len(admitted); i++ {
+		if admitted[i] < admitted[i-1] {
+			t.Errorf("admitted keys should never shrink, got %v", admitted)
+		}
+	}
+	if admitted[len(admitted)-1] <= admitted[0] {
+		t.Errorf("admitted keys should grow as cohorts recover, got %v", admitted)
+	}
+	if sum := mb.Summary(); sum.Closed != keys || mb.RecoveryFraction() != 1 {
+		t.Errorf("all keys should recover in the end, got %+v, fraction %v, admitted %v", sum, mb.RecoveryFraction(), admitted)
+	}
+}
+
+//keyAt return the first key whose position is within [low, high)
+func keyAt(low, high float64) string {
+	for i := 0; ; i++ {
+		if k := fmt.Sprint("key-", i); position(k) >= low && position(k) < high {
+			return k
+		}
+	}
+}
+
+func TestCohortRecoveryRejectsOutsideCohort(t *testing.T) {
+
+	clock := newFakeClock()
+	mb := NewMultiBreaker(func(req interface{}) string { return req.(string) },
+		func(key string) *RequestBreaker {
+			return NewRequestBreaker(ActionName(key), WithClock(clock), Timeout(time.Hour))
+		},
+		WithCohortRecovery(0.1))
+
+	//第一批里有打开的key，不在第一批里的key要等
+	first, key := keyAt(0, 0.1), keyAt(0.5, 1)
+	for j := 0; j < 3; j++ {
+		mb.Do(first, failWork)
+		mb.Do(key, failWork)
+	}
+	clock.Advance(2 * time.Hour)
+
+	if _, err := mb.Do(key, succeedWork); err != ErrServiceUnavailable {
+		t.Errorf("key outside the first cohort should wait, got %v", err)
+	}
+	if state := mb.Breaker(key).State(); state != StateOpen {
+		t.Errorf("held back key should stay open, got %s", state)
+	}
+
+	//第一批闭合之后轮到它
+	mb.Do(first, succeedWork)
+	if _, err := mb.Do(key, succeedWork); err != nil {
+		t.Errorf("key should recover once the first cohort closed, got %v", err)
+	}
+}
+
+func TestCohortRecoveryOnlyOutsideFirstCohort(t *testing.T) {
+
+	clock := newFakeClock()
+	mb := NewMultiBreaker(func(req interface{}) string { return req.(string) },
+		func(key string) *RequestBreaker {
+			return NewRequestBreaker(ActionName(key), WithClock(clock), Timeout(time.Second))
+		},
+		WithCohortRecovery(0.1))
+
+	//打开的key都不在第一批里，没有哪个会闭合来放开下一批，直接翻倍到有打开的key的那一批
+	key := keyAt(0.5, 1)
+	for j := 0; j < 3; j++ {
+		mb.Do(key, failWork)
+	}
+	if got := mb.RecoveryFraction(); got <= position(key) {
+		t.Fatalf("the cohort should grow to hold the tripped key at %v, got %v", position(key), got)
+	}
+
+	clock.Advance(2 * time.Second)
+	if _, err := mb.Do(key, succeedWork); err != nil || mb.Breaker(key).State() != StateClosed {
+		t.Errorf("the only tripped key should recover, got %v in %s", err, mb.Breaker(key).State())
+	}
+}
+
+func TestCohortRecoveryClone(t *testing.T) {
+
+	clock := newFakeClock()
+	mb := NewMultiBreaker(func(req interface{}) string { return req.(string) },
+		func(key string) *RequestBreaker {
+			return NewRequestBreaker(ActionName(key), WithClock(clock), Timeout(time.Hour))
+		},
+		WithCohortRecovery(0.1))
+	first, key := keyAt(0, 0.1), keyAt(0.5, 1)
+	mb.Breaker(first)
+	mb.Breaker(key)
+
+	//克隆出来的断路器也要跟踪，第一批里打开的key闭合后才轮到后面的
+	clone := mb.Clone()
+	for j := 0; j < 3; j++ {
+		clone.Do(first, failWork)
+		clone.Do(key, failWork)
+	}
+	clock.Advance(2 * time.Hour)
+	if _, err := clone.Do(key, succeedWork); err != ErrServiceUnavailable {
+		t.Errorf("the clone should hold back the key outside the cohort, got %v", err)
+	}
+	clone.Do(first, succeedWork)
+	if _, err := clone.Do(key, succeedWork); err != nil {
+		t.Errorf("the clone should track the close of its cohort, got %v", err)
+	}
+}
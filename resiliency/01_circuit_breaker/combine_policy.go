@@ -0,0 +1,62 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:20:37
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:27:44
+ */
+
+package circuit
+
+////////////////////////////////
+/// 组合模式
+/// 几个打开条件组合成一个，对断路器来说和单个条件没有区别
+////////////////////////////////
+
+//AnyOrAll is how CombineTripPolicies combines the decisions of its policies
+type AnyOrAll int
+
+//modes of CombineTripPolicies
+const (
+	//TripAny trips once any of the policies says so
+	TripAny AnyOrAll = iota
+	//TripAll trips only when all of the policies say so
+	TripAll
+)
+
+func (m AnyOrAll) String() string {
+	switch m {
+	case TripAny:
+		return "any"
+	case TripAll:
+		return "all"
+	}
+	return "unknown"
+}
+
+// CombineTripPolicies return one break condition asking every policy about the same counters,
+// e.g. CombineTripPolicies(TripAny, consecutive, slow) to trip on either of them.
+// Nil policies are skipped, with no policy left the condition never trips, whatever the mode.
+// Use it with WithBreakCondition, the policies are then called with the mutex held like any break condition.
+func CombineTripPolicies(mode AnyOrAll, policies ...BreakConditionWatcher) BreakConditionWatcher {
+
+	children := make([]BreakConditionWatcher, 0, len(policies))
+	for _, policy := range policies {
+		if policy != nil {
+			children = append(children, policy)
+		}
+	}
+
+	return func(state State, cnter Counts) bool {
+		if len(children) == 0 {
+			return false
+		}
+		for _, policy := range children {
+			if policy(state, cnter) == (mode == TripAny) {
+				//任意一个为真(Any)或者任意一个为假(All)就有结论了
+				return mode == TripAny
+			}
+		}
+		return mode != TripAny
+	}
+}
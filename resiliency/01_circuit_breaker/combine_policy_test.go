@@ -0,0 +1,72 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCombineTripPoliciesAny(t *testing.T) {
+
+	consecutive, err := NewTripPolicy("consecutive", map[string]interface{}{"failures": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newBreaker := func() (*RequestBreaker, *TDigestLatency) {
+		latency := NewTDigestLatency(100)
+		slow := func(state State, cnter Counts) bool {
+			return latency.Count() > 0 && latency.Quantile(0.99) > 500*time.Millisecond
+		}
+		return NewRequestBreaker(WithBreakCondition(CombineTripPolicies(TripAny, consecutive, slow))), latency
+	}
+
+	//只有连续失败
+	rb, _ := newBreaker()
+	for i := 0; i < 2; i++ {
+		rb.Do(failWork)
+	}
+	if rb.State() != StateClosed {
+		t.Fatalf("two failures should not trip, got %s", rb.State())
+	}
+	rb.Do(failWork)
+	if rb.State() != StateOpen {
+		t.Errorf("consecutive failures alone should trip, got %s", rb.State())
+	}
+
+	//只有延迟过高，一次失败就打开
+	rb, latency := newBreaker()
+	slowFailure := func(ctx context.Context) (interface{}, error) {
+		latency.Add(time.Second)
+		return nil, errBackendDown
+	}
+	rb.Do(slowFailure)
+	if rb.State() != StateOpen {
+		t.Errorf("high latency alone should trip, got %s", rb.State())
+	}
+}
+
+func TestCombineTripPoliciesAll(t *testing.T) {
+
+	yes := func(state State, cnter Counts) bool { return true }
+	no := func(state State, cnter Counts) bool { return false }
+
+	cases := []struct {
+		mode     AnyOrAll
+		policies []BreakConditionWatcher
+		want     bool
+	}{
+		{TripAny, []BreakConditionWatcher{no, yes}, true},
+		{TripAny, []BreakConditionWatcher{no, no}, false},
+		{TripAll, []BreakConditionWatcher{yes, yes}, true},
+		{TripAll, []BreakConditionWatcher{yes, no}, false},
+		{TripAll, []BreakConditionWatcher{yes, nil}, true},
+		{TripAll, nil, false},
+		{TripAny, nil, false},
+	}
+	for i, c := range cases {
+		if got := CombineTripPolicies(c.mode, c.policies...)(StateClosed, Counts{}); got != c.want {
+			t.Errorf("case %d %s: expected %v, got %v", i, c.mode, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:11:59
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:11:59
+ */
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+////////////////////////////////
+/// 命令模式 + 断路器
+/// 每个任务封装成命令，通过断路器执行，可以排队、重试
+/// 中途失败(比如断路器打开)时停止，可以撤销已经执行过的命令
+////////////////////////////////
+
+//Command is a unit of work that can be queued
+type Command interface {
+	Execute() error
+}
+
+//Undoer is implemented by commands that can be rolled back
+type Undoer interface {
+	Undo() error
+}
+
+//BreakerCommand run work through a breaker
+type BreakerCommand struct {
+	rb   *RequestBreaker
+	work func(ctx context.Context) error
+	undo func() error
+}
+
+//NewBreakerCommand return a command running work through rb, undo may be nil
+func NewBreakerCommand(rb *RequestBreaker, work func(ctx context.Context) error, undo func() error) *BreakerCommand {
+	return &BreakerCommand{rb: rb, work: work, undo: undo}
+}
+
+//Execute run the work if the breaker accepts it
+func (c *BreakerCommand) Execute() error {
+	_, err := c.rb.Do(func(ctx context.Context) (interface{}, error) {
+		return nil, c.work(ctx)
+	})
+	return err
+}
+
+//Undo roll back the work, it does nothing without an undo func
+func (c *BreakerCommand) Undo() error {
+	if c.undo == nil {
+		return nil
+	}
+	return c.undo()
+}
+
+//CommandError tells which command stopped the queue
+type CommandError struct {
+	Index int
+	Err   error
+}
+
+func (e *CommandError) Error() string {
+	return fmt.Sprintf("command queue stopped at command %d: %v", e.Index, e.Err)
+}
+
+//Unwrap return the error of the command
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+//CommandQueue execute commands in order, it is not safe for concurrent use
+type CommandQueue struct {
+	cmds          []Command
+	undoOnFailure bool
+}
+
+//NewCommandQueue return an empty queue, with undoOnFailure executed commands are undone when one fails
+func NewCommandQueue(undoOnFailure bool) *CommandQueue {
+	return &CommandQueue{undoOnFailure: undoOnFailure}
+}
+
+//Enqueue append commands to the end of queue
+func (q *CommandQueue) Enqueue(cmds ...Command) *CommandQueue {
+	q.cmds = append(q.cmds, cmds...)
+	return q
+}
+
+//Len return the number of queued commands
+func (q *CommandQueue) Len() int {
+	return len(q.cmds)
+}
+
+// Run executes the queued commands in order and stops at the first one that fails,
+// for example because its breaker is open. The failed command and the ones after it stay
+// queued so Run can be retried. With undoOnFailure the executed commands are undone in
+// reverse order and stay queued as well, errors of Undo are joined to the returned error.
+func (q *CommandQueue) Run() error {
+
+	for i, cmd := range q.cmds {
+		err := cmd.Execute()
+		if err == nil {
+			continue
+		}
+
+		err = &CommandError{Index: i, Err: err}
+		if !q.undoOnFailure {
+			q.cmds = q.cmds[i:]
+			return err
+		}
+
+		errs := []error{err}
+		for j := i - 1; j >= 0; j-- {
+			if undoer, ok := q.cmds[j].(Undoer); ok {
+				if undoErr := undoer.Undo(); undoErr != nil {
+					errs = append(errs, fmt.Errorf("undo command %d: %w", j, undoErr))
+				}
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	q.cmds = nil
+	return nil
+}
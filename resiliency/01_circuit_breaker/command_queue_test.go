@@ -0,0 +1,74 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCommandQueueUndoWhenBreakerOpens(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("jobs"))
+
+	var done, undone []int
+	cmd := func(i int, work func()) Command {
+		return NewBreakerCommand(rb, func(ctx context.Context) error {
+			work()
+			done = append(done, i)
+			return nil
+		}, func() error {
+			undone = append(undone, i)
+			return nil
+		})
+	}
+
+	//其他请求在命令1执行期间让断路器打开了
+	q := NewCommandQueue(true).Enqueue(
+		cmd(0, func() {}),
+		cmd(1, func() { tripBreaker(t, rb) }),
+		cmd(2, func() {}),
+		cmd(3, func() {}),
+	)
+
+	err := q.Run()
+
+	var cmdErr *CommandError
+	if !errors.As(err, &cmdErr) || cmdErr.Index != 2 {
+		t.Fatalf("queue should stop at command 2, got %v", err)
+	}
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("expected ErrServiceUnavailable, got %v", err)
+	}
+	if len(done) != 2 {
+		t.Errorf("only commands 0 and 1 should run, got %v", done)
+	}
+	if len(undone) != 2 || undone[0] != 1 || undone[1] != 0 {
+		t.Errorf("completed commands should be undone in reverse order, got %v", undone)
+	}
+	if q.Len() != 4 {
+		t.Errorf("undone commands should stay queued, got %d", q.Len())
+	}
+}
+
+func TestCommandQueueKeepsRemaining(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	tripBreaker(t, rb)
+
+	ran := 0
+	ok := NewBreakerCommand(NewRequestBreaker(), func(ctx context.Context) error { ran++; return nil }, nil)
+	rejected := NewBreakerCommand(rb, func(ctx context.Context) error { ran++; return nil }, nil)
+
+	q := NewCommandQueue(false).Enqueue(ok, rejected, ok)
+	err := q.Run()
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Fatalf("expected ErrServiceUnavailable, got %v", err)
+	}
+	if ran != 1 || q.Len() != 2 {
+		t.Errorf("queue should stop at the open breaker, ran %d, queued %d", ran, q.Len())
+	}
+
+	if err := NewCommandQueue(false).Enqueue(ok, ok).Run(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:12:04
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:52:10
+ */
+
+package circuit
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+////////////////////////////////
+/// 导出和比较断路器的配置
+/// 配置评审和漂移检查只关心能比较的部分：时长、阈值、开关和打开条件的名字
+/// 回调、时钟、计数器这些没法比较的不导出
+////////////////////////////////
+
+//defaultTripPolicy is the TripPolicy of the built-in break condition, more than 2 consecutive failures
+const defaultTripPolicy = "default"
+
+//customTripPolicy is reported for a break condition which was not built by WithTripPolicy
+const customTripPolicy = "custom"
+
+//Config is the resolved configuration of a breaker, every field is comparable
+type Config struct {
+	Name                     string
+	Interval, Timeout        time.Duration
+	MaxRequests              uint32
+	ShoulderHalfToOpen       uint32
+	CountProbes              bool
+	AdaptiveTimeout          AdaptiveTimeout //为零值表示关闭
+	MaxInFlight              uint32
+	IntervalJitter           float64
+	HealthAlpha              float64
+	LatencyTarget            time.Duration
+	ChaosRate                float64
+	Warmup                   time.Duration
+	RequestTimeout           time.Duration
+	HistorySize              int
+	MinErrorGroups           uint32
+	HalfOpenFailureTolerance uint32
+	PriorityProbeGrace       time.Duration
+	LatencyQuantile          float64
+	LatencyThreshold         time.Duration
+	ReservationTTL           time.Duration
+	IgnoreCanceled           bool
+	EligibleProbesOnly       bool
+	NilResult                NilResultPolicy
+	FallbackOnFailure        bool
+	ManualRecoveryOnly       bool
+	FlappingWindow           time.Duration
+	FlappingThreshold        int
+	ForceTimeout             bool
+	CloseSuccesses           uint32
+	CloseWindow              uint32
+	MaxBackoff               time.Duration
+	AverageWindow            int
+	AverageThreshold         time.Duration
+	CancelInflightOnTrip     bool
+	WeightedThreshold        uint32
+	PersistentWeight         uint32
+	TimeoutJitter            float64
+	SuccessShards            int
+	InitialProbe             bool
+	DryRun                   bool
+	MinIntervalSuccessRatio  float64
+	MinIntervalRequests      uint32
+	AsyncHooks               int
+	MinBudget                time.Duration
+	//打开条件: "default"、WithTripPolicy注册的名字和参数、"threshold"，或者"custom"
+	TripPolicy string
+	//当前的打开阈值，TripPolicy 是"threshold"时才有意义
+	TripThreshold TripThreshold
+}
+
+//Config return the resolved configuration of rb
+func (rb *RequestBreaker) Config() Config {
+	rb.lazyInit()
+	o := rb.options
+
+	c := Config{
+		Name:                     o.Name,
+		Interval:                 o.Interval,
+		Timeout:                  o.Timeout,
+		MaxRequests:              o.MaxRequests,
+		ShoulderHalfToOpen:       o.ShoulderHalfToOpen,
+		CountProbes:              o.CountProbes,
+		MaxInFlight:              o.MaxInFlight,
+		IntervalJitter:           o.IntervalJitter,
+		HealthAlpha:              o.HealthAlpha,
+		LatencyTarget:            o.LatencyTarget,
+		ChaosRate:                o.ChaosRate,
+		Warmup:                   o.Warmup,
+		RequestTimeout:           o.RequestTimeout,
+		HistorySize:              o.HistorySize,
+		MinErrorGroups:           o.MinErrorGroups,
+		HalfOpenFailureTolerance: o.HalfOpenFailureTolerance,
+		PriorityProbeGrace:       o.PriorityProbeGrace,
+		LatencyQuantile:          o.LatencyQuantile,
+		LatencyThreshold:         o.LatencyThreshold,
+		ReservationTTL:           o.ReservationTTL,
+		IgnoreCanceled:           o.IgnoreCanceled,
+		EligibleProbesOnly:       o.EligibleProbesOnly,
+		NilResult:                o.NilResult,
+		FallbackOnFailure:        o.FallbackOnFailure,
+		ManualRecoveryOnly:       o.ManualRecoveryOnly,
+		FlappingWindow:           o.FlappingWindow,
+		FlappingThreshold:        o.FlappingThreshold,
+		ForceTimeout:             o.ForceTimeout,
+		CloseSuccesses:           o.CloseSuccesses,
+		CloseWindow:              o.CloseWindow,
+		MaxBackoff:               o.MaxBackoff,
+		AverageWindow:            o.AverageWindow,
+		AverageThreshold:         o.AverageThreshold,
+		CancelInflightOnTrip:     o.CancelInflightOnTrip,
+		WeightedThreshold:        o.WeightedThreshold,
+		PersistentWeight:         o.PersistentWeight,
+		TimeoutJitter:            o.TimeoutJitter,
+		SuccessShards:            o.SuccessShards,
+		InitialProbe:             o.InitialProbe,
+		DryRun:                   o.DryRun,
+		MinIntervalSuccessRatio:  o.MinIntervalSuccessRatio,
+		MinIntervalRequests:      o.MinIntervalRequests,
+		AsyncHooks:               o.AsyncHooks,
+		MinBudget:                o.MinBudget,
+		TripPolicy:               o.TripPolicy,
+	}
+	if o.AdaptiveTimeout != nil {
+		c.AdaptiveTimeout = *o.AdaptiveTimeout
+	}
+	if threshold, ok := rb.Threshold(); ok {
+		c.TripPolicy, c.TripThreshold = thresholdTripPolicy, threshold
+	}
+	//CanOpenFor 和 CanOpenContext 优先于阈值和 CanOpen
+	if c.TripPolicy == "" || o.CanOpenFor != nil || o.CanOpenContext != nil {
+		c.TripPolicy = customTripPolicy
+	}
+	return c
+}
+
+//DiffConfig report every field in which a and b differ, such as "Timeout: 1m0s != 30s", in field order
+func DiffConfig(a, b Config) []string {
+	var diffs []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		fa, fb := va.Field(i).Interface(), vb.Field(i).Interface()
+		if fa != fb {
+			diffs = append(diffs, fmt.Sprintf("%s: %v != %v", va.Type().Field(i).Name, fa, fb))
+		}
+	}
+	return diffs
+}
@@ -0,0 +1,58 @@
+package circuit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDiffConfig(t *testing.T) {
+
+	a := NewRequestBreaker(ActionName("orders"))
+	b := NewRequestBreaker(ActionName("orders"), Timeout(30*time.Second), MaxRequests(1))
+
+	if diffs := DiffConfig(a.Config(), a.Clone().Config()); len(diffs) != 0 {
+		t.Errorf("a clone should have the same config, got %v", diffs)
+	}
+
+	want := []string{"Timeout: 1m0s != 30s", "MaxRequests: 5 != 1"}
+	if diffs := DiffConfig(a.Config(), b.Config()); fmt.Sprint(diffs) != fmt.Sprint(want) {
+		t.Errorf("diffs:\n got %q\nwant %q", diffs, want)
+	}
+}
+
+func TestConfigTripPolicy(t *testing.T) {
+
+	ratio, err := WithTripPolicy("ratio", map[string]interface{}{"ratio": 0.5, "minRequests": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	budget, err := WithTripPolicy("budget", map[string]interface{}{"failures": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "default"},
+		{[]Option{ratio}, "ratio(minRequests=4, ratio=0.5)"},
+		{[]Option{WithBreakCondition(func(state State, cnt Counts) bool { return false })}, "custom"},
+		{[]Option{ratio, WithReadyToTrip(func(state State, cnt Counts) (bool, time.Duration) { return false, 0 })}, "custom"},
+	}
+	for _, c := range cases {
+		if got := NewRequestBreaker(c.opts...).Config().TripPolicy; got != c.want {
+			t.Errorf("expected trip policy %s, got %s", c.want, got)
+		}
+	}
+
+	diffs := DiffConfig(NewRequestBreaker(ratio).Config(), NewRequestBreaker(budget).Config())
+	if fmt.Sprint(diffs) != "[TripPolicy: ratio(minRequests=4, ratio=0.5) != budget(failures=2)]" {
+		t.Errorf("trip policies should be compared by name, got %v", diffs)
+	}
+
+	if _, err := WithTripPolicy("no-such-policy", nil); err == nil {
+		t.Error("unknown policy should fail")
+	}
+}
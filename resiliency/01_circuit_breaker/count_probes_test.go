@@ -0,0 +1,44 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func probeOnce(t *testing.T, opts ...Option) Counts {
+	t.Helper()
+
+	opts = append(opts, Timeout(5*time.Millisecond), WithShoulderHalfToOpen(2))
+	rb := NewRequestBreaker(opts...)
+	tripBreaker(t, rb)
+
+	time.Sleep(10 * time.Millisecond)
+
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+	if rb.State() != StateHalfOpen {
+		t.Fatalf("breaker should be half-open, got %s", rb.State())
+	}
+	return rb.Counts()
+}
+
+func TestProbesCountedApart(t *testing.T) {
+
+	cnt := probeOnce(t)
+
+	if cnt.ProbeSuccesses != 1 || cnt.TotalSuccesses != 0 {
+		t.Errorf("probe should land in ProbeSuccesses, got %+v", cnt)
+	}
+	if cnt.ConsecutiveSuccesses != 1 {
+		t.Errorf("probe should still count toward closing, got %+v", cnt)
+	}
+}
+
+func TestProbesFoldedIntoTotals(t *testing.T) {
+
+	cnt := probeOnce(t, WithCountProbes(true))
+
+	if cnt.ProbeSuccesses != 0 || cnt.TotalSuccesses != 1 {
+		t.Errorf("probe should land in TotalSuccesses, got %+v", cnt)
+	}
+}
@@ -0,0 +1,43 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestCounterLazyInitConcurrent(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	if rb.cnter != nil {
+		t.Fatal("no counter should be created before first use")
+	}
+
+	const callers = 64
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+		}()
+	}
+	wg.Wait()
+
+	if cnt := rb.Counts(); cnt.TotalSuccesses != callers {
+		t.Errorf("expected %d successes, got %+v", callers, cnt)
+	}
+}
+
+func TestWithCounter(t *testing.T) {
+
+	own := &Counts{}
+	rb := NewRequestBreaker(WithCounter(own))
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+
+	//Counts merges successes taken by the lock-free path into the counter
+	rb.Counts()
+	if own.TotalSuccesses != 1 {
+		t.Errorf("configured counter should be used, got %+v", own)
+	}
+}
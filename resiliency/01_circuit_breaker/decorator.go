@@ -0,0 +1,131 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:05:26
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+////////////////////////////////
+/// 装饰器模式
+/// 超时、重试、统计、断路器都是对work的装饰，可以像洋葱一样一层一层包起来
+////////////////////////////////
+
+//Work is the requested work guarded by the breaker
+type Work func(ctx context.Context) (interface{}, error)
+
+//Decorator wrap next with an extra concern
+type Decorator func(next Work) Work
+
+// Chain compose decorators into one, the first decorator is the outermost layer.
+// Chain(BreakerDecorator(rb), TimeoutDecorator(d))(work) runs the timeout inside the breaker,
+// so a timeout is counted by the breaker as a failure.
+func Chain(decorators ...Decorator) Decorator {
+	return func(next Work) Work {
+		for i := len(decorators) - 1; i >= 0; i-- {
+			next = decorators[i](next)
+		}
+		return next
+	}
+}
+
+//BreakerDecorator run next through rb
+func BreakerDecorator(rb *RequestBreaker) Decorator {
+	return func(next Work) Work {
+		return func(ctx context.Context) (interface{}, error) {
+			return rb.DoContext(ctx, next)
+		}
+	}
+}
+
+//TimeoutDecorator give next a context which is done after timeout
+func TimeoutDecorator(timeout time.Duration) Decorator {
+	return func(next Work) Work {
+		return func(ctx context.Context) (interface{}, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx)
+		}
+	}
+}
+
+//RetryDecorator run next up to attempts times until it succeeds, waiting backoff between attempts,
+//only the WithClock option applies to it
+func RetryDecorator(attempts int, backoff time.Duration, opts ...Option) Decorator {
+	clock := circuitOptions(opts).Clock
+	return func(next Work) Work {
+		return func(ctx context.Context) (interface{}, error) {
+			return retry(ctx, clock, attempts, next, func(error) time.Duration { return backoff })
+		}
+	}
+}
+
+// RetryBreakerDecorator runs next through rb up to attempts times until it succeeds.
+// A failed attempt waits backoff on the clock of rb, but an attempt rejected by the open breaker waits until
+// rb may turn half-open, see RetryAfter, so no attempt is wasted on an open circuit.
+// When rb will never turn half-open by itself, see RetryNever, retrying stops and the rejection is returned.
+func RetryBreakerDecorator(rb *RequestBreaker, attempts int, backoff time.Duration) Decorator {
+	return func(next Work) Work {
+		guarded := BreakerDecorator(rb)(next)
+		return func(ctx context.Context) (interface{}, error) {
+			return retry(ctx, rb.clock(), attempts, guarded, func(err error) time.Duration {
+				if !IsRejection(err) {
+					return backoff
+				}
+				//只能手动恢复的断路器等不到半开，RetryNever直接结束重试
+				if wait := rb.RetryAfter(); wait > 0 || wait == RetryNever {
+					return wait
+				}
+				return backoff
+			})
+		}
+	}
+}
+
+//retry run next up to attempts times until it succeeds, waiting wait(err) on clock after the failed attempts,
+//a negative wait stops retrying and returns the error
+func retry(ctx context.Context, clock Clock, attempts int, next Work, wait func(err error) time.Duration) (interface{}, error) {
+	var (
+		result interface{}
+		err    error
+	)
+	for i := 0; i < attempts; i++ {
+		if result, err = next(ctx); err == nil {
+			return result, nil
+		}
+		if i == attempts-1 {
+			break
+		}
+		d := wait(err)
+		if d < 0 {
+			break
+		}
+		select {
+		case <-clock.After(d):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return result, err
+}
+
+//MetricsDecorator report the error and latency of next to observe,
+//only the WithClock option applies to it
+func MetricsDecorator(observe func(err error, latency time.Duration), opts ...Option) Decorator {
+	clock := circuitOptions(opts).Clock
+	return func(next Work) Work {
+		return func(ctx context.Context) (interface{}, error) {
+			start := clock.Now()
+			result, err := next(ctx)
+			observe(err, elapsed(clock, start))
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestChainTimeoutInsideBreaker(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("chain"))
+
+	var observed error
+	work := Chain(
+		MetricsDecorator(func(err error, latency time.Duration) { observed = err }),
+		BreakerDecorator(rb),
+		TimeoutDecorator(5*time.Millisecond),
+	)(func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, err := work(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if observed != err {
+		t.Errorf("metrics should observe the outer error, got %v", observed)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("timeout should be counted by the breaker, got %+v", cnt)
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+
+	var order []string
+	trace := func(name string) Decorator {
+		return func(next Work) Work {
+			return func(ctx context.Context) (interface{}, error) {
+				order = append(order, "enter "+name)
+				defer func() { order = append(order, "leave "+name) }()
+				return next(ctx)
+			}
+		}
+	}
+
+	Chain(trace("outer"), trace("inner"))(func(ctx context.Context) (interface{}, error) {
+		order = append(order, "work")
+		return nil, nil
+	})(context.Background())
+
+	want := []string{"enter outer", "enter inner", "work", "leave inner", "leave outer"}
+	if len(order) != len(want) {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, order)
+		}
+	}
+}
+
+func TestRetryDecorator(t *testing.T) {
+
+	calls := 0
+	res, err := RetryDecorator(3, time.Millisecond)(func(ctx context.Context) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return nil, errBackendDown
+		}
+		return "ok", nil
+	})(context.Background())
+
+	if err != nil || res != "ok" || calls != 3 {
+		t.Errorf("expected success on the third attempt, got %v %v after %d calls", res, err, calls)
+	}
+}
+
+func TestRetryBreakerWaitsForRecovery(t *testing.T) {
+
+	const openFor = 60 * time.Millisecond
+	rb := NewRequestBreaker(Timeout(openFor))
+
+	var calls []time.Time
+	work := func(ctx context.Context) (interface{}, error) {
+		calls = append(calls, time.Now())
+		if len(calls) <= 3 {
+			return nil, errBackendDown //第3次失败打开断路器
+		}
+		return "recovered", nil
+	}
+
+	res, err := RetryBreakerDecorator(rb, 5, time.Millisecond)(work)(context.Background())
+	if err != nil || res != "recovered" {
+		t.Fatalf("retry should succeed after recovery, got %v %v", res, err)
+	}
+
+	//第4次尝试被拒绝，等到可以半开才有第5次，work只跑了4次
+	if len(calls) != 4 {
+		t.Fatalf("rejected attempt should not reach work, got %d calls", len(calls))
+	}
+	if waited := calls[3].Sub(calls[2]); waited < openFor {
+		t.Errorf("next attempt should wait for the recovery window %v, waited %v", openFor, waited)
+	}
+}
+
+func TestRetryBreakerWaitsOnWrappedRejection(t *testing.T) {
+
+	const openFor = 60 * time.Millisecond
+	rb := NewRequestBreaker(Timeout(openFor), WithFallbackChain(func(err error) (interface{}, error) {
+		return nil, fmt.Errorf("calling orders: %w", err)
+	}))
+
+	var calls []time.Time
+	work := func(ctx context.Context) (interface{}, error) {
+		calls = append(calls, time.Now())
+		if len(calls) <= 3 {
+			return nil, errBackendDown
+		}
+		return "recovered", nil
+	}
+
+	//降级把拒绝包了一层，仍然要等到可以半开
+	res, err := RetryBreakerDecorator(rb, 5, time.Millisecond)(work)(context.Background())
+	if err != nil || res != "recovered" {
+		t.Fatalf("retry should succeed after recovery, got %v %v", res, err)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("rejected attempt should not reach work, got %d calls", len(calls))
+	}
+	if waited := calls[3].Sub(calls[2]); waited < openFor {
+		t.Errorf("a wrapped rejection should wait for the recovery window %v, waited %v", openFor, waited)
+	}
+}
+
+func TestRetryBreakerStopsOnManualRecovery(t *testing.T) {
+
+	rb := NewRequestBreaker(WithManualRecoveryOnly(true))
+	tripBreaker(t, rb)
+
+	calls := 0
+	done := make(chan error, 1)
+	go func() {
+		_, err := RetryBreakerDecorator(rb, 5, time.Hour)(func(ctx context.Context) (interface{}, error) {
+			calls++
+			return "ok", nil
+		})(context.Background())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrServiceUnavailable) || calls != 0 {
+			t.Errorf("expected the rejection without running work, got %v after %d calls", err, calls)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("retry should stop at once when the breaker only recovers by Reset")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Minute))
+	if rb.RetryAfter() != 0 {
+		t.Errorf("closed breaker should not ask to wait, got %v", rb.RetryAfter())
+	}
+
+	tripBreaker(t, rb)
+	clock.Advance(20 * time.Second)
+	if got := rb.RetryAfter(); got != 40*time.Second {
+		t.Errorf("expected 40s until half-open, got %v", got)
+	}
+}
+
+func TestRetryAfterCountsDownToProbe(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), WithAdaptiveTimeout(time.Second, time.Minute, 2),
+		WithTimeoutJitter(0.2), WithJitterRand(rand.New(rand.NewSource(7))),
+		WithBreakCondition(func(state State, cnt Counts) bool {
+			return state == StateHalfOpen || cnt.ConsecutiveFailures > 2
+		}))
+
+	//第二次打开，退避到2s，再加上抖动
+	tripBreaker(t, rb)
+	clock.Advance(rb.RetryAfter() + time.Nanosecond)
+	if _, err := rb.Do(failWork); err != errBackendDown || rb.State() != StateOpen {
+		t.Fatalf("failed probe should reopen the breaker, got %v %s", err, rb.State())
+	}
+
+	wait := rb.RetryAfter()
+	if wait < 1600*time.Millisecond || wait > 2400*time.Millisecond || wait == 2*time.Second {
+		t.Fatalf("RetryAfter should be the jittered adaptive timeout, got %v", wait)
+	}
+
+	for step := wait / 4; ; {
+		clock.Advance(step)
+		got := rb.RetryAfter()
+		if got >= wait {
+			t.Fatalf("RetryAfter should shrink as the clock advances, %v then %v", wait, got)
+		}
+		if got == 0 {
+			break
+		}
+		wait = got
+	}
+	if rb.State() != StateOpen {
+		t.Fatalf("breaker should still be open when RetryAfter reaches zero, got %s", rb.State())
+	}
+
+	clock.Advance(time.Nanosecond)
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Errorf("probe should be admitted once RetryAfter is zero, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:34:34
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:34:34
+ */
+
+package circuit
+
+import (
+	"context"
+	"sync"
+)
+
+// DoBatchReduce runs works concurrently as one request of the breaker, admitted once, and folds their
+// results with reduce. results[i] and errs[i] are what works[i] returned, reduce decides what the partial
+// failures mean: its error is the one outcome the breaker records and the one returned to the caller.
+// A panic in one of the works is raised again in the caller once all of them have returned.
+func (rb *RequestBreaker) DoBatchReduce(works []func() (interface{}, error),
+	reduce func(results []interface{}, errs []error) (interface{}, error)) (interface{}, error) {
+
+	if reduce == nil {
+		return nil, ErrNilWork
+	}
+	for _, work := range works {
+		if work == nil {
+			return nil, ErrNilWork
+		}
+	}
+
+	return rb.Do(func(ctx context.Context) (interface{}, error) {
+		results, errs := make([]interface{}, len(works)), make([]error, len(works))
+		panics := make([]interface{}, len(works))
+
+		var wg sync.WaitGroup
+		for i, work := range works {
+			wg.Add(1)
+			go func(i int, work func() (interface{}, error)) {
+				defer wg.Done()
+				defer func() { panics[i] = recover() }()
+				results[i], errs[i] = work()
+			}(i, work)
+		}
+		wg.Wait()
+
+		//和 Do 一样，work 的 panic 交给调用方
+		for _, p := range panics {
+			if p != nil {
+				panic(p)
+			}
+		}
+		return reduce(results, errs)
+	})
+}
@@ -0,0 +1,57 @@
+package circuit
+
+import (
+	"errors"
+	"testing"
+)
+
+//tolerateOne succeed with the sum of the results as long as at most one work failed
+func tolerateOne(results []interface{}, errs []error) (interface{}, error) {
+	sum, failed := 0, 0
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			continue
+		}
+		sum += results[i].(int)
+	}
+	if failed > 1 {
+		return nil, errors.Join(errs...)
+	}
+	return sum, nil
+}
+
+func TestDoBatchReduceToleratesOneFailure(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	value := func(v int) func() (interface{}, error) {
+		return func() (interface{}, error) { return v, nil }
+	}
+	failing := func() (interface{}, error) { return nil, errBackendDown }
+
+	result, err := rb.DoBatchReduce([]func() (interface{}, error){value(1), failing, value(2)}, tolerateOne)
+	if err != nil || result != 3 {
+		t.Fatalf("reducer should tolerate one failure, got %v %v", result, err)
+	}
+	if cnt := rb.Counts(); cnt.Requests != 1 || cnt.TotalSuccesses != 1 {
+		t.Errorf("the batch should be counted as one success, got %+v", cnt)
+	}
+
+	_, err = rb.DoBatchReduce([]func() (interface{}, error){failing, failing, value(2)}, tolerateOne)
+	if !errors.Is(err, errBackendDown) {
+		t.Fatalf("expected the reducer's error, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.Requests != 2 || cnt.TotalFailures != 1 {
+		t.Errorf("the batch should be counted as one failure, got %+v", cnt)
+	}
+
+	tripBreaker(t, rb)
+	ran := false
+	_, err = rb.DoBatchReduce([]func() (interface{}, error){func() (interface{}, error) {
+		ran = true
+		return nil, nil
+	}}, tolerateOne)
+	if err != ErrServiceUnavailable || ran {
+		t.Errorf("an open breaker should reject the whole batch, got %v", err)
+	}
+}
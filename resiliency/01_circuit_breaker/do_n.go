@@ -0,0 +1,38 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:07:21
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:19:16
+ */
+
+package circuit
+
+import "context"
+
+// DoN runs idempotent work through the breaker up to n times and returns the first success.
+// It stops as soon as the breaker rejects an attempt, such as when a failed attempt has just
+// opened it, and returns the rejection, ErrServiceUnavailable for an open breaker,
+// without running work again. Otherwise the error of the last attempt is returned. n < 1 is treated as 1.
+// The fallback chain handles the final error just like Do.
+func (rb *RequestBreaker) DoN(n int, work func() (interface{}, error)) (interface{}, error) {
+	if work == nil {
+		return nil, ErrNilWork
+	}
+	if n < 1 {
+		n = 1
+	}
+
+	var (
+		result interface{}
+		err    error
+	)
+	for i := 0; i < n; i++ {
+		var admitted bool
+		admitted, result, err = rb.TryDo(func(ctx context.Context) (interface{}, error) { return work() })
+		if err == nil || !admitted {
+			return rb.withFallback(admitted, result, err)
+		}
+	}
+	return rb.withFallback(true, result, err)
+}
@@ -0,0 +1,42 @@
+package circuit
+
+import "testing"
+
+func TestDoNStopsWhenBreakerOpens(t *testing.T) {
+
+	//连续失败2次就打开
+	rb := NewRequestBreaker(WithBreakCondition(func(state State, cnt Counts) bool { return cnt.ConsecutiveFailures > 1 }))
+
+	attempts := 0
+	_, err := rb.DoN(5, func() (interface{}, error) {
+		attempts++
+		return nil, errBackendDown
+	})
+	if err != ErrServiceUnavailable {
+		t.Errorf("expected the rejection of the open breaker, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("breaker opened on the second attempt, the third should not run, got %d attempts", attempts)
+	}
+}
+
+func TestDoNReturnsFirstSuccess(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	attempts := 0
+	res, err := rb.DoN(3, func() (interface{}, error) {
+		if attempts++; attempts < 2 {
+			return nil, errBackendDown
+		}
+		return "ok", nil
+	})
+	if err != nil || res != "ok" || attempts != 2 {
+		t.Errorf("expected success on the second attempt, got %v %v after %d", res, err, attempts)
+	}
+
+	attempts = 0
+	if _, err := rb.DoN(2, func() (interface{}, error) { attempts++; return nil, errBackendDown }); err != errBackendDown || attempts != 2 {
+		t.Errorf("expected the last error after 2 attempts, got %v after %d", err, attempts)
+	}
+}
@@ -0,0 +1,164 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDoContextCountsSwallowedDeadline(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("deadline"))
+
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	_, err := rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+		//work ignores the deadline
+		return nil, nil
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 || cnt.TotalSuccesses != 0 {
+		t.Errorf("expected one failure recorded, got %+v", cnt)
+	}
+}
+
+func TestDoContextPassesContext(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "v")
+
+	res, err := rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+		return ctx.Value(key{}), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != "v" {
+		t.Errorf("work should see the caller context, got %v", res)
+	}
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 1 {
+		t.Errorf("expected one success recorded, got %+v", cnt)
+	}
+}
+
+func TestDoContextKeepsValuesUnderTimeout(t *testing.T) {
+
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "v"))
+	defer cancel()
+
+	check := func(ctx context.Context) (interface{}, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("work should see the timeout")
+		}
+		return ctx.Value(key{}), nil
+	}
+
+	rb := NewRequestBreaker()
+	work := Chain(BreakerDecorator(rb), TimeoutDecorator(time.Second))(check)
+	if res, err := work(parent); err != nil || res != "v" {
+		t.Errorf("value should survive the timeout decorator, got %v %v", res, err)
+	}
+
+	//每一级的断路器拿到的也是调用方的ctx
+	p := NewPipeline().AddStage("timed", NewRequestBreaker(), func(ctx context.Context, in interface{}) (interface{}, error) {
+		return check(ctx)
+	})
+	timed, cancelTimed := context.WithTimeout(parent, time.Second)
+	defer cancelTimed()
+	if res, err := p.Run(timed, nil); err != nil || res != "v" {
+		t.Errorf("value should reach the stage, got %v %v", res, err)
+	}
+
+	//the parent cancellation reaches the breaker, which counts it
+	stage := NewRequestBreaker(WithIgnoreContextCanceled(false))
+	canceled, cancelNow := context.WithCancel(parent)
+	cancelNow()
+	NewPipeline().AddStage("canceled", stage, func(ctx context.Context, in interface{}) (interface{}, error) {
+		return nil, nil
+	}).Run(canceled, nil)
+	if cnt := stage.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("canceled stage should be counted as a failure, got %+v", cnt)
+	}
+}
+
+func TestDoContextSaturatedHalfOpenRejectsAtOnce(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(1))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	//占住唯一的试探名额
+	release := make(chan struct{})
+	probing := make(chan struct{})
+	go rb.Do(func(ctx context.Context) (interface{}, error) {
+		close(probing)
+		<-release
+		return nil, nil
+	})
+	<-probing
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+		t.Error("work should not run while the probe slot is taken")
+		return nil, nil
+	})
+	if err != ErrTooManyRequests {
+		t.Errorf("expected ErrTooManyRequests, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("rejection should not wait for the deadline, took %v", elapsed)
+	}
+}
+
+func TestRequestTimeoutDefault(t *testing.T) {
+
+	rb := NewRequestBreaker(WithRequestTimeout(20 * time.Millisecond))
+
+	start := time.Now()
+	_, err := rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("breaker default timeout should apply, took %v", elapsed)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("timeout should be counted as a failure, got %+v", cnt)
+	}
+}
+
+func TestRequestTimeoutShorterCallerDeadlineWins(t *testing.T) {
+
+	rb := NewRequestBreaker(WithRequestTimeout(time.Hour))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	want, _ := ctx.Deadline()
+
+	_, err := rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+		if got, _ := ctx.Deadline(); !got.Equal(want) {
+			t.Errorf("caller deadline should win, got %v want %v", got, want)
+		}
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:33:46
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:24:12
+ */
+
+package circuit
+
+// WithDryRun run the state machine without enforcing it, to tune the thresholds on real traffic.
+// State, Counts and the transitions are those of an enforcing breaker: a request it would reject is counted
+// and logged as rejected, then runs anyway, and its outcome is not recorded since it would never have run.
+// Transitions reach OnStateChanged and the observers with StateChange.DryRun set, but nothing acts on them:
+// OnReject is not called, in-flight requests are not canceled on a trip, a Mediator ignores the trip,
+// AllowRequest and RetryAfter always let the caller go on, a VotingBreaker and a Mediator count it as closed
+// and HealthHandler reports it healthy.
+func WithDryRun(dryRun bool) Option {
+	return func(opts *Options) {
+		opts.DryRun = dryRun
+	}
+}
+
+//enforced return the state rb acts on, a dry-run breaker admits every request as if closed
+func (rb *RequestBreaker) enforced() State {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	if rb.options.DryRun {
+		return StateClosed
+	}
+	return rb.state
+}
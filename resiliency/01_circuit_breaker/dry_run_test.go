@@ -0,0 +1,51 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDryRunTripsButAdmits(t *testing.T) {
+
+	clock := newFakeClock()
+	var changes []StateChange
+	rejected := 0
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Minute), WithDryRun(true),
+		WithOnReject(func(name string, state State, err error) { rejected++ }))
+	rb.Events().Subscribe(ObserverFunc[StateChange](func(change StateChange) { changes = append(changes, change) }))
+
+	tripBreaker(t, rb)
+	if len(changes) != 1 || changes[0].To != StateOpen || !changes[0].DryRun {
+		t.Fatalf("expected a dry-run trip, got %+v", changes)
+	}
+
+	//影子状态是打开的，请求仍然执行
+	ran := 0
+	for i := 0; i < 3; i++ {
+		if _, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+			ran++
+			return nil, errBackendDown
+		}); err != errBackendDown {
+			t.Errorf("dry run should admit and run work, got %v", err)
+		}
+	}
+	if ran != 3 || rb.State() != StateOpen || !rb.AllowRequest() || rb.RetryAfter() != 0 {
+		t.Errorf("expected work to run with the shadow state open, ran %d, %s", ran, rb.State())
+	}
+
+	cnt := rb.Counts()
+	if cnt.RejectedOpen != 3 || cnt.Requests != 0 {
+		t.Errorf("would-be rejections should be counted, not their outcomes, got %+v", cnt)
+	}
+	if rejected != 0 {
+		t.Errorf("OnReject should not be called in dry run, got %d", rejected)
+	}
+
+	//影子状态照常恢复
+	clock.Advance(time.Minute + time.Nanosecond)
+	rb.Do(succeedWork)
+	if rb.State() != StateClosed || len(changes) != 3 {
+		t.Errorf("the shadow breaker should recover, got %s after %d changes", rb.State(), len(changes))
+	}
+}
@@ -0,0 +1,62 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:20:27
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:05:18
+ */
+
+package circuit
+
+////////////////////////////////
+/// 按错误分组统计失败
+/// 同一种无害的错误重复出现不应该打开断路器，多种错误一起出现才像真正的故障
+////////////////////////////////
+
+//WithErrorGrouping count the failures of current generation by the group group(err) returns,
+//it is called with the mutex held, a failure whose group panics is not counted in any group
+func WithErrorGrouping(group func(error) string) Option {
+	return func(opts *Options) {
+		opts.ErrorGroup = group
+	}
+}
+
+// TripOnDistinctErrorGroups let the breaker trip only when failures of at least n distinct
+// error groups are counted in current generation, on top of the normal break condition.
+// Errors are grouped by their message unless WithErrorGrouping is set. Messages which carry
+// a request id, an address or a time make a group each, the breaker then trips as if every failure
+// were distinct and keeps one group per message until the generation ends, set WithErrorGrouping for them.
+func TripOnDistinctErrorGroups(n uint32) Option {
+	return func(opts *Options) {
+		opts.MinErrorGroups = n
+	}
+}
+
+//ErrorGroups return the failures of current generation by error group
+func (rb *RequestBreaker) ErrorGroups() map[string]uint32 {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	groups := make(map[string]uint32, len(rb.errorGroups))
+	for group, n := range rb.errorGroups {
+		groups[group] = n
+	}
+	return groups
+}
+
+//countErrorGroup count err in its group, must be called with the mutex held
+func (rb *RequestBreaker) countErrorGroup(err error) {
+	group := rb.options.ErrorGroup
+	if group == nil {
+		if rb.options.MinErrorGroups == 0 {
+			return
+		}
+		group = func(err error) string { return err.Error() }
+	}
+
+	if rb.errorGroups == nil {
+		rb.errorGroups = make(map[string]uint32)
+	}
+	//分组是用户的回调，panic 不能让锁一直被持有
+	rb.guard("ErrorGroup", func() { rb.errorGroups[group(err)]++ })
+}
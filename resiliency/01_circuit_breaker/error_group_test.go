@@ -0,0 +1,72 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+)
+
+type statusError struct{ code int }
+
+func (e statusError) Error() string { return fmt.Sprintf("status %d", e.code) }
+
+func TestTripOnDistinctErrorGroups(t *testing.T) {
+
+	byClass := func(err error) string {
+		var se statusError
+		if errors.As(err, &se) {
+			return fmt.Sprintf("%dxx", se.code/100)
+		}
+		return "other"
+	}
+	rb := NewRequestBreaker(WithErrorGrouping(byClass), TripOnDistinctErrorGroups(3))
+
+	failWith := func(err error) {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, err })
+	}
+
+	//同一组的错误再多也不打开
+	for i := 0; i < 20; i++ {
+		failWith(statusError{code: 400 + i%5})
+	}
+	if rb.State() != StateClosed {
+		t.Fatalf("one error group should not trip, got %s", rb.State())
+	}
+	if groups := rb.ErrorGroups(); groups["4xx"] != 20 || len(groups) != 1 {
+		t.Errorf("unexpected groups %v", groups)
+	}
+
+	failWith(statusError{code: 503})
+	if rb.State() != StateClosed {
+		t.Fatalf("two error groups should not trip, got %s", rb.State())
+	}
+	failWith(errBackendDown)
+	if rb.State() != StateOpen {
+		t.Errorf("three error groups should trip, got %s", rb.State())
+	}
+	if len(rb.ErrorGroups()) != 0 {
+		t.Error("groups belong to the generation and are reset on trip")
+	}
+}
+
+func TestPanickingErrorGroup(t *testing.T) {
+
+	h := &captureHandler{}
+	rb := NewRequestBreaker(WithLogger(slog.New(h)), TripOnDistinctErrorGroups(1),
+		WithErrorGrouping(func(error) string { panic("bad grouping") }))
+
+	for i := 0; i < 3; i++ {
+		if _, err := rb.Do(failWork); err != errBackendDown {
+			t.Fatalf("Do should return the work error, got %v", err)
+		}
+	}
+	//没有分组被记下，不会打开，锁也已经释放
+	if rb.State() != StateClosed || len(rb.ErrorGroups()) != 0 {
+		t.Errorf("a failure whose group panics should not be counted, got %s %v", rb.State(), rb.ErrorGroups())
+	}
+	if len(h.records) != 3 || recordAttrs(h.records[0])["callback"].String() != "ErrorGroup" {
+		t.Errorf("each panic should be logged as ErrorGroup, got %d records", len(h.records))
+	}
+}
@@ -0,0 +1,57 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:48:14
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package circuit
+
+import "errors"
+
+////////////////////////////////
+/// 错误的分类
+/// 调用方最常问的是：这个错误是断路器拒绝的，还是work自己失败的
+/// 断路器产生的错误都能 errors.Is 到 ErrBreaker，其中没有执行work的拒绝还能 errors.Is 到 ErrRejected
+////////////////////////////////
+
+var (
+	//ErrBreaker is matched by errors.Is for every error made by the breaker rather than by work
+	ErrBreaker = errors.New("circuit breaker error")
+	//ErrRejected is matched by errors.Is for every rejection, the work of a rejected request never ran
+	ErrRejected error = &breakerError{text: "request rejected", kind: ErrBreaker}
+)
+
+//breakerError is a sentinel of the breaker, it unwraps to its kind
+type breakerError struct {
+	text string
+	kind error
+}
+
+func (e *breakerError) Error() string { return e.text }
+
+func (e *breakerError) Unwrap() error { return e.kind }
+
+//newBreakerError return a sentinel matching ErrBreaker
+func newBreakerError(text string) error {
+	return &breakerError{text: text, kind: ErrBreaker}
+}
+
+//NewRejectionError return a sentinel matching ErrRejected, for the rejections of a wrapper such as a bulkhead
+func NewRejectionError(text string) error {
+	return &breakerError{text: text, kind: ErrRejected}
+}
+
+// IsRejection report whether err is or wraps a rejection, such as ErrServiceUnavailable or ErrTooManyRequests,
+// so the work never ran and err says nothing about the backend.
+// A breaker out of the scope of NewRequestBreakerWithContext rejects with the error of the scope instead.
+func IsRejection(err error) bool {
+	return errors.Is(err, ErrRejected)
+}
+
+//IsBreakerError report whether err is or wraps an error made by the breaker, rejections included,
+//such as ErrTimeout of force timeout or ErrChaosInjected
+func IsBreakerError(err error) bool {
+	return errors.Is(err, ErrBreaker)
+}
@@ -0,0 +1,56 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsRejection(t *testing.T) {
+
+	rejections := []error{ErrTooManyRequests, ErrServiceUnavailable, ErrLoadShed, ErrExecutorFull, ErrPoolClosed, ErrRejected,
+		&OpenCircuitError{State: StateOpen, clock: systemClock{}}}
+	for _, err := range rejections {
+		if !IsRejection(err) || !IsRejection(fmt.Errorf("calling orders: %w", err)) {
+			t.Errorf("%v should be a rejection, wrapped or not", err)
+		}
+		if !IsBreakerError(err) {
+			t.Errorf("%v should be a breaker error", err)
+		}
+	}
+
+	others := []error{ErrNilWork, ErrChaosInjected, ErrTimeout, ErrTripped, ErrTokenExpired}
+	for _, err := range others {
+		if IsRejection(err) {
+			t.Errorf("%v is not a rejection, the work ran or it is a misuse", err)
+		}
+		if !IsBreakerError(err) {
+			t.Errorf("%v should be a breaker error", err)
+		}
+	}
+
+	for _, err := range []error{nil, errBackendDown, context.Canceled, context.DeadlineExceeded, errors.New("too many requests")} {
+		if IsRejection(err) || IsBreakerError(err) {
+			t.Errorf("an error of work %v should be neither", err)
+		}
+	}
+
+	if ErrServiceUnavailable.Error() != "service unavailable" {
+		t.Errorf("the text of a sentinel should not change, got %q", ErrServiceUnavailable)
+	}
+}
+
+func TestRejectionOfOpenBreaker(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	tripBreaker(t, rb)
+
+	_, err := rb.Do(succeedWork)
+	if !IsRejection(err) {
+		t.Errorf("an open breaker should reject, got %v", err)
+	}
+	if _, err := NewRequestBreaker().Do(failWork); IsRejection(err) || IsBreakerError(err) {
+		t.Errorf("an error of work should pass through as is, got %v", err)
+	}
+}
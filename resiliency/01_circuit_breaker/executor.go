@@ -0,0 +1,159 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:38:58
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package circuit
+
+import (
+	"context"
+	"sync"
+)
+
+////////////////////////////////
+/// 任务窃取的执行器 + 每个目标一个断路器
+/// 每个worker有自己的有界队列，空闲的worker从别的队列尾部偷任务
+/// 提交时先问目标的断路器，打开就直接拒绝，注定失败的任务不进队列
+////////////////////////////////
+
+//ErrExecutorFull is returned by Submit when every queue is full
+var ErrExecutorFull = NewRejectionError("executor queues full")
+
+//AllowRequest report whether rb would admit a request now, without taking a probe slot,
+//it is false only while rb is open and may not try to recover yet, never WithDryRun
+func (rb *RequestBreaker) AllowRequest() bool {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.state != StateOpen || rb.options.DryRun || rb.canRecover(rb.now())
+}
+
+type execTask struct {
+	rb     *RequestBreaker
+	fn     func() error
+	result chan error
+}
+
+type workQueue struct {
+	mutex sync.Mutex
+	tasks []execTask
+}
+
+//Executor run tasks for many breakers on a fixed set of workers with bounded, stealable queues
+type Executor struct {
+	queues   []*workQueue
+	capacity int           //每个队列最多的任务数
+	pending  chan struct{} //每个排队的任务一个令牌
+	mutex    sync.Mutex
+	closed   bool
+	next     int //下一次提交先试的队列
+	workers  sync.WaitGroup
+}
+
+//NewExecutor start workers workers, each with a queue of at most capacity tasks
+func NewExecutor(workers, capacity int) *Executor {
+
+	e := &Executor{
+		queues:   make([]*workQueue, workers),
+		capacity: capacity,
+		pending:  make(chan struct{}, workers*capacity),
+	}
+
+	e.workers.Add(workers)
+	for i := range e.queues {
+		e.queues[i] = &workQueue{}
+		go e.worker(i)
+	}
+
+	return e
+}
+
+// Submit queues task to run through rb and returns a channel receiving its result.
+// It fails at once with ErrServiceUnavailable when rb is open, so doomed tasks never build up,
+// with ErrExecutorFull when every queue is full, or with ErrPoolClosed after Shutdown.
+func (e *Executor) Submit(rb *RequestBreaker, task func() error) (<-chan error, error) {
+
+	if !rb.AllowRequest() {
+		return nil, ErrServiceUnavailable
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.closed {
+		return nil, ErrPoolClosed
+	}
+
+	result := make(chan error, 1)
+	for i := range e.queues {
+		q := e.queues[(e.next+i)%len(e.queues)]
+		q.mutex.Lock()
+		if len(q.tasks) < e.capacity {
+			q.tasks = append(q.tasks, execTask{rb: rb, fn: task, result: result})
+			q.mutex.Unlock()
+			e.next = (e.next + i + 1) % len(e.queues)
+			e.pending <- struct{}{}
+			return result, nil
+		}
+		q.mutex.Unlock()
+	}
+
+	return nil, ErrExecutorFull
+}
+
+//Pending return the number of queued tasks
+func (e *Executor) Pending() int {
+	return len(e.pending)
+}
+
+//Shutdown stop accepting tasks and wait for queued tasks to finish
+func (e *Executor) Shutdown() {
+
+	e.mutex.Lock()
+	if e.closed {
+		e.mutex.Unlock()
+		return
+	}
+	e.closed = true
+	close(e.pending)
+	e.mutex.Unlock()
+
+	e.workers.Wait()
+}
+
+func (e *Executor) worker(own int) {
+	defer e.workers.Done()
+
+	//拿到令牌就一定有一个任务在某个队列里
+	for range e.pending {
+		task := e.take(own)
+		_, err := task.rb.Do(func(ctx context.Context) (interface{}, error) {
+			return nil, task.fn()
+		})
+		task.result <- err
+	}
+}
+
+//take pop the head of own queue, or steal the tail of another queue when own is empty
+func (e *Executor) take(own int) execTask {
+	for {
+		for i := range e.queues {
+			q := e.queues[(own+i)%len(e.queues)]
+			q.mutex.Lock()
+			if n := len(q.tasks); n > 0 {
+				var task execTask
+				if i == 0 {
+					task, q.tasks = q.tasks[0], q.tasks[1:]
+				} else {
+					task, q.tasks = q.tasks[n-1], q.tasks[:n-1]
+				}
+				q.mutex.Unlock()
+				return task
+			}
+			q.mutex.Unlock()
+		}
+	}
+}
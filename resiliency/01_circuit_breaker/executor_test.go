@@ -0,0 +1,78 @@
+package circuit
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestExecutorRejectsAtSubmitWhenOpen(t *testing.T) {
+
+	e := NewExecutor(2, 4)
+	defer e.Shutdown()
+
+	rb := NewRequestBreaker()
+	tripBreaker(t, rb)
+
+	var ran int32
+	for i := 0; i < 1000; i++ {
+		_, err := e.Submit(rb, func() error {
+			atomic.AddInt32(&ran, 1)
+			return nil
+		})
+		if err != ErrServiceUnavailable {
+			t.Fatalf("submit %d: expected ErrServiceUnavailable, got %v", i, err)
+		}
+	}
+	if e.Pending() != 0 || atomic.LoadInt32(&ran) != 0 {
+		t.Errorf("doomed tasks should never be queued, pending %d ran %d", e.Pending(), ran)
+	}
+}
+
+func TestExecutorRunsAndBounds(t *testing.T) {
+
+	e := NewExecutor(2, 1)
+
+	//两个worker各卡住一个任务，两个队列各排一个
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+	rb := NewRequestBreaker()
+	results := make([]<-chan error, 0, 4)
+	for i := 0; i < 2; i++ {
+		result, err := e.Submit(rb, func() error {
+			started <- struct{}{}
+			<-release
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+	<-started
+	<-started
+	for i := 0; i < 2; i++ {
+		result, err := e.Submit(rb, func() error { return errBackendDown })
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+
+	if _, err := e.Submit(rb, func() error { return nil }); err != ErrExecutorFull {
+		t.Errorf("expected ErrExecutorFull, got %v", err)
+	}
+
+	close(release)
+	for i, result := range results {
+		err := <-result
+		if want := i >= 2; (err != nil) != want || (want && !errors.Is(err, errBackendDown)) {
+			t.Errorf("task %d: unexpected result %v", i, err)
+		}
+	}
+
+	e.Shutdown()
+	if _, err := e.Submit(rb, func() error { return nil }); err != ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
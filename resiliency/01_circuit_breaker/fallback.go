@@ -0,0 +1,57 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:44:33
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:19:16
+ */
+
+package circuit
+
+////////////////////////////////
+/// 职责链模式
+/// 一层一层降级：本地缓存、备用服务、默认值……
+/// 每个降级处理者要么给出结果，要么把请求交给下一个
+////////////////////////////////
+
+//Fallback handle the error of a rejected or failed request, a nil error means it handled the request
+type Fallback func(err error) (interface{}, error)
+
+// WithFallbackChain makes Do and DoContext try fallbacks in order when the breaker rejects a request,
+// until one of them returns a nil error, the fallbacks after it are not called.
+// Every fallback gets the original error, if all of them fail, the error of the last one is returned.
+// The fallbacks run after the outcome is counted, they never affect the breaker.
+// TryDo does not run them, it reports the real rejection.
+func WithFallbackChain(fallbacks ...Fallback) Option {
+	return func(opts *Options) {
+		opts.Fallbacks = fallbacks
+	}
+}
+
+//WithFallbackOnFailure also run the fallback chain when the work of an admitted request fails
+func WithFallbackOnFailure(onFailure bool) Option {
+	return func(opts *Options) {
+		opts.FallbackOnFailure = onFailure
+	}
+}
+
+//withFallback run the fallback chain on the outcome of a request if it has to
+func (rb *RequestBreaker) withFallback(admitted bool, result interface{}, err error) (interface{}, error) {
+	if err != nil && len(rb.options.Fallbacks) > 0 && (!admitted || rb.options.FallbackOnFailure) {
+		return rb.fallback(err)
+	}
+	return result, err
+}
+
+//fallback pass err along the chain until a fallback handles it
+func (rb *RequestBreaker) fallback(err error) (interface{}, error) {
+	last := err
+	for _, handle := range rb.options.Fallbacks {
+		result, fallbackErr := handle(err)
+		if fallbackErr == nil {
+			return result, nil
+		}
+		last = fallbackErr
+	}
+	return nil, last
+}
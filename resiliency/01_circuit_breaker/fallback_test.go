@@ -0,0 +1,76 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFallbackChain(t *testing.T) {
+
+	var called []string
+	errCacheMiss := errors.New("cache miss")
+	errStandbyDown := errors.New("standby down")
+	step := func(name string, result interface{}, err error) Fallback {
+		return func(cause error) (interface{}, error) {
+			called = append(called, name)
+			if cause != ErrServiceUnavailable {
+				t.Errorf("%s should get the rejection, got %v", name, cause)
+			}
+			return result, err
+		}
+	}
+
+	rb := NewRequestBreaker(WithFallbackChain(
+		step("cache", nil, errCacheMiss),
+		step("standby", nil, errStandbyDown),
+		step("default", "default", nil),
+		step("never", "never", nil),
+	))
+
+	//执行失败默认不走降级链
+	if _, err := rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }); err != errBackendDown || len(called) != 0 {
+		t.Fatalf("failure should not fall back by default, got %v %v", err, called)
+	}
+
+	rb.Reset()
+	tripBreaker(t, rb)
+	res, err := rb.Do(succeedWork)
+	if err != nil || res != "default" {
+		t.Fatalf("third fallback should answer, got %v %v", res, err)
+	}
+	if len(called) != 3 || called[2] != "default" {
+		t.Errorf("fallbacks after the answer should not run, called %v", called)
+	}
+}
+
+func TestFallbackChainAllFail(t *testing.T) {
+
+	errLast := errors.New("last")
+	rb := NewRequestBreaker(WithFallbackOnFailure(true), WithFallbackChain(
+		func(err error) (interface{}, error) { return nil, errors.New("first") },
+		func(err error) (interface{}, error) { return nil, errLast },
+	))
+
+	_, err := rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	if err != errLast {
+		t.Errorf("the error of the last fallback should be returned, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("the failure is still counted, got %+v", cnt)
+	}
+}
+
+func TestTryDoSkipsFallback(t *testing.T) {
+
+	rb := NewRequestBreaker(WithFallbackChain(func(err error) (interface{}, error) { return "default", nil }))
+	tripBreaker(t, rb)
+
+	admitted, res, err := rb.TryDo(succeedWork)
+	if admitted || res != nil || err != ErrServiceUnavailable {
+		t.Errorf("TryDo should report the real rejection, got %v %v %v", admitted, res, err)
+	}
+	if res, err := rb.Do(succeedWork); res != "default" || err != nil {
+		t.Errorf("Do should still fall back, got %v %v", res, err)
+	}
+}
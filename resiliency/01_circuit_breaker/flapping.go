@@ -0,0 +1,53 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:49:21
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:49:21
+ */
+
+package circuit
+
+import "time"
+
+////////////////////////////////
+/// 频繁打开的告警
+/// 一个窗口内打开的次数太多，说明依赖不稳定
+/// 只负责通知，不影响状态转换
+////////////////////////////////
+
+//FlappingHandler is told how many times the breaker tripped within the window
+type FlappingHandler func(name string, tripsPerWindow int)
+
+// WithFlappingAlert calls handler whenever the breaker trips and it has tripped more than
+// threshold times within the trailing window, including this trip, e.g. WithFlappingAlert(time.Minute, 5, alert).
+// The handler runs while the breaker holds its lock, just like OnStateChanged.
+func WithFlappingAlert(window time.Duration, threshold int, handler FlappingHandler) Option {
+	return func(opts *Options) {
+		opts.FlappingWindow = window
+		opts.FlappingThreshold = threshold
+		opts.OnFlapping = handler
+	}
+}
+
+//recordTrip remember a trip at now and alert when there are too many, must be called with the mutex held
+func (rb *RequestBreaker) recordTrip(now time.Time) {
+	handler := rb.options.OnFlapping
+	if handler == nil || rb.options.FlappingWindow <= 0 {
+		return
+	}
+
+	//丢掉窗口之外的打开记录
+	since := now.Add(-rb.options.FlappingWindow)
+	kept := rb.trips[:0]
+	for _, at := range rb.trips {
+		if at.After(since) {
+			kept = append(kept, at)
+		}
+	}
+	rb.trips = append(kept, now)
+
+	if trips := len(rb.trips); trips > rb.options.FlappingThreshold {
+		rb.guard("OnFlapping", func() { handler(rb.options.Name, trips) })
+	}
+}
@@ -0,0 +1,53 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlappingAlert(t *testing.T) {
+
+	clock := newFakeClock()
+	var alerts []int
+	rb := NewRequestBreaker(
+		ActionName("flappy"),
+		WithClock(clock),
+		WithFlappingAlert(time.Minute, 3, func(name string, trips int) {
+			if name != "flappy" {
+				t.Errorf("unexpected name %s", name)
+			}
+			alerts = append(alerts, trips)
+		}),
+	)
+
+	//每10秒打开一次，第4次开始超过阈值
+	for i := 0; i < 5; i++ {
+		rb.Reset()
+		tripBreaker(t, rb)
+		clock.Advance(10 * time.Second)
+	}
+	if len(alerts) != 2 || alerts[0] != 4 || alerts[1] != 5 {
+		t.Fatalf("expected alerts with 4 and 5 trips, got %v", alerts)
+	}
+
+	//窗口过去之后，旧的打开记录不再计算
+	clock.Advance(time.Minute)
+	rb.Reset()
+	tripBreaker(t, rb)
+	if len(alerts) != 2 {
+		t.Errorf("trips outside the window should not alert, got %v", alerts)
+	}
+}
+
+func TestFlappingAlertDoesNotChangeState(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), WithFlappingAlert(time.Minute, 0, func(string, int) {
+		panic("alert failed")
+	}))
+
+	tripBreaker(t, rb)
+	if rb.State() != StateOpen {
+		t.Errorf("alerting is only observational, got %s", rb.State())
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:55:19
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:55:19
+ */
+
+package circuit
+
+import (
+	"math/rand"
+	"sync"
+)
+
+////////////////////////////////
+/// 享元模式
+/// 成千上万个配置相同的断路器，配置(阈值、回调)只保存一份，所有断路器引用同一份
+/// 计数、状态这些每个断路器自己的数据仍然各自独立
+////////////////////////////////
+
+//SharedOptions is an immutable configuration referenced by many breakers
+type SharedOptions struct {
+	options *Options
+	counter ICounter //每个断路器一个副本，不能共享
+}
+
+// NewSharedOptions applies opts once for all the breakers made by NewBreaker.
+// Counter is not shared, each breaker gets a CloneCounter of it if it is a CounterCloner,
+// otherwise the built-in counter. JitterRand and ChaosRand are made safe to share.
+func NewSharedOptions(opts ...Option) *SharedOptions {
+	options := newOptions(opts)
+
+	shared := &SharedOptions{counter: options.Counter}
+	options.Counter = nil
+	//rand.Rand 不能并发使用，换成加锁的随机源
+	if options.JitterRand != nil {
+		options.JitterRand = rand.New(&lockedSource{src: rand.NewSource(options.JitterRand.Int63())})
+	}
+	if options.ChaosRand != nil {
+		options.ChaosRand = rand.New(&lockedSource{src: rand.NewSource(options.ChaosRand.Int63())})
+	}
+	shared.options = &options
+	return shared
+}
+
+//NewBreaker return a closed breaker referencing the shared options
+func (s *SharedOptions) NewBreaker() *RequestBreaker {
+	rb := &RequestBreaker{}
+	rb.initOnce.Do(func() {
+		rb.initWith(s.options)
+		if cloner, ok := s.counter.(CounterCloner); ok {
+			rb.cnter = cloner.CloneCounter()
+		}
+	})
+	return rb
+}
+
+//lockedSource is a rand.Source safe for concurrent use
+type lockedSource struct {
+	mutex sync.Mutex
+	src   rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.src.Seed(seed)
+}
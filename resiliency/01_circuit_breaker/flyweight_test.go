@@ -0,0 +1,45 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSharedOptions(t *testing.T) {
+
+	shared := NewSharedOptions(ActionName("tenant"), Timeout(time.Minute), MaxRequests(2))
+
+	breakers := make([]*RequestBreaker, 1000)
+	for i := range breakers {
+		breakers[i] = shared.NewBreaker()
+	}
+
+	for _, rb := range breakers {
+		if rb.options != shared.options {
+			t.Fatal("breakers should reference the shared options")
+		}
+	}
+	if shared.options.Name != "tenant" || shared.options.Timeout != time.Minute {
+		t.Errorf("options not applied: %+v", shared.options)
+	}
+
+	//状态各自独立
+	tripBreaker(t, breakers[0])
+	if breakers[1].State() != StateClosed {
+		t.Errorf("tripping one breaker should not affect another, got %s", breakers[1].State())
+	}
+	breakers[2].Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	if cnt := breakers[3].Counts(); cnt.Requests != 0 {
+		t.Errorf("counters should not be shared, got %+v", cnt)
+	}
+}
+
+func TestSharedOptionsCloneCounter(t *testing.T) {
+
+	shared := NewSharedOptions(WithCounter(&Counts{}))
+	a, b := shared.NewBreaker(), shared.NewBreaker()
+	if a.counter() == b.counter() {
+		t.Error("each breaker should get its own counter")
+	}
+}
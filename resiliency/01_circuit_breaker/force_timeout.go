@@ -0,0 +1,68 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:51:26
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+//ErrTimeout is returned in force timeout mode when the deadline passes before work returns,
+//it also matches context.DeadlineExceeded
+var ErrTimeout = newBreakerError("request timed out")
+
+// WithForceTimeout makes DoContext return as soon as the context of a request is done,
+// even if work ignores the cancellation, the request is counted as a failure.
+// Work runs in its own goroutine which is abandoned on timeout and keeps running until work returns,
+// so uncooperative work leaks a goroutine each time, its result is dropped.
+// By default the breaker waits for work to return.
+func WithForceTimeout(force bool) Option {
+	return func(opts *Options) {
+		opts.ForceTimeout = force
+	}
+}
+
+type workResult struct {
+	result interface{}
+	err    error
+	panic  interface{}
+}
+
+//run call work, in force timeout mode it stops waiting once ctx is done
+func (rb *RequestBreaker) run(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if !rb.options.ForceTimeout || ctx.Done() == nil {
+		return work(ctx)
+	}
+
+	//缓冲为1，超时之后work的goroutine也能退出
+	done := make(chan workResult, 1)
+	go func() {
+		var res workResult
+		defer func() {
+			res.panic = recover()
+			done <- res
+		}()
+		res.result, res.err = work(ctx)
+	}()
+
+	select {
+	case res := <-done:
+		//work 的 panic 交给调用方
+		if res.panic != nil {
+			panic(res.panic)
+		}
+		return res.result, res.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+		}
+		return nil, ctx.Err()
+	}
+}
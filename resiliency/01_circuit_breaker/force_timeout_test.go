@@ -0,0 +1,45 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestForceTimeoutReturnsAtDeadline(t *testing.T) {
+
+	rb := NewRequestBreaker(WithForceTimeout(true), WithRequestTimeout(20*time.Millisecond))
+
+	release := make(chan struct{})
+	defer close(release)
+
+	start := time.Now()
+	_, err := rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		<-release //不理会取消
+		return "late", nil
+	})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected ErrTimeout, got %v", err)
+	}
+	if elapsed > time.Second {
+		t.Errorf("DoContext should return at the deadline, took %v", elapsed)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("timeout should count as failure, got %+v", cnt)
+	}
+}
+
+func TestForceTimeoutPassesResult(t *testing.T) {
+
+	rb := NewRequestBreaker(WithForceTimeout(true), WithRequestTimeout(time.Second))
+
+	res, err := rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return "ok", nil
+	})
+	if err != nil || res != "ok" {
+		t.Errorf("finished work should pass its result, got %v %v", res, err)
+	}
+}
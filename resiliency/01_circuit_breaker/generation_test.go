@@ -0,0 +1,139 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutcomeAfterIntervalRolloverIsDropped(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Expiry(clock.Now().Add(10*time.Second)),
+		Interval(10*time.Second))
+
+	failing := func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+
+	//a slow request admitted in the first generation
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return nil, errBackendDown
+		})
+		close(done)
+	}()
+	<-started
+
+	//the interval rolls while the slow request is still running
+	clock.Advance(11 * time.Second)
+	rb.Do(failing)
+	rb.Do(failing)
+
+	if cnt := rb.Counts(); cnt.ConsecutiveFailures != 2 {
+		t.Fatalf("new generation should only see its own failures, got %+v", cnt)
+	}
+
+	close(release)
+	<-done
+
+	cnt := rb.Counts()
+	if cnt.TotalFailures != 2 || cnt.Requests != 2 {
+		t.Errorf("late outcome should not land in the new generation, got %+v", cnt)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("late outcome should not trip the breaker, got %s", rb.State())
+	}
+}
+
+func TestRolloverRaceAttributesOutcomesToAdmission(t *testing.T) {
+
+	const stale, fresh = 50, 50
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Expiry(clock.Now().Add(10*time.Second)),
+		Interval(10*time.Second))
+
+	//一批请求在第一代放行，一直执行到周期到期之后
+	var started, done sync.WaitGroup
+	release := make(chan struct{})
+	for i := 0; i < stale; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rb.Do(func(ctx context.Context) (interface{}, error) {
+				started.Done()
+				<-release
+				return nil, errBackendDown
+			})
+		}()
+	}
+	started.Wait()
+
+	clock.Advance(11 * time.Second)
+	rb.Do(succeedWork)
+
+	//旧一代的失败和新一代的成功同时结束
+	close(release)
+	for i := 0; i < fresh; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rb.Do(succeedWork)
+		}()
+	}
+	done.Wait()
+
+	cnt := rb.Counts()
+	if cnt.Requests != fresh+1 || cnt.TotalFailures != 0 {
+		t.Errorf("only the outcomes admitted in the new generation should count, got %+v", cnt)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("%d stale failures should not trip the breaker, got %s", stale, rb.State())
+	}
+}
+
+func TestStaleLatencyIsDropped(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Expiry(clock.Now().Add(10*time.Second)),
+		Interval(10*time.Second), TripOnLatencyQuantile(0.5, time.Second))
+
+	var started, done sync.WaitGroup
+	release := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rb.Do(func(ctx context.Context) (interface{}, error) {
+				started.Done()
+				<-release
+				return "slow", nil
+			})
+		}()
+	}
+	started.Wait()
+
+	//满了一个周期，慢请求测出来的延迟是11秒
+	clock.Advance(11 * time.Second)
+	rb.Do(succeedWork)
+	close(release)
+	done.Wait()
+
+	//新一代自己的延迟中位数没有超过阈值
+	rb.Do(succeedWork)
+	rb.Do(func(ctx context.Context) (interface{}, error) {
+		clock.Advance(2 * time.Second)
+		return "ok", nil
+	})
+
+	if rb.State() != StateClosed {
+		t.Errorf("latency of the previous generation should not trip the new one, got %s", rb.State())
+	}
+}
@@ -0,0 +1,6 @@
+// Package grpcbreaker guards gRPC client calls with a circuit breaker.
+//
+// The interceptors are only built with the grpc build tag:
+//
+//	go test -tags grpc ./grpcbreaker
+package grpcbreaker
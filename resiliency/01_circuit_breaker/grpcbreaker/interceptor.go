@@ -0,0 +1,114 @@
+//go:build grpc
+
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:42:32
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:42:32
+ */
+
+package grpcbreaker
+
+import (
+	"context"
+	"sync"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//StreamOption set the stream interceptor
+type StreamOption func(o *streamOptions)
+
+type streamOptions struct {
+	midStreamFailure func(err error) bool
+}
+
+//CountMidStreamErrors count an error received after the stream is established as a failure when isFailure returns true,
+//by default errors after establishment say nothing about the backend and are not counted
+func CountMidStreamErrors(isFailure func(err error) bool) StreamOption {
+	return func(o *streamOptions) {
+		o.midStreamFailure = isFailure
+	}
+}
+
+//rejected turn a rejection of breaker into codes.Unavailable
+func rejected(err error) error {
+	return status.Error(codes.Unavailable, err.Error())
+}
+
+//UnaryClientInterceptor run every unary call through rb, it returns codes.Unavailable without calling while rb is open
+func UnaryClientInterceptor(rb *circuit.RequestBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		admitted, _, err := rb.TryDo(func(context.Context) (interface{}, error) {
+			return nil, invoker(ctx, method, req, reply, cc, opts...)
+		})
+		if !admitted {
+			return rejected(err)
+		}
+		return err
+	}
+}
+
+// StreamClientInterceptor runs the establishment of every stream through rb.
+// A stream is established once it is opened and its first message or end is received,
+// failing to open it or an Unavailable on the first Recv counts as a failure.
+// While rb is open no stream is opened, codes.Unavailable is returned at once.
+// A stream whose first Recv comes after the reservation TTL of rb is not counted.
+func StreamClientInterceptor(rb *circuit.RequestBreaker, opts ...StreamOption) grpc.StreamClientInterceptor {
+
+	var o streamOptions
+	for _, setOption := range opts {
+		setOption(&o)
+	}
+
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, callOpts ...grpc.CallOption) (grpc.ClientStream, error) {
+
+		token, err := rb.Prepare()
+		if err != nil {
+			return nil, rejected(err)
+		}
+
+		//流的生命周期跟着调用方的ctx，不能用断路器的
+		stream, err := streamer(ctx, desc, cc, method, callOpts...)
+		if err != nil {
+			rb.Commit(token, err)
+			return nil, err
+		}
+
+		return &guardedStream{ClientStream: stream, rb: rb, token: token, opts: &o}, nil
+	}
+}
+
+//guardedStream commit the reservation on the first Recv
+type guardedStream struct {
+	grpc.ClientStream
+	rb          *circuit.RequestBreaker
+	token       circuit.Token
+	opts        *streamOptions
+	established sync.Once
+}
+
+func (s *guardedStream) RecvMsg(m interface{}) error {
+
+	err := s.ClientStream.RecvMsg(m)
+
+	first := false
+	s.established.Do(func() {
+		first = true
+		//第一次Recv只有Unavailable说明流没有建立起来，io.EOF和其他状态都说明后端在工作
+		var outcome error
+		if status.Code(err) == codes.Unavailable {
+			outcome = err
+		}
+		s.rb.Commit(s.token, outcome)
+	})
+
+	if !first && err != nil && s.opts.midStreamFailure != nil && s.opts.midStreamFailure(err) {
+		s.rb.Do(func(context.Context) (interface{}, error) { return nil, err })
+	}
+	return err
+}
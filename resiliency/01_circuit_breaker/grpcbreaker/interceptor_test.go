@@ -0,0 +1,174 @@
+//go:build grpc
+
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const watchMethod = "/test.Watcher/Watch"
+
+var watchDesc = &grpc.StreamDesc{StreamName: "Watch", ServerStreams: true}
+
+//startServer serve a Watch stream handled by handler on a bufconn listener
+func startServer(t *testing.T, handler grpc.StreamHandler) *bufconn.Listener {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 16)
+	s := grpc.NewServer()
+	desc := *watchDesc
+	desc.Handler = handler
+	s.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "test.Watcher",
+		HandlerType: (*interface{})(nil),
+		Streams:     []grpc.StreamDesc{desc},
+	}, struct{}{})
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	return lis
+}
+
+func dial(t *testing.T, dialer func(context.Context, string) (net.Conn, error), rb *circuit.RequestBreaker, opts ...StreamOption) *grpc.ClientConn {
+	t.Helper()
+
+	cc, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(dialer),
+		grpc.WithStreamInterceptor(StreamClientInterceptor(rb, opts...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { cc.Close() })
+	return cc
+}
+
+//watch open a Watch stream and receive its first message
+func watch(ctx context.Context, cc *grpc.ClientConn) error {
+	stream, err := cc.NewStream(ctx, watchDesc, watchMethod)
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(&empty.Empty{}); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+	return stream.RecvMsg(&empty.Empty{})
+}
+
+func TestStreamCreationFailureTrips(t *testing.T) {
+
+	refused := func(context.Context, string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+	rb := circuit.NewRequestBreaker(circuit.Timeout(time.Minute))
+	cc := dial(t, refused, rb)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := watch(ctx, cc); status.Code(err) != codes.Unavailable {
+			t.Fatalf("stream %d: expected Unavailable, got %v", i, err)
+		}
+	}
+	if rb.State() != circuit.StateOpen {
+		t.Fatalf("failed streams should trip the breaker, got %v", rb.State())
+	}
+
+	streamed := false
+	_, err := StreamClientInterceptor(rb)(ctx, watchDesc, cc, watchMethod,
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			streamed = true
+			return nil, nil
+		})
+	if status.Code(err) != codes.Unavailable || streamed {
+		t.Errorf("open breaker should return Unavailable without opening a stream, got %v opened %v", err, streamed)
+	}
+}
+
+func TestUnavailableOnFirstRecvTrips(t *testing.T) {
+
+	lis := startServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+		return status.Error(codes.Unavailable, "warming up")
+	})
+	rb := circuit.NewRequestBreaker()
+	cc := dial(t, func(context.Context, string) (net.Conn, error) { return lis.Dial() }, rb)
+
+	for i := 0; i < 3; i++ {
+		if err := watch(context.Background(), cc); status.Code(err) != codes.Unavailable {
+			t.Fatalf("stream %d: expected Unavailable, got %v", i, err)
+		}
+	}
+	if rb.State() != circuit.StateOpen {
+		t.Errorf("Unavailable on the first Recv should trip the breaker, got %v", rb.State())
+	}
+}
+
+func TestMidStreamErrors(t *testing.T) {
+
+	lis := startServer(t, func(srv interface{}, stream grpc.ServerStream) error {
+		if err := stream.SendMsg(&empty.Empty{}); err != nil {
+			return err
+		}
+		return status.Error(codes.Internal, "lost")
+	})
+	dialer := func(context.Context, string) (net.Conn, error) { return lis.Dial() }
+
+	run := func(rb *circuit.RequestBreaker, opts ...StreamOption) {
+		cc := dial(t, dialer, rb, opts...)
+		stream, err := cc.NewStream(context.Background(), watchDesc, watchMethod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream.SendMsg(&empty.Empty{})
+		stream.CloseSend()
+		if err := stream.RecvMsg(&empty.Empty{}); err != nil {
+			t.Fatalf("first message should arrive, got %v", err)
+		}
+		if err := stream.RecvMsg(&empty.Empty{}); status.Code(err) != codes.Internal {
+			t.Fatalf("expected Internal, got %v", err)
+		}
+	}
+
+	ignored := circuit.NewRequestBreaker()
+	run(ignored)
+	if cnt := ignored.Counts(); cnt.TotalSuccesses != 1 || cnt.TotalFailures != 0 {
+		t.Errorf("by default only the establishment is counted, got %+v", cnt)
+	}
+
+	counted := circuit.NewRequestBreaker()
+	run(counted, CountMidStreamErrors(func(err error) bool { return status.Code(err) == codes.Internal }))
+	if cnt := counted.Counts(); cnt.TotalSuccesses != 1 || cnt.TotalFailures != 1 {
+		t.Errorf("the mid-stream error should be counted, got %+v", cnt)
+	}
+}
+
+func TestUnaryRejectionWithFallbackChain(t *testing.T) {
+
+	rb := circuit.NewRequestBreaker(circuit.Timeout(time.Minute),
+		circuit.WithFallbackChain(func(err error) (interface{}, error) { return "default", nil }))
+	interceptor := UnaryClientInterceptor(rb)
+	failing := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "down")
+	}
+
+	for i := 0; i < 3; i++ {
+		interceptor(context.Background(), "/test.Svc/Get", nil, nil, nil, failing)
+	}
+	//断路器的回退链不能让拦截器拿到空的错误
+	err := interceptor(context.Background(), "/test.Svc/Get", nil, nil, nil, failing)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("an open breaker should answer Unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:17:15
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:27:44
+ */
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+//用户提供的回调都在断路器的锁内执行，回调里的panic不能让调用Do的goroutine崩溃
+
+//guard run a user callback, a panic is recovered, logged and reported as false
+func (rb *RequestBreaker) guard(callback string, fn func()) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			rb.logPanic(callback, r)
+			ok = false
+		}
+	}()
+	fn()
+	return true
+}
+
+//errWorkPanicked is counted for a request whose work panicked
+var errWorkPanicked = errors.New("work panicked")
+
+//abandon release the admission of a request whose work never returned and count it as a failure
+func (rb *RequestBreaker) abandon(generation uint32, r interface{}) {
+	rb.mutex.Lock()
+	rb.release(Token{generation: generation})
+	rb.mutex.Unlock()
+	err := errWorkPanicked
+	if r != nil {
+		err = fmt.Errorf("%w: %v", errWorkPanicked, r)
+	}
+	rb.afterRequest(generation, err, unmeasured)
+}
+
+//verifyRecovery ask RecoveryVerifier whether the breaker may close, a panicking verifier keeps it half-open
+func (rb *RequestBreaker) verifyRecovery() bool {
+	verify := rb.options.RecoveryVerifier
+	if verify == nil {
+		return true
+	}
+	ctx := rb.options.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ok := false
+	rb.guard("RecoveryVerifier", func() { ok = verify(ctx) })
+	return ok
+}
+
+//canOpen ask CanOpenFor, CanOpenContext, the trip threshold or CanOpen whether to trip, a panicking condition does not trip,
+//nothing trips during the warm-up or before enough distinct error groups have failed
+func (rb *RequestBreaker) canOpen(state State, cnt Counts) bool {
+	if rb.now().Before(rb.warmUntil) {
+		return false
+	}
+	if min := rb.options.MinErrorGroups; min > 0 && uint32(len(rb.errorGroups)) < min {
+		return false
+	}
+
+	trip := false
+	if condition := rb.options.CanOpenFor; condition != nil {
+		var openFor time.Duration
+		if !rb.guard("CanOpenFor", func() { trip, openFor = condition(state, cnt) }) {
+			return false
+		}
+		if trip {
+			rb.requestedOpen = openFor
+			rb.conditionMet(state, cnt)
+		}
+		return trip
+	}
+	if condition := rb.options.CanOpenContext; condition != nil {
+		if !rb.guard("CanOpenContext", func() { trip = condition(rb.options.Name, state, cnt) }) {
+			return false
+		}
+		if trip {
+			rb.conditionMet(state, cnt)
+		}
+		return trip
+	}
+	if threshold := rb.threshold.Load(); threshold != nil {
+		if trip = threshold.met(cnt); trip {
+			rb.conditionMet(state, cnt)
+		}
+		return trip
+	}
+	if !rb.guard("CanOpen", func() { trip = rb.options.CanOpen(state, cnt) }) {
+		return false
+	}
+	if trip {
+		rb.conditionMet(state, cnt)
+	}
+	return trip
+}
@@ -0,0 +1,56 @@
+package circuit
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestPanickingCanOpenDoesNotTrip(t *testing.T) {
+
+	h := &captureHandler{}
+	rb := NewRequestBreaker(WithLogger(slog.New(h)), WithBreakCondition(func(State, Counts) bool {
+		panic("bad policy")
+	}))
+
+	for i := 0; i < 5; i++ {
+		if _, err := rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }); err != errBackendDown {
+			t.Fatalf("Do should return the work error, got %v", err)
+		}
+	}
+
+	//锁已经释放，断路器还能正常工作
+	done := make(chan State)
+	go func() { done <- rb.State() }()
+	select {
+	case s := <-done:
+		if s != StateClosed {
+			t.Errorf("a panicking CanOpen should not trip, got %s", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("breaker is left locked")
+	}
+
+	if len(h.records) != 5 || h.records[0].Level != slog.LevelError {
+		t.Fatalf("each panic should be logged at Error, got %d records", len(h.records))
+	}
+	if attrs := recordAttrs(h.records[0]); attrs["callback"].String() != "CanOpen" || attrs["panic"].String() != "bad policy" {
+		t.Errorf("unexpected attrs %v", attrs)
+	}
+}
+
+func TestPanickingCallbacksKeepTransition(t *testing.T) {
+
+	rb := NewRequestBreaker(WithStateChanged(func(string, State, State) {
+		panic("bad handler")
+	}))
+	rb.Events().Subscribe(ObserverFunc[StateChange](func(StateChange) {
+		panic("bad observer")
+	}))
+
+	tripBreaker(t, rb)
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Errorf("breaker should keep working after callbacks panic, got %v", err)
+	}
+}
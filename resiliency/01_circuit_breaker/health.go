@@ -0,0 +1,70 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:13:49
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:32:22
+ */
+
+package circuit
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+////////////////////////////////
+/// 健康分
+/// 对每次执行的结果做指数加权移动平均(EWMA)，成功记1，失败记0
+/// 设置了目标延迟时，慢的成功按 target/latency 打折
+/// 被拒绝的请求没有执行，不影响健康分
+////////////////////////////////
+
+//WithHealthWeighting set the weight of the latest outcome in the health score, in (0, 1], default 0.1
+func WithHealthWeighting(alpha float64) Option {
+	return func(opts *Options) {
+		opts.HealthAlpha = alpha
+	}
+}
+
+//WithLatencyPenalty discount successes slower than target in the health score
+func WithLatencyPenalty(target time.Duration) Option {
+	return func(opts *Options) {
+		opts.LatencyTarget = target
+	}
+}
+
+//HealthScore return the health of recent requests between 0 and 1, a new breaker has 1
+func (rb *RequestBreaker) HealthScore() float64 {
+	rb.lazyInit()
+	return math.Float64frombits(atomic.LoadUint64(&rb.health))
+}
+
+//observeHealth fold the outcome of one request into the health score without lock
+func (rb *RequestBreaker) observeHealth(err error, latency time.Duration) {
+
+	alpha := rb.options.HealthAlpha
+	if alpha <= 0 {
+		return
+	}
+	if alpha > 1 {
+		alpha = 1
+	}
+
+	sample := 0.0
+	if err == nil {
+		sample = 1
+		if target := rb.options.LatencyTarget; target > 0 && latency > target {
+			sample = float64(target) / float64(latency)
+		}
+	}
+
+	for {
+		old := atomic.LoadUint64(&rb.health)
+		score := alpha*sample + (1-alpha)*math.Float64frombits(old)
+		if atomic.CompareAndSwapUint64(&rb.health, old, math.Float64bits(score)) {
+			return
+		}
+	}
+}
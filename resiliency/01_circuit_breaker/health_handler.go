@@ -0,0 +1,47 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:53:25
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:31:00
+ */
+
+package circuit
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+////////////////////////////////
+/// 断路器作为健康检查的HTTP接口
+/// 关键依赖的断路器打开时，readiness 探针失败，编排系统把流量转给别的实例
+////////////////////////////////
+
+type healthBody struct {
+	Name   string `json:"name"`
+	State  string `json:"state"`
+	DryRun bool   `json:"dryRun,omitempty"`
+}
+
+//HealthHandler serve the state of rb as JSON, 200 when it is closed or half-open, 503 when it is open and may not
+//try to recover yet, a WithDryRun breaker is always 200 since it rejects nothing
+func HealthHandler(rb *RequestBreaker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rb.lazyInit()
+		rb.mutex.Lock()
+		body := healthBody{Name: rb.options.Name, State: rb.state.String(), DryRun: rb.options.DryRun}
+		//打开时间已经过了，下一个请求就会转到半开去试探，和 AllowRequest 一样算作可用
+		open := rb.state == StateOpen && !rb.options.DryRun && !rb.canRecover(rb.now())
+		rb.mutex.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if open {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		json.NewEncoder(w).Encode(body)
+	})
+}
@@ -0,0 +1,63 @@
+package circuit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(ActionName("db"), WithClock(clock), Timeout(time.Second))
+	handler := HealthHandler(rb)
+
+	check := func(wantCode int, wantState string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+		if rec.Code != wantCode {
+			t.Errorf("expected %d, got %d", wantCode, rec.Code)
+		}
+		var body healthBody
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.Name != "db" || body.State != wantState {
+			t.Errorf("expected db %s, got %+v", wantState, body)
+		}
+	}
+
+	check(http.StatusOK, "closed")
+
+	tripBreaker(t, rb)
+	check(http.StatusServiceUnavailable, "open")
+
+	//打开时间过了，下一个请求就能试探，虽然状态还是打开
+	clock.Advance(2 * time.Second)
+	check(http.StatusOK, "open")
+
+	//占一个试探名额转到半开
+	if _, err := rb.Prepare(); err != nil || rb.State() != StateHalfOpen {
+		t.Fatalf("expected half-open, got %s %v", rb.State(), err)
+	}
+	check(http.StatusOK, "half-open")
+}
+
+func TestHealthHandlerDryRun(t *testing.T) {
+
+	rb := NewRequestBreaker(WithDryRun(true))
+	tripBreaker(t, rb)
+
+	//影子状态打开时不拒绝请求，探针不应失败
+	rec := httptest.NewRecorder()
+	HealthHandler(rb).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	var body healthBody
+	json.NewDecoder(rec.Body).Decode(&body)
+	if rec.Code != http.StatusOK || body.State != "open" || !body.DryRun {
+		t.Errorf("a dry-run breaker should be healthy, got %d %+v", rec.Code, body)
+	}
+}
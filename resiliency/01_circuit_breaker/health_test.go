@@ -0,0 +1,67 @@
+package circuit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHealthScoreAlternatingOutcomes(t *testing.T) {
+
+	alpha := 0.2
+	rb := NewRequestBreaker(WithHealthWeighting(alpha), WithBreakCondition(func(State, Counts) bool { return false }))
+	if rb.HealthScore() != 1 {
+		t.Fatalf("a new breaker should be healthy, got %v", rb.HealthScore())
+	}
+
+	fail := func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+
+	//失败、成功交替，每一对之后的分数单调地收敛到 0.5+alpha/(2(2-alpha))
+	expected := 0.5 + alpha/(2*(2-alpha))
+	distance := math.Abs(rb.HealthScore() - expected)
+	for i := 0; i < 30; i++ {
+		rb.Do(fail)
+		rb.Do(succeedWork)
+
+		d := math.Abs(rb.HealthScore() - expected)
+		if d >= distance {
+			t.Fatalf("pair %d: score %v should move toward %v", i, rb.HealthScore(), expected)
+		}
+		distance = d
+	}
+	if distance > 0.001 {
+		t.Errorf("score %v should settle at %v", rb.HealthScore(), expected)
+	}
+}
+
+func TestHealthScoreLatencyPenalty(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), WithHealthWeighting(1), WithLatencyPenalty(100*time.Millisecond))
+
+	rb.Do(func(ctx context.Context) (interface{}, error) {
+		clock.Advance(400 * time.Millisecond)
+		return nil, nil
+	})
+	if got := rb.HealthScore(); got != 0.25 {
+		t.Errorf("a success 4x slower than target should score 0.25, got %v", got)
+	}
+
+	rb.Do(succeedWork)
+	if got := rb.HealthScore(); got != 1 {
+		t.Errorf("a fast success should score 1, got %v", got)
+	}
+}
+
+func TestHealthScoreIgnoresRejections(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	tripBreaker(t, rb)
+
+	before := rb.HealthScore()
+	rb.Do(succeedWork)
+	if rb.HealthScore() != before {
+		t.Errorf("rejected requests should not change the score, %v -> %v", before, rb.HealthScore())
+	}
+}
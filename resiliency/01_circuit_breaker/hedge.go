@@ -0,0 +1,119 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:04:39
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 对冲请求
+/// 主请求在delay之后还没有返回，就再发一个对冲请求，谁先返回用谁的结果
+/// 后端整体变慢的时候对冲会让负载翻倍，所以用预算限制对冲的比例
+////////////////////////////////
+
+type hedgeEvent int
+
+const (
+	hedgeRequest hedgeEvent = iota
+	hedgeSpawned
+)
+
+//HedgeOption set Hedger
+type HedgeOption func(h *Hedger)
+
+//WithHedgeBudget allow at most fraction of the requests within window to spawn a hedge,
+//once the budget is spent slow primaries just wait
+func WithHedgeBudget(fraction float64, window time.Duration) HedgeOption {
+	return func(h *Hedger) {
+		h.budget = fraction
+		h.events = NewCounter[hedgeEvent](window)
+	}
+}
+
+//Hedger send a hedged request through the breaker when the primary is slow
+type Hedger struct {
+	rb     *RequestBreaker
+	delay  time.Duration
+	budget float64
+	events *Counter[hedgeEvent]
+	//检查和花掉预算要一起做，不然并发的慢请求会一起超出预算
+	spend sync.Mutex
+}
+
+//NewHedger return a Hedger firing a hedge after delay, without a budget every slow request is hedged
+func NewHedger(rb *RequestBreaker, delay time.Duration, opts ...HedgeOption) *Hedger {
+	h := &Hedger{rb: rb, delay: delay}
+	for _, setOption := range opts {
+		setOption(h)
+	}
+	return h
+}
+
+type hedgeResult struct {
+	result interface{}
+	err    error
+}
+
+// Do run work through the breaker, and run it again as a hedge if the first one
+// has not returned after delay and the budget allows.
+// The first result wins, the other work is canceled through its context.
+func (h *Hedger) Do(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	run := func() {
+		res, err := h.rb.DoContext(ctx, work)
+		results <- hedgeResult{result: res, err: err}
+	}
+
+	if h.events != nil {
+		h.events.Count(hedgeRequest)
+	}
+	go run()
+
+	timer := h.rb.clock().NewTimer(h.delay)
+	defer timer.Stop()
+
+	select {
+	case r := <-results:
+		return r.result, r.err
+	case <-timer.C():
+	}
+
+	if !h.allowHedge() {
+		r := <-results
+		return r.result, r.err
+	}
+	go run()
+
+	r := <-results
+	return r.result, r.err
+}
+
+//allowHedge check and spend the budget
+func (h *Hedger) allowHedge() bool {
+	if h.events == nil {
+		return true
+	}
+
+	h.spend.Lock()
+	defer h.spend.Unlock()
+	allowed := float64(h.events.Total(hedgeRequest)) * h.budget
+	if float64(h.events.Total(hedgeSpawned)+1) > allowed {
+		return false
+	}
+
+	h.events.Count(hedgeSpawned)
+	return true
+}
@@ -0,0 +1,88 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgeBudgetLimitsHedges(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("hedge"))
+	h := NewHedger(rb, time.Millisecond, WithHedgeBudget(0.1, time.Minute))
+
+	slow := func(ctx context.Context) (interface{}, error) {
+		select {
+		case <-time.After(5 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return "done", nil
+	}
+
+	const requests = 30
+	for i := 0; i < requests; i++ {
+		if res, err := h.Do(context.Background(), slow); err != nil || res != "done" {
+			t.Fatalf("request %d: unexpected %v %v", i, res, err)
+		}
+	}
+
+	hedges := h.events.Total(hedgeSpawned)
+	if hedges != 3 {
+		t.Errorf("10%% budget of %d requests should allow 3 hedges, got %d", requests, hedges)
+	}
+}
+
+func TestHedgeWithoutBudget(t *testing.T) {
+
+	h := NewHedger(NewRequestBreaker(), time.Millisecond)
+
+	var calls int32
+	slow := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		select {
+		case <-time.After(5 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return nil, nil
+	}
+
+	for i := 0; i < 5; i++ {
+		h.Do(context.Background(), slow)
+	}
+
+	//the losing hedge may still be on its way into work
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&calls) < 10 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := atomic.LoadInt32(&calls); got != 10 {
+		t.Errorf("every slow request should be hedged, got %d calls", got)
+	}
+}
+
+func TestHedgeBudgetUnderConcurrency(t *testing.T) {
+
+	h := NewHedger(NewRequestBreaker(), time.Millisecond, WithHedgeBudget(0.1, time.Minute))
+	for i := 0; i < 100; i++ {
+		h.events.Count(hedgeRequest)
+	}
+
+	//并发的慢请求一起来要预算，只有10个能拿到
+	var allowed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if h.allowHedge() {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 10 {
+		t.Errorf("10%% of 100 requests should allow 10 hedges, got %d", allowed)
+	}
+}
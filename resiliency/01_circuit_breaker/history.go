@@ -0,0 +1,72 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:19:56
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:19:56
+ */
+
+package circuit
+
+////////////////////////////////
+/// 状态变化的历史 + 迭代器模式
+/// 最近的状态变化保存在环形缓冲区里
+/// 迭代器创建时复制一份快照，之后新的状态变化不影响正在进行的迭代
+////////////////////////////////
+
+//WithTransitionHistory keep the last size state changes for TransitionIterator
+func WithTransitionHistory(size int) Option {
+	return func(opts *Options) {
+		opts.HistorySize = size
+	}
+}
+
+//transitionHistory is a ring buffer of state changes, guarded by the mutex of breaker
+type transitionHistory struct {
+	changes []StateChange
+	next    int //缓冲区满了之后，下一个要覆盖的位置，也就是最旧的一条
+}
+
+func (h *transitionHistory) record(change StateChange) {
+	switch {
+	case cap(h.changes) == 0:
+		return
+	case len(h.changes) < cap(h.changes):
+		h.changes = append(h.changes, change)
+	default:
+		h.changes[h.next] = change
+		h.next = (h.next + 1) % len(h.changes)
+	}
+}
+
+//snapshot copy the changes from the oldest to the newest
+func (h *transitionHistory) snapshot() []StateChange {
+	out := make([]StateChange, 0, len(h.changes))
+	out = append(out, h.changes[h.next:]...)
+	return append(out, h.changes[:h.next]...)
+}
+
+//TransitionIterator walk a snapshot of the transition history, from the oldest to the newest
+type TransitionIterator struct {
+	index   int
+	changes []StateChange
+}
+
+//HasNext report whether there is another state change
+func (it *TransitionIterator) HasNext() bool {
+	return it.index < len(it.changes)
+}
+
+//Next return the next state change, call HasNext first
+func (it *TransitionIterator) Next() StateChange {
+	change := it.changes[it.index]
+	it.index++
+	return change
+}
+
+//TransitionIterator return an iterator over the recorded state changes, see WithTransitionHistory
+func (rb *RequestBreaker) TransitionIterator() *TransitionIterator {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return &TransitionIterator{changes: rb.history.snapshot()}
+}
@@ -0,0 +1,46 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransitionIterator(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithTransitionHistory(4))
+
+	//两轮 closed -> open -> half-open -> closed，一共6次，只保留最后4次
+	for i := 0; i < 2; i++ {
+		tripBreaker(t, rb)
+		clock.Advance(2 * time.Second)
+		rb.Do(succeedWork)
+	}
+
+	it := rb.TransitionIterator()
+
+	//新的状态变化不影响已经创建的迭代器
+	tripBreaker(t, rb)
+
+	want := [][2]State{
+		{StateHalfOpen, StateClosed},
+		{StateClosed, StateOpen},
+		{StateOpen, StateHalfOpen},
+		{StateHalfOpen, StateClosed},
+	}
+	n := 0
+	for it.HasNext() {
+		change := it.Next()
+		if n < len(want) && (change.From != want[n][0] || change.To != want[n][1]) {
+			t.Errorf("change %d: expected %s -> %s, got %s -> %s", n, want[n][0], want[n][1], change.From, change.To)
+		}
+		n++
+	}
+	if n != len(want) {
+		t.Errorf("expected %d changes, got %d", len(want), n)
+	}
+
+	if NewRequestBreaker().TransitionIterator().HasNext() {
+		t.Error("history is off by default")
+	}
+}
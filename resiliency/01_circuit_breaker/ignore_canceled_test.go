@@ -0,0 +1,63 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallerCanceledIsIgnored(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+		cancel() //用户离开了页面
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("caller should still see the cancellation, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 0 || cnt.TotalSuccesses != 0 {
+		t.Errorf("caller cancellation should be ignored, got %+v", cnt)
+	}
+}
+
+func TestBreakerTimeoutCountsAsFailure(t *testing.T) {
+
+	rb := NewRequestBreaker(WithRequestTimeout(10 * time.Millisecond))
+
+	_, err := rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected DeadlineExceeded, got %v", err)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 1 {
+		t.Errorf("breaker timeout should count as failure, got %+v", cnt)
+	}
+}
+
+func TestCanceledProbeGivesBackSlot(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(1))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rb.DoContext(ctx, succeedWork)
+
+	if rb.State() != StateHalfOpen {
+		t.Fatalf("canceled probe should not decide the state, got %v", rb.State())
+	}
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("next probe should be admitted and close the breaker, got %v %v", err, rb.State())
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:02:02
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package circuit
+
+import "context"
+
+////////////////////////////////
+/// 打开时取消还在执行的请求
+/// 断路器因为一批失败打开的时候，还挂在坏掉的后端上的慢请求基本不会成功了
+/// 主动取消它们，早点把资源还给调用方
+////////////////////////////////
+
+//ErrTripped is the cause of the context of a request canceled because the breaker opened,
+//read it with context.Cause inside work
+var ErrTripped = newBreakerError("circuit breaker tripped")
+
+//canceledContext is handed to the requests admitted before a trip they could not see
+var canceledContext = func() context.Context {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrTripped)
+	return ctx
+}()
+
+// WithCancelInflightOnTrip cancels the context of every running request when the breaker opens,
+// the request is then reported with context.Canceled, its outcome belongs to the generation before
+// the trip and is not counted, context.Cause of it is ErrTripped. Only work honoring its context is aborted.
+// Every admitted request takes the mutex once more to join the current trip context.
+func WithCancelInflightOnTrip(cancel bool) Option {
+	return func(opts *Options) {
+		opts.CancelInflightOnTrip = cancel
+	}
+}
+
+//withTrip derive the context of a request admitted in generation, it is canceled when the breaker opens
+func (rb *RequestBreaker) withTrip(ctx context.Context, generation uint32) (context.Context, func()) {
+	return cancelWith(ctx, rb.tripContext(generation))
+}
+
+//tripContext return the context shared by the requests running since the last trip
+func (rb *RequestBreaker) tripContext(generation uint32) context.Context {
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	//放行之后、拿到context之前断路器已经打开过，这个请求也在取消之列
+	if int32(rb.tripGeneration-generation) > 0 {
+		return canceledContext
+	}
+	if rb.tripCtx == nil {
+		rb.tripCtx, rb.tripCancel = context.WithCancelCause(context.Background())
+	}
+	return rb.tripCtx
+}
+
+//cancelInflight cancel the requests running before this trip, must be called with the mutex held
+func (rb *RequestBreaker) cancelInflight() {
+	if !rb.options.CancelInflightOnTrip || rb.options.DryRun {
+		return
+	}
+	rb.tripGeneration = rb.generation
+	if rb.tripCancel != nil {
+		rb.tripCancel(ErrTripped)
+		rb.tripCtx, rb.tripCancel = nil, nil
+	}
+}
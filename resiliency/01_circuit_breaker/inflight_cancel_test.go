@@ -0,0 +1,69 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+//startSlow start n requests which wait on their context, it returns once all of them run
+func startSlow(rb *RequestBreaker, n int) (*sync.WaitGroup, chan error) {
+	var started, done sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			_, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+				started.Done()
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(time.Second):
+					return "late", nil
+				}
+			})
+			errs <- err
+		}()
+	}
+	started.Wait()
+	return &done, errs
+}
+
+func TestCancelInflightOnTrip(t *testing.T) {
+
+	rb := NewRequestBreaker(WithCancelInflightOnTrip(true))
+
+	done, errs := startSlow(rb, 4)
+	tripBreaker(t, rb)
+	done.Wait()
+	close(errs)
+
+	for err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("in-flight request should be canceled on trip, got %v", err)
+		}
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("canceled requests belong to the old generation, breaker should stay open, got %s", rb.State())
+	}
+}
+
+func TestInflightNotCanceledByDefault(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	done, errs := startSlow(rb, 2)
+	tripBreaker(t, rb)
+	done.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("without the option in-flight requests should run to the end, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:30:40
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:06:04
+ */
+
+package circuit
+
+import "sync/atomic"
+
+// WithInitialProbe let the first request after construction through even if the breaker is open,
+// such as restored from a Memento of a dependency that has recovered since. It runs as a ProbeNow would:
+// a success closes the breaker as any probe would, a failure opens it again for a new Timeout.
+// Requests arriving while it runs are admitted as the other probes of half-open state, up to MaxRequests.
+// Like ProbeNow it does not let a request through a breaker WithManualRecoveryOnly.
+func WithInitialProbe(probe bool) Option {
+	return func(opts *Options) {
+		opts.InitialProbe = probe
+	}
+}
+
+//initialProbe report whether this is the first request of a breaker WithInitialProbe, only once
+func (rb *RequestBreaker) initialProbe() bool {
+	return rb.options.InitialProbe && atomic.LoadUint32(&rb.initialProbed) == 0 &&
+		atomic.CompareAndSwapUint32(&rb.initialProbed, 0, 1)
+}
@@ -0,0 +1,77 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+//openSeeded return a breaker with opts restored into the open state of a tripped breaker
+func openSeeded(t *testing.T, clock *fakeClock, opts ...Option) *RequestBreaker {
+	tripped := NewRequestBreaker(WithClock(clock), Timeout(time.Hour))
+	tripBreaker(t, tripped)
+
+	rb := NewRequestBreaker(append([]Option{WithClock(clock), Timeout(time.Hour)}, opts...)...)
+	rb.RestoreMemento(tripped.CreateMemento())
+	if rb.State() != StateOpen {
+		t.Fatalf("expected an open-seeded breaker, got %s", rb.State())
+	}
+	return rb
+}
+
+func TestInitialProbeAdmitsFirstRequest(t *testing.T) {
+
+	clock := newFakeClock()
+
+	rb := openSeeded(t, clock)
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Fatalf("without the option an open breaker should reject, got %v", err)
+	}
+
+	rb = openSeeded(t, clock, WithInitialProbe(true))
+	admitted := false
+	_, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+		admitted = true
+		return nil, nil
+	})
+	if err != nil || !admitted {
+		t.Fatalf("first request should be admitted as a probe, got %v", err)
+	}
+	if rb.State() != StateClosed || rb.LastTransitionReason() == "" {
+		t.Errorf("a successful initial probe should close the breaker, got %s %q", rb.State(), rb.LastTransitionReason())
+	}
+}
+
+func TestInitialProbeFailureKeepsOpen(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := openSeeded(t, clock, WithInitialProbe(true))
+
+	if _, err := rb.Do(failWork); err != errBackendDown {
+		t.Fatalf("first request should reach the backend, got %v", err)
+	}
+	if rb.State() != StateOpen || rb.RetryAfter() != time.Hour {
+		t.Errorf("a failed initial probe should open the breaker for a new Timeout, got %s %v", rb.State(), rb.RetryAfter())
+	}
+	if got := rb.LastTransitionReason(); got != "initial probe failed: "+errBackendDown.Error() {
+		t.Errorf("unexpected reason %q", got)
+	}
+
+	//只有第一个请求
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Errorf("only the first request is a forced probe, got %v", err)
+	}
+}
+
+func TestInitialProbeNotAdmittedKeepsOpen(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := openSeeded(t, clock, WithInitialProbe(true), WithEligibleProbesOnly())
+
+	if _, err := rb.Do(succeedWork); err != ErrTooManyRequests {
+		t.Fatalf("an ineligible first request should be rejected, got %v", err)
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("an initial probe that was not admitted should leave the breaker open, got %s", rb.State())
+	}
+}
@@ -0,0 +1,128 @@
+package circuit
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestIntervalJitterPerRollover(t *testing.T) {
+
+	clock := newFakeClock()
+	interval := 10 * time.Second
+	rb := NewRequestBreaker(WithClock(clock), Interval(interval), Expiry(clock.Now()),
+		WithIntervalJitter(0.2), WithJitterRand(rand.New(rand.NewSource(42))))
+
+	low, high := 8*time.Second, 12*time.Second
+	seen := map[time.Duration]bool{}
+
+	for i := 0; i < 20; i++ {
+		clock.Advance(time.Nanosecond)
+		generation := rb.generation
+		rb.Do(succeedWork) //rollover
+		if rb.generation != generation+1 {
+			t.Fatalf("rollover %d: expected a new generation", i)
+		}
+
+		got := rb.expiresAt.Sub(clock.Now())
+		if got < low || got > high {
+			t.Errorf("rollover %d: interval %v out of [%v, %v]", i, got, low, high)
+		}
+		seen[got] = true
+		clock.Advance(got)
+	}
+
+	if len(seen) < 10 {
+		t.Errorf("interval should be drawn on each rollover, got %d distinct values", len(seen))
+	}
+}
+
+func TestIntervalWithoutJitter(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Second), Expiry(clock.Now()))
+
+	clock.Advance(time.Nanosecond)
+	rb.Do(succeedWork)
+	if got := rb.expiresAt.Sub(clock.Now()); got != time.Second {
+		t.Errorf("expected exact interval, got %v", got)
+	}
+}
+
+func TestTimeoutJitterSpreadsReplicas(t *testing.T) {
+
+	clock := newFakeClock()
+	timeout := 10 * time.Second
+	replica := func(seed int64) *RequestBreaker {
+		return NewRequestBreaker(WithClock(clock), Timeout(timeout),
+			WithTimeoutJitter(0.2), WithJitterRand(rand.New(rand.NewSource(seed))))
+	}
+	a, b := replica(1), replica(2)
+	tripBreaker(t, a)
+	tripBreaker(t, b)
+
+	low, high := 8*time.Second, 12*time.Second
+	openA, openB := a.expiresAt.Sub(clock.Now()), b.expiresAt.Sub(clock.Now())
+	for _, got := range []time.Duration{openA, openB} {
+		if got < low || got > high {
+			t.Errorf("open duration %v out of [%v, %v]", got, low, high)
+		}
+	}
+	if openA == openB {
+		t.Errorf("replicas with the same Timeout should not probe together, both open for %v", openA)
+	}
+	if a.openFor != timeout {
+		t.Errorf("backoff should start from the plain Timeout, got %v", a.openFor)
+	}
+
+	if got := a.RetryAfter(); got != openA {
+		t.Errorf("RetryAfter should report the jittered expiry %v, got %v", openA, got)
+	}
+	clock.Advance(openA + time.Nanosecond)
+	if _, err := a.Do(succeedWork); err != nil {
+		t.Errorf("a probe should be admitted after the jittered expiry, got %v", err)
+	}
+}
+
+func TestIntervalJitterClamped(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Second), Expiry(clock.Now()),
+		WithIntervalJitter(3), WithJitterRand(rand.New(rand.NewSource(1))))
+	if got := rb.Config().IntervalJitter; got != maxJitter {
+		t.Fatalf("a jitter of 3 should be clamped to %v, got %v", maxJitter, got)
+	}
+
+	//周期再怎么随机也不会小于等于0，否则每个请求都会换一代
+	for i := 0; i < 50; i++ {
+		clock.Advance(time.Nanosecond)
+		rb.Do(succeedWork)
+		got := rb.expiresAt.Sub(clock.Now())
+		if got <= 0 {
+			t.Fatalf("rollover %d: the interval should stay positive, got %v", i, got)
+		}
+		clock.Advance(got)
+	}
+
+	if got := NewRequestBreaker(WithIntervalJitter(-0.5)).Config().IntervalJitter; got != 0 {
+		t.Errorf("a negative jitter should be clamped to 0, got %v", got)
+	}
+}
+
+func TestTimeoutJitterClamped(t *testing.T) {
+
+	clock := newFakeClock()
+	for seed := int64(0); seed < 20; seed++ {
+		rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second),
+			WithTimeoutJitter(2), WithJitterRand(rand.New(rand.NewSource(seed))))
+		tripBreaker(t, rb)
+
+		//打开的时长不会小于等于0，否则一打开就马上试探
+		if got := rb.expiresAt.Sub(clock.Now()); got <= 0 {
+			t.Fatalf("seed %d: the open duration should stay positive, got %v", seed, got)
+		}
+	}
+	if got := NewRequestBreaker(WithTimeoutJitter(-1)).Config().TimeoutJitter; got != 0 {
+		t.Errorf("a negative jitter should be clamped to 0, got %v", got)
+	}
+}
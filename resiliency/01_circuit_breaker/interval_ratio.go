@@ -0,0 +1,52 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:38:35
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:29:47
+ */
+
+package circuit
+
+import "time"
+
+////////////////////////////////
+/// 按统计周期的成功率打开
+/// 失败分散在整个周期里的时候，连续失败的次数一直很低，默认条件永远不会打开
+/// 每个闭合周期结束时看一眼整个周期的成功率，太低就直接打开
+////////////////////////////////
+
+// WithMinIntervalSuccessRatio opens a closed breaker when an Interval ends with a success ratio below ratio,
+// a window of fewer than minRequests requests says too little and is never judged.
+// The check runs when the first request after the end of the Interval rolls the counters over,
+// that request is then rejected. Nothing is judged during the warm-up. A ratio outside (0, 1] is ignored.
+func WithMinIntervalSuccessRatio(ratio float64, minRequests uint32) Option {
+	return func(opts *Options) {
+		//大于1的成功率永远达不到，每个周期都会打开
+		if ratio <= 0 || ratio > 1 {
+			return
+		}
+		opts.MinIntervalSuccessRatio = ratio
+		opts.MinIntervalRequests = minRequests
+	}
+}
+
+//intervalTrip judge the closing Interval by its success ratio, must be called with the mutex held before the rollover
+func (rb *RequestBreaker) intervalTrip(now time.Time) bool {
+	min := rb.options.MinIntervalSuccessRatio
+	if min <= 0 || now.Before(rb.warmUntil) {
+		return false
+	}
+
+	cnt := rb.snapshot()
+	if cnt.Requests == 0 || cnt.Requests < rb.options.MinIntervalRequests {
+		return false
+	}
+	ratio := float64(cnt.TotalSuccesses) / float64(cnt.Requests)
+	if ratio >= min {
+		return false
+	}
+	rb.because("success ratio of the last interval %.2f is below %.2f: %d of %d requests succeeded",
+		ratio, min, cnt.TotalSuccesses, cnt.Requests)
+	return true
+}
@@ -0,0 +1,80 @@
+package circuit
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//scatteredWindow run n requests in which every third one fails, a streak never gets past one
+func scatteredWindow(rb *RequestBreaker, n int) {
+	for i := 0; i < n; i++ {
+		if i%3 == 0 {
+			rb.Do(failWork)
+		} else {
+			rb.Do(succeedWork)
+		}
+	}
+}
+
+func TestIntervalSuccessRatioTripsAtBoundary(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Minute), WithMinIntervalSuccessRatio(0.9, 10))
+
+	scatteredWindow(rb, 30)
+	if rb.State() != StateClosed {
+		t.Fatalf("scattered failures should not trip within the interval, got %s", rb.State())
+	}
+
+	clock.Advance(time.Minute + time.Nanosecond)
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Errorf("the first request after a poor interval should be rejected, got %v", err)
+	}
+	if rb.State() != StateOpen {
+		t.Fatalf("a poor interval should trip at its end, got %s", rb.State())
+	}
+	if reason := rb.LastTransitionReason(); !strings.Contains(reason, "20 of 30 requests succeeded") {
+		t.Errorf("unexpected reason %q", reason)
+	}
+}
+
+func TestIntervalSuccessRatioSparesGoodAndQuietWindows(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Minute), WithMinIntervalSuccessRatio(0.5, 10))
+
+	//成功率达标
+	scatteredWindow(rb, 30)
+	clock.Advance(time.Minute + time.Nanosecond)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Fatalf("a window above the ratio should roll over, got %v in %s", err, rb.State())
+	}
+
+	//请求太少，不判断
+	rb.Do(failWork)
+	rb.Do(failWork)
+	clock.Advance(time.Minute + time.Nanosecond)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("a window under minRequests should not be judged, got %v in %s", err, rb.State())
+	}
+}
+
+func TestIntervalSuccessRatioRejectsRatioAboveOne(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Minute), WithMinIntervalSuccessRatio(1.5, 1))
+	if got := rb.Config().MinIntervalSuccessRatio; got != 0 {
+		t.Fatalf("a ratio above 1 should be ignored, got %v", got)
+	}
+
+	//全部成功的周期也不会因为达不到的成功率打开
+	rb.Do(succeedWork)
+	clock.Advance(time.Minute + time.Nanosecond)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("a perfect interval should roll over, got %v in %s", err, rb.State())
+	}
+	if got := NewRequestBreaker(WithMinIntervalSuccessRatio(1, 1)).Config().MinIntervalSuccessRatio; got != 1 {
+		t.Errorf("a ratio of 1 is valid, got %v", got)
+	}
+}
@@ -0,0 +1,41 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLastError(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second))
+
+	if rb.LastError() != nil {
+		t.Fatalf("new breaker should have no last error, got %v", rb.LastError())
+	}
+
+	errTimeout, errReset := errors.New("timeout"), errors.New("connection reset")
+	for _, err := range []error{errBackendDown, errTimeout, errReset} {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, err })
+	}
+	if rb.State() != StateOpen {
+		t.Fatalf("breaker should be open, got %s", rb.State())
+	}
+	if rb.LastError() != errReset {
+		t.Errorf("expected the most recent failure, got %v", rb.LastError())
+	}
+
+	//被拒绝的请求不是失败
+	rb.Do(succeedWork)
+	if rb.LastError() != errReset {
+		t.Errorf("a rejection should not replace the last error, got %v", rb.LastError())
+	}
+
+	clock.Advance(2 * time.Second)
+	rb.Do(succeedWork)
+	if rb.State() != StateClosed || rb.LastError() != nil {
+		t.Errorf("recovery should clear the last error, got %s %v", rb.State(), rb.LastError())
+	}
+}
@@ -0,0 +1,108 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:57:42
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:23:13
+ */
+
+package circuit
+
+import (
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 简单移动平均延迟
+/// 不需要分位数的时候，最近 N 次延迟的平均值就够了，也更便宜
+////////////////////////////////
+
+//SimpleMovingAverage track the average of the last N latencies, it is safe for concurrent use
+type SimpleMovingAverage struct {
+	mutex   sync.Mutex
+	samples []time.Duration //环形缓冲区，创建时分配好，Add不再分配内存
+	next    int
+	count   int
+	sum     time.Duration
+}
+
+//NewSimpleMovingAverage return a tracker of the last n latencies, n < 1 means 1
+func NewSimpleMovingAverage(n int) *SimpleMovingAverage {
+	if n < 1 {
+		n = 1
+	}
+	return &SimpleMovingAverage{samples: make([]time.Duration, n)}
+}
+
+//Add record one latency, the oldest one is dropped once the window is full
+func (a *SimpleMovingAverage) Add(latency time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.sum += latency - a.samples[a.next]
+	a.samples[a.next] = latency
+	a.next = (a.next + 1) % len(a.samples)
+	if a.count < len(a.samples) {
+		a.count++
+	}
+}
+
+//Average return the average of the recorded latencies, 0 if nothing is recorded
+func (a *SimpleMovingAverage) Average() time.Duration {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.count == 0 {
+		return 0
+	}
+	return a.sum / time.Duration(a.count)
+}
+
+//Count return how many latencies are in the window
+func (a *SimpleMovingAverage) Count() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.count
+}
+
+//Reset drop all recorded latencies
+func (a *SimpleMovingAverage) Reset() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	for i := range a.samples {
+		a.samples[i] = 0
+	}
+	a.next, a.count, a.sum = 0, 0, 0
+}
+
+//TripOnAverageLatency open the breaker when the average of the last n latencies exceeds threshold,
+//the average is only trusted once n requests are recorded since the last state change
+func TripOnAverageLatency(n int, threshold time.Duration) Option {
+	return func(opts *Options) {
+		opts.AverageWindow = n
+		opts.AverageThreshold = threshold
+	}
+}
+
+//AverageLatency return the average of the last latencies tracked by TripOnAverageLatency, 0 if it is not set
+func (rb *RequestBreaker) AverageLatency() time.Duration {
+	rb.lazyInit()
+	if rb.average == nil {
+		return 0
+	}
+	return rb.average.Average()
+}
+
+//averageTrip report whether the moving average is over the threshold, must be called with the mutex held
+func (rb *RequestBreaker) averageTrip() bool {
+	if rb.average == nil || rb.average.Count() < rb.options.AverageWindow {
+		return false
+	}
+	if average := rb.average.Average(); average > rb.options.AverageThreshold {
+		rb.because("average latency %v > %v over the last %d requests", average, rb.options.AverageThreshold, rb.average.Count())
+		return true
+	}
+	return false
+}
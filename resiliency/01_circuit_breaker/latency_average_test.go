@@ -0,0 +1,59 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSimpleMovingAverage(t *testing.T) {
+
+	avg := NewSimpleMovingAverage(3)
+	if avg.Average() != 0 {
+		t.Errorf("empty average should be 0, got %v", avg.Average())
+	}
+	for _, ms := range []int{10, 20, 30} {
+		avg.Add(time.Duration(ms) * time.Millisecond)
+	}
+	if got := avg.Average(); got != 20*time.Millisecond {
+		t.Errorf("expected 20ms, got %v", got)
+	}
+	//窗口满了，最旧的10ms被挤出去
+	avg.Add(40 * time.Millisecond)
+	if got := avg.Average(); got != 30*time.Millisecond {
+		t.Errorf("expected 30ms, got %v", got)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { avg.Add(time.Millisecond) }); allocs != 0 {
+		t.Errorf("Add should not allocate, got %v", allocs)
+	}
+}
+
+func TestTripOnAverageLatency(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), TripOnAverageLatency(3, 25*time.Millisecond))
+
+	call := func(latency time.Duration) {
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			clock.Advance(latency)
+			return "ok", nil
+		})
+	}
+
+	call(10 * time.Millisecond)
+	call(50 * time.Millisecond)
+	if rb.State() != StateClosed || rb.AverageLatency() != 30*time.Millisecond {
+		t.Fatalf("window is not full yet, got %s %v", rb.State(), rb.AverageLatency())
+	}
+
+	call(15 * time.Millisecond)
+	if got := rb.AverageLatency(); got != 25*time.Millisecond || rb.State() != StateClosed {
+		t.Fatalf("average at the threshold should not trip, got %s %v", rb.State(), got)
+	}
+
+	call(40 * time.Millisecond)
+	if rb.State() != StateOpen {
+		t.Errorf("average 35ms is over 25ms, breaker should open, got %s", rb.State())
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:33:48
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:29:27
+ */
+
+package circuit
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 按延迟分位数打开断路器
+/// EWMA 会把少量很慢的请求平滑掉，t-digest 用有限的内存近似统计分位数，适合盯住尾延迟
+////////////////////////////////
+
+//defaultCompression 越大越精确，质心的数量大约是它的常数倍
+const defaultCompression = 100
+
+type centroid struct {
+	mean, count float64
+}
+
+//TDigestLatency track approximate quantiles of latencies in bounded memory, it is safe for concurrent use
+type TDigestLatency struct {
+	mutex       sync.Mutex
+	compression float64
+	centroids   []centroid
+	buffer      []float64 //还没有合并进质心的样本
+	merged      float64   //已经合并进质心的样本数
+	min, max    float64
+}
+
+//NewTDigestLatency return an empty tracker, compression <= 0 means the default 100
+func NewTDigestLatency(compression float64) *TDigestLatency {
+	if compression <= 0 {
+		compression = defaultCompression
+	}
+	return &TDigestLatency{
+		compression: compression,
+		buffer:      make([]float64, 0, int(4*compression)),
+	}
+}
+
+//Add record one latency
+func (d *TDigestLatency) Add(latency time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	v := float64(latency)
+	if d.merged == 0 && len(d.buffer) == 0 {
+		d.min, d.max = v, v
+	}
+	d.min = math.Min(d.min, v)
+	d.max = math.Max(d.max, v)
+
+	d.buffer = append(d.buffer, v)
+	if len(d.buffer) == cap(d.buffer) {
+		d.merge()
+	}
+}
+
+//Count return how many latencies are recorded
+func (d *TDigestLatency) Count() uint64 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return uint64(d.merged) + uint64(len(d.buffer))
+}
+
+//Quantile return the approximate q-quantile of recorded latencies, 0 if nothing is recorded
+func (d *TDigestLatency) Quantile(q float64) time.Duration {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if len(d.buffer) > 0 {
+		d.merge()
+	}
+	if len(d.centroids) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return time.Duration(d.min)
+	}
+	if q >= 1 {
+		return time.Duration(d.max)
+	}
+
+	//在相邻两个质心的中心之间线性插值，两端分别插值到最小值和最大值
+	target := q * d.merged
+	prevMean, prevCenter, before := d.min, 0.0, 0.0
+	for _, c := range d.centroids {
+		center := before + c.count/2
+		if target < center {
+			return time.Duration(prevMean + (c.mean-prevMean)*(target-prevCenter)/(center-prevCenter))
+		}
+		prevMean, prevCenter = c.mean, center
+		before += c.count
+	}
+	if d.merged == prevCenter {
+		return time.Duration(d.max)
+	}
+	return time.Duration(prevMean + (d.max-prevMean)*(target-prevCenter)/(d.merged-prevCenter))
+}
+
+//Reset drop all recorded latencies
+func (d *TDigestLatency) Reset() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.centroids = d.centroids[:0]
+	d.buffer = d.buffer[:0]
+	d.merged = 0
+}
+
+//merge fold the buffer into the centroids, must be called with the mutex held
+func (d *TDigestLatency) merge() {
+
+	points := make([]centroid, 0, len(d.centroids)+len(d.buffer))
+	points = append(points, d.centroids...)
+	for _, v := range d.buffer {
+		points = append(points, centroid{mean: v, count: 1})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].mean < points[j].mean })
+
+	d.merged += float64(len(d.buffer))
+	d.buffer = d.buffer[:0]
+
+	//靠近两端的质心只能很小，中间的可以很大，质心总数因此有上限
+	merged := d.centroids[:0]
+	cur, before := points[0], 0.0
+	for _, p := range points[1:] {
+		q := (before + (cur.count+p.count)/2) / d.merged
+		if cur.count+p.count <= 4*d.merged*q*(1-q)/d.compression {
+			cur.mean += (p.mean - cur.mean) * p.count / (cur.count + p.count)
+			cur.count += p.count
+			continue
+		}
+		merged = append(merged, cur)
+		before += cur.count
+		cur = p
+	}
+	d.centroids = append(merged, cur)
+}
+
+//TripOnLatencyQuantile open the breaker when the q-quantile of latencies in current interval exceeds threshold,
+//e.g. TripOnLatencyQuantile(0.99, 500*time.Millisecond). The quantile is only trusted once 1/(1-q) requests are recorded,
+//q outside (0, 1) is ignored
+func TripOnLatencyQuantile(q float64, threshold time.Duration) Option {
+	return func(opts *Options) {
+		//q 为1时永远等不够请求数，大于1或者小于等于0没有意义
+		if q <= 0 || q >= 1 {
+			return
+		}
+		opts.LatencyQuantile = q
+		opts.LatencyThreshold = threshold
+	}
+}
+
+//latencyTrip report whether the tracked latency quantile or average is over the threshold, must be called with the mutex held
+func (rb *RequestBreaker) latencyTrip() bool {
+	if rb.now().Before(rb.warmUntil) {
+		return false
+	}
+	return rb.quantileTrip() || rb.averageTrip()
+}
+
+func (rb *RequestBreaker) quantileTrip() bool {
+	if rb.latency == nil {
+		return false
+	}
+	q := rb.options.LatencyQuantile
+	if float64(rb.latency.Count()) < 1/(1-q) {
+		return false
+	}
+	if latency := rb.latency.Quantile(q); latency > rb.options.LatencyThreshold {
+		rb.because("%g quantile of latency %v > %v over %d requests", q, latency, rb.options.LatencyThreshold, rb.latency.Count())
+		return true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+package circuit
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTDigestLatencyQuantiles(t *testing.T) {
+
+	d := NewTDigestLatency(0)
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 100000; i++ {
+		d.Add(time.Duration(rnd.Intn(1000)) * time.Millisecond)
+	}
+
+	for _, q := range []float64{0.5, 0.9, 0.99} {
+		want := time.Duration(q * float64(time.Second))
+		if got := d.Quantile(q); got < want-10*time.Millisecond || got > want+10*time.Millisecond {
+			t.Errorf("quantile %v: want about %v, got %v", q, want, got)
+		}
+	}
+	if len(d.centroids) > 10*defaultCompression {
+		t.Errorf("centroids should stay bounded, got %d", len(d.centroids))
+	}
+}
+
+func TestTDigestLatencyConcurrentAdd(t *testing.T) {
+
+	d := NewTDigestLatency(50)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				d.Add(time.Millisecond)
+				d.Quantile(0.99)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if d.Count() != 8000 || d.Quantile(0.5) != time.Millisecond {
+		t.Errorf("unexpected digest: count %d, median %v", d.Count(), d.Quantile(0.5))
+	}
+}
+
+//slowEvery return work taking 1s on every nth call and 10ms otherwise on clock
+func slowEvery(clock *fakeClock, n int) func(i int) func(ctx context.Context) (interface{}, error) {
+	return func(i int) func(ctx context.Context) (interface{}, error) {
+		return func(ctx context.Context) (interface{}, error) {
+			if i%n == n-1 {
+				clock.Advance(time.Second)
+			} else {
+				clock.Advance(10 * time.Millisecond)
+			}
+			return nil, nil
+		}
+	}
+}
+
+func TestTripOnLatencyQuantile(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Hour),
+		TripOnLatencyQuantile(0.99, 500*time.Millisecond))
+
+	//2%的请求要1秒，中位数一直是10ms，p99超过阈值
+	work := slowEvery(clock, 50)
+	for i := 0; i < 1000 && rb.State() == StateClosed; i++ {
+		if median := rb.latency.Quantile(0.5); median > 500*time.Millisecond {
+			t.Fatalf("median should stay under threshold, got %v", median)
+		}
+		if _, err := rb.Do(work(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if rb.State() != StateOpen {
+		t.Fatal("breaker should trip on p99 latency")
+	}
+	if c := rb.Counts(); c.TotalFailures != 0 {
+		t.Errorf("no request failed, got %+v", c)
+	}
+}
+
+func TestLatencyQuantileUnderThreshold(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(time.Hour),
+		TripOnLatencyQuantile(0.99, 500*time.Millisecond))
+
+	//只有0.5%的请求慢，p99仍然很快
+	work := slowEvery(clock, 200)
+	for i := 0; i < 1000; i++ {
+		rb.Do(work(i))
+	}
+
+	if rb.State() != StateClosed {
+		t.Errorf("p99 is under threshold, breaker should stay closed, p99 %v", rb.latency.Quantile(0.99))
+	}
+}
+
+func TestTripOnLatencyQuantileRejectsInvalidQ(t *testing.T) {
+
+	for _, q := range []float64{0, -0.5, 1, 1.5} {
+		rb := NewRequestBreaker(TripOnLatencyQuantile(q, time.Millisecond))
+		if rb.latency != nil || rb.Config().LatencyQuantile != 0 {
+			t.Errorf("q=%g outside (0, 1) should be ignored", q)
+		}
+	}
+	if rb := NewRequestBreaker(TripOnLatencyQuantile(0.99, time.Millisecond)); rb.latency == nil {
+		t.Error("a valid q should track latencies")
+	}
+}
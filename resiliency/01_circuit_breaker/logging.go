@@ -0,0 +1,78 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:09:03
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:27:44
+ */
+
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+//WithLogger log the lifecycle of breaker: trips at Warn, other transitions at Info, rejections at Debug
+//and panics of user callbacks at Error
+func WithLogger(logger *slog.Logger) Option {
+	return func(opts *Options) {
+		opts.Logger = logger
+	}
+}
+
+//logTransition log a state change and its reason with the counters of the generation it ends
+func (rb *RequestBreaker) logTransition(from, to State, reason string, cnt Counts) {
+	logger := rb.options.Logger
+	if logger == nil {
+		return
+	}
+
+	level, msg := slog.LevelInfo, "circuit breaker state changed"
+	if to == StateOpen {
+		level, msg = slog.LevelWarn, "circuit breaker tripped"
+	}
+	if rb.options.DryRun {
+		msg += " (dry run)"
+	}
+
+	logger.LogAttrs(context.Background(), level, msg,
+		slog.String("name", rb.options.Name),
+		slog.String("from", from.String()),
+		slog.String("to", to.String()),
+		slog.String("reason", reason),
+		slog.Group("counts",
+			slog.Uint64("requests", uint64(cnt.Requests)),
+			slog.Uint64("total_successes", uint64(cnt.TotalSuccesses)),
+			slog.Uint64("total_failures", uint64(cnt.TotalFailures)),
+			slog.Uint64("consecutive_successes", uint64(cnt.ConsecutiveSuccesses)),
+			slog.Uint64("consecutive_failures", uint64(cnt.ConsecutiveFailures)),
+		))
+}
+
+//logRejection log a short-circuited request
+func (rb *RequestBreaker) logRejection(err error) {
+	logger := rb.options.Logger
+	if logger == nil {
+		return
+	}
+
+	logger.LogAttrs(context.Background(), slog.LevelDebug, "circuit breaker rejected request",
+		slog.String("name", rb.options.Name),
+		slog.String("state", rb.state.String()),
+		slog.String("error", err.Error()))
+}
+
+//logPanic log a panic recovered from a user callback
+func (rb *RequestBreaker) logPanic(callback string, recovered interface{}) {
+	logger := rb.options.Logger
+	if logger == nil {
+		return
+	}
+
+	logger.LogAttrs(context.Background(), slog.LevelError, "circuit breaker callback panicked",
+		slog.String("name", rb.options.Name),
+		slog.String("callback", callback),
+		slog.String("panic", fmt.Sprint(recovered)))
+}
@@ -0,0 +1,90 @@
+package circuit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+//captureHandler keep every record logged through it
+type captureHandler struct {
+	mutex   sync.Mutex
+	records []slog.Record
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+func recordAttrs(r slog.Record) map[string]slog.Value {
+	attrs := map[string]slog.Value{}
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value
+		return true
+	})
+	return attrs
+}
+
+func TestLoggerTripAndRecover(t *testing.T) {
+
+	h := &captureHandler{}
+	rb := NewRequestBreaker(ActionName("logged"), Timeout(time.Millisecond), WithLogger(slog.New(h)))
+
+	tripBreaker(t, rb)
+	rb.Do(succeedWork) //rejected
+	time.Sleep(2 * time.Millisecond)
+	rb.Do(succeedWork) //open -> half-open -> closed
+
+	type want struct {
+		level    slog.Level
+		from, to string
+	}
+	wants := []want{
+		{slog.LevelWarn, "closed", "open"},
+		{slog.LevelDebug, "", ""},
+		{slog.LevelInfo, "open", "half-open"},
+		{slog.LevelInfo, "half-open", "closed"},
+	}
+
+	if len(h.records) != len(wants) {
+		t.Fatalf("expected %d records, got %d", len(wants), len(h.records))
+	}
+
+	for i, w := range wants {
+		r := h.records[i]
+		attrs := recordAttrs(r)
+		if r.Level != w.level {
+			t.Errorf("record %d: expected level %s, got %s", i, w.level, r.Level)
+		}
+		if attrs["name"].String() != "logged" {
+			t.Errorf("record %d: missing name, got %v", i, attrs)
+		}
+		if w.level == slog.LevelDebug {
+			if attrs["state"].String() != "open" || attrs["error"].String() != ErrServiceUnavailable.Error() {
+				t.Errorf("record %d: unexpected rejection attrs %v", i, attrs)
+			}
+			continue
+		}
+		if attrs["from"].String() != w.from || attrs["to"].String() != w.to {
+			t.Errorf("record %d: expected %s -> %s, got %v", i, w.from, w.to, attrs)
+		}
+	}
+
+	counts := recordAttrs(h.records[0])["counts"].Group()
+	for _, a := range counts {
+		if a.Key == "consecutive_failures" && a.Value.Uint64() != 3 {
+			t.Errorf("trip should log the counts that caused it, got %v", counts)
+		}
+	}
+}
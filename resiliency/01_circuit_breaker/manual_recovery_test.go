@@ -0,0 +1,32 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualRecoveryOnly(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithManualRecoveryOnly(true))
+	tripBreaker(t, rb)
+
+	if got := rb.RetryAfter(); got != RetryNever {
+		t.Errorf("RetryAfter should say the breaker never recovers by itself, got %v", got)
+	}
+	clock.Advance(24 * time.Hour)
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Fatalf("breaker should not try to recover by itself, got %v", err)
+	}
+	if rb.State() != StateOpen || rb.AllowRequest() {
+		t.Fatalf("breaker should stay open, got %v", rb.State())
+	}
+
+	rb.Reset()
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("Reset should close the breaker, got %v %v", err, rb.State())
+	}
+	if got := rb.RetryAfter(); got != 0 {
+		t.Errorf("closed breaker should not ask to wait, got %v", got)
+	}
+}
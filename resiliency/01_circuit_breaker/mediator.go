@@ -0,0 +1,139 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:56:52
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:06:56
+ */
+
+package circuit
+
+import (
+	"fmt"
+	"sync"
+)
+
+////////////////////////////////
+/// 中介者模式
+/// 同一个故障域(同一个数据库、同一个网络分区)后面的断路器互相不认识，只和中介者打交道
+/// 一个打开了，中介者提醒其他的断路器小心一点，或者直接把它们一起打开
+////////////////////////////////
+
+//Coupling decide what Mediator does to the peers of a tripped breaker
+type Coupling int
+
+const (
+	//CouplingAdvisory move closed peers to half-open, so only probes go through until they prove healthy
+	CouplingAdvisory Coupling = iota
+	//CouplingForced open the closed peers
+	CouplingForced
+)
+
+//Mediator coordinate breakers behind a shared failure domain
+type Mediator struct {
+	mutex     sync.Mutex
+	coupling  Coupling
+	threshold int
+	members   map[*RequestBreaker]struct{}
+	acting    int        //还没做完的 tripped
+	idle      *sync.Cond //acting 归零时广播
+}
+
+// NewMediator returns a mediator which acts on the closed members once threshold of them are open,
+// a threshold of 0 or 1 acts on the first trip.
+func NewMediator(coupling Coupling, threshold int) *Mediator {
+	m := &Mediator{coupling: coupling, threshold: threshold, members: make(map[*RequestBreaker]struct{})}
+	m.idle = sync.NewCond(&m.mutex)
+	return m
+}
+
+// Wait blocks until the mediator has acted on every trip of its members seen so far,
+// including the trips it caused itself with CouplingForced. The mediator acts in the background,
+// call Wait before checking the peers of a tripped breaker or before shutting down.
+func (m *Mediator) Wait() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for m.acting > 0 {
+		m.idle.Wait()
+	}
+}
+
+//Register add rb to the failure domain, call the returned func to remove it
+func (m *Mediator) Register(rb *RequestBreaker) (unregister func()) {
+	m.mutex.Lock()
+	m.members[rb] = struct{}{}
+	m.mutex.Unlock()
+
+	unsubscribe := rb.Events().Subscribe(ObserverFunc[StateChange](func(change StateChange) {
+		if change.To == StateOpen && !change.DryRun {
+			//通知时断路器持有自己的锁，去改别的断路器可能和它们互相等待，所以另起goroutine
+			m.mutex.Lock()
+			m.acting++
+			m.mutex.Unlock()
+			go func() {
+				defer m.done()
+				m.tripped(rb)
+			}()
+		}
+	}))
+
+	return func() {
+		unsubscribe()
+		m.mutex.Lock()
+		delete(m.members, rb)
+		m.mutex.Unlock()
+	}
+}
+
+//done mark one tripped finished
+func (m *Mediator) done() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.acting--; m.acting == 0 {
+		m.idle.Broadcast()
+	}
+}
+
+//tripped act on the peers of from once enough members are open
+func (m *Mediator) tripped(from *RequestBreaker) {
+	m.mutex.Lock()
+	peers := make([]*RequestBreaker, 0, len(m.members))
+	for rb := range m.members {
+		peers = append(peers, rb)
+	}
+	m.mutex.Unlock()
+
+	//WithDryRun 的成员只是假装打开，不算数
+	open := 0
+	for _, rb := range peers {
+		if rb.enforced() == StateOpen {
+			open++
+		}
+	}
+	if open < m.threshold {
+		return
+	}
+
+	to := StateHalfOpen
+	if m.coupling == CouplingForced {
+		to = StateOpen
+	}
+	//只动闭合的断路器，被动过的断路器再发出的事件不会循环
+	reason := fmt.Sprintf("%d members of the mediator are open, the last is %s", open, from.Name())
+	for _, rb := range peers {
+		if rb != from {
+			rb.moveFrom(StateClosed, to, reason)
+		}
+	}
+}
+
+//moveFrom change rb to state to for reason if it is in state from
+func (rb *RequestBreaker) moveFrom(from, to State, reason string) {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	if rb.state == from {
+		rb.reason = reason
+		rb.changeStateTo(to)
+	}
+}
@@ -0,0 +1,90 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+//eventually wait up to a second for rb to reach want
+func eventually(t *testing.T, rb *RequestBreaker, want State) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for rb.State() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s, got %s", want, rb.State())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMediatorAdvisory(t *testing.T) {
+
+	m := NewMediator(CouplingAdvisory, 1)
+	primary, replica := NewRequestBreaker(ActionName("primary")), NewRequestBreaker(ActionName("replica"))
+	m.Register(primary)
+	m.Register(replica)
+
+	tripBreaker(t, primary)
+
+	//同一个故障域的断路器进入半开，只放行试探请求
+	m.Wait()
+	if replica.State() != StateHalfOpen {
+		t.Errorf("peer should turn half-open, got %s", replica.State())
+	}
+	if primary.State() != StateOpen {
+		t.Errorf("tripped breaker should stay open, got %s", primary.State())
+	}
+}
+
+func TestMediatorForcedAfterThreshold(t *testing.T) {
+
+	m := NewMediator(CouplingForced, 2)
+	a, b, c := NewRequestBreaker(), NewRequestBreaker(), NewRequestBreaker()
+	m.Register(a)
+	m.Register(b)
+	m.Register(c)
+
+	tripBreaker(t, a)
+	m.Wait()
+	if c.State() != StateClosed {
+		t.Fatalf("one trip is below the threshold, got %s", c.State())
+	}
+
+	//两个同时打开，说明是整个故障域出了问题
+	tripBreaker(t, b)
+	m.Wait()
+	if c.State() != StateOpen {
+		t.Errorf("peer should be opened, got %s", c.State())
+	}
+}
+
+func TestMediatorUnregister(t *testing.T) {
+
+	m := NewMediator(CouplingForced, 1)
+	a, b := NewRequestBreaker(), NewRequestBreaker()
+	m.Register(a)
+	m.Register(b)()
+
+	tripBreaker(t, a)
+	m.Wait()
+	if b.State() != StateClosed {
+		t.Errorf("unregistered breaker should be left alone, got %s", b.State())
+	}
+}
+
+func TestMediatorIgnoresDryRunMembers(t *testing.T) {
+
+	m := NewMediator(CouplingForced, 2)
+	shadow, a, b := NewRequestBreaker(WithDryRun(true)), NewRequestBreaker(), NewRequestBreaker()
+	tripBreaker(t, shadow)
+	tripBreaker(t, a)
+	m.Register(shadow)
+	m.Register(a)
+	m.Register(b)
+
+	//只有一个成员真的打开，影子状态打开的不算
+	m.tripped(a)
+	if b.State() != StateClosed {
+		t.Errorf("a dry-run member should not count as open, got %s", b.State())
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:11:21
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+import "time"
+
+////////////////////////////////
+/// 备忘录模式
+/// 断路器是发起人(originator)，备忘录保存它某一时刻的完整状态
+/// 备忘录对外不透明，创建之后不能修改，只能交还给断路器恢复
+////////////////////////////////
+
+//Memento is an opaque, immutable capture of breaker state
+type Memento struct {
+	state    State
+	preState State
+	counts   Counts
+	expiry   time.Time
+	openFor  time.Duration
+}
+
+//counterRestorer is implemented by counters that can be put back to a snapshot
+type counterRestorer interface {
+	Restore(Counts)
+}
+
+//Restore put the counters back to snapshot
+func (c *Counts) Restore(snapshot Counts) {
+	clock := c.clock
+	*c = snapshot
+	c.clock = clock
+}
+
+//CreateMemento capture the full state of breaker
+func (rb *RequestBreaker) CreateMemento() Memento {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	return Memento{
+		state:    rb.state,
+		preState: rb.preState,
+		counts:   rb.snapshot(),
+		expiry:   rb.expiresAt,
+		openFor:  rb.openFor,
+	}
+}
+
+// RestoreMemento puts the breaker back to the state captured in m.
+// Restoring is not a transition, OnStateChanged and observers are not notified.
+// Probes in flight are not restored, a half-open breaker starts a new round of probes.
+// A custom ICounter that does not implement Restore(Counts) is reset instead.
+func (rb *RequestBreaker) RestoreMemento(m Memento) {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	//恢复前的这段时间仍然算在原来的状态上
+	now := rb.now()
+	rb.inState[rb.state] += now.Sub(rb.stateSince)
+	rb.stateSince = now
+
+	rb.state = m.state
+	rb.preState = m.preState
+	rb.openFor = m.openFor
+	//快照里的探测请求已经不会归还名额了，从零开始
+	rb.probes = 0
+	rb.setExpiry(m.expiry)
+
+	if restorer, ok := rb.counter().(counterRestorer); ok {
+		restorer.Restore(m.counts)
+	} else {
+		rb.counter().Reset()
+	}
+	//恢复也开始新的一代，代数只往前走，恢复前放行的请求结果不会记到恢复的计数上
+	rb.nextGeneration()
+	rb.signal()
+}
@@ -0,0 +1,96 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMementoRestoresFullState(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Minute),
+		WithAdaptiveTimeout(time.Minute, time.Hour, 2), MaxRequests(3), WithShoulderHalfToOpen(3),
+		WithBreakCondition(func(current State, cnt Counts) bool {
+			return current == StateHalfOpen || cnt.ConsecutiveFailures > 2
+		}))
+
+	//mid-cycle: tripped once, now half-open with one good probe
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Minute)
+	rb.Do(succeedWork)
+	if rb.State() != StateHalfOpen {
+		t.Fatalf("expected half-open, got %s", rb.State())
+	}
+
+	m := rb.CreateMemento()
+	wantCounts := rb.Counts()
+	capturedGeneration := rb.generation
+	wantExpiry := rb.expiresAt
+	wantOpenFor := rb.openFor
+
+	//mutate: fail the probe and trip again, with a longer adaptive timeout
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	if rb.State() != StateOpen || rb.generation == capturedGeneration {
+		t.Fatalf("breaker should have tripped again, got %s", rb.State())
+	}
+
+	tripped := rb.generation
+	rb.RestoreMemento(m)
+
+	if rb.State() != StateHalfOpen || rb.preState != StateOpen {
+		t.Errorf("expected open -> half-open, got %s -> %s", rb.preState, rb.state)
+	}
+	//代数不会回到快照时的那一代
+	if rb.generation <= tripped {
+		t.Errorf("restoring should start a generation after %d, got %d", tripped, rb.generation)
+	}
+	if got := rb.Counts(); got != wantCounts {
+		t.Errorf("expected counts %+v, got %+v", wantCounts, got)
+	}
+	if !rb.expiresAt.Equal(wantExpiry) || rb.openFor != wantOpenFor || rb.probes != 0 {
+		t.Errorf("timing not restored: expiry %v openFor %v probes %d", rb.expiresAt, rb.openFor, rb.probes)
+	}
+
+	//the restored breaker carries on from where it was captured
+	rb.Do(succeedWork)
+	rb.Do(succeedWork)
+	if rb.State() != StateClosed {
+		t.Errorf("two more good probes should close, got %s", rb.State())
+	}
+
+	//the memento is not affected by what happened after it was restored
+	rb.RestoreMemento(m)
+	if rb.State() != StateHalfOpen || rb.Counts() != wantCounts {
+		t.Error("memento should be reusable and unchanged")
+	}
+}
+
+func TestMementoRestoreMidProbe(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Minute), MaxRequests(1))
+
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Minute)
+
+	//capture while the only probe is in flight
+	token, err := rb.Prepare()
+	if err != nil {
+		t.Fatalf("probe should be admitted, got %v", err)
+	}
+	m := rb.CreateMemento()
+	rb.RestoreMemento(m)
+	rb.Commit(token, nil)
+
+	if rb.State() != StateHalfOpen {
+		t.Fatalf("expected half-open, got %s", rb.State())
+	}
+	//the stale probe cannot give its slot back, the restored breaker must not wait for it
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Fatalf("a new probe should be admitted after restore, got %v", err)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("a good probe should close, got %s", rb.State())
+	}
+}
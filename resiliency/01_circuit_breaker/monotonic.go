@@ -0,0 +1,52 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:29:21
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:29:21
+ */
+
+package circuit
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+////////////////////////////////
+/// 时钟回拨
+/// 注入的时钟或者系统的墙上时间(NTP校时)可能往回走，到期时间就会迟迟不到
+/// 断路器用自己的时间：往回走的那一段当作时间停住了，之后照常前进
+/// time.Now() 带单调时钟读数，Sub/Before 用的就是单调时钟，只有没有单调读数的时钟才会回拨
+////////////////////////////////
+
+// now return the time of breaker, it never goes back. A clock that steps back is treated as
+// standing still, how far it stepped back is added to every later reading.
+// It must be called with the mutex held
+func (rb *RequestBreaker) now() time.Time {
+	skew := time.Duration(atomic.LoadInt64(&rb.skew))
+	now := rb.options.Clock.Now().Add(skew)
+	if now.Before(rb.lastNow) {
+		skew += rb.lastNow.Sub(now)
+		atomic.StoreInt64(&rb.skew, int64(skew))
+		now = rb.lastNow
+	}
+	rb.lastNow = now
+	atomic.StoreInt64(&rb.seen, int64(now.Sub(rb.epoch)))
+	return now
+}
+
+//sinceEpoch return the time of breaker as an offset from its creation without lock,
+//ok is false if the clock stepped back since the last reading under the mutex
+func (rb *RequestBreaker) sinceEpoch() (d int64, ok bool) {
+	d = int64(rb.options.Clock.Now().Sub(rb.epoch)) + atomic.LoadInt64(&rb.skew)
+	return d, d >= atomic.LoadInt64(&rb.seen)
+}
+
+//elapsed return the time since start on clock, 0 if the clock stepped back
+func elapsed(clock Clock, start time.Time) time.Duration {
+	if d := clock.Now().Sub(start); d > 0 {
+		return d
+	}
+	return 0
+}
@@ -0,0 +1,62 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClockSteppingBackWhileOpen(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(10*time.Second))
+	tripBreaker(t, rb)
+
+	clock.Advance(4 * time.Second)
+	rb.Do(succeedWork) //rejected，断路器看到4s
+	clock.Advance(-time.Hour)
+
+	//回拨的一小时当作时间停住了
+	if got := rb.RetryAfter(); got != 6*time.Second {
+		t.Errorf("stepping back should not extend the timeout, RetryAfter %v", got)
+	}
+	for state, d := range rb.StateDurations() {
+		if d < 0 {
+			t.Errorf("negative time in %s: %v", state, d)
+		}
+	}
+
+	clock.Advance(6*time.Second + time.Nanosecond)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("breaker should recover once the timeout elapsed, got %v %s", err, rb.State())
+	}
+}
+
+func TestClockSteppingBackWhileClosed(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Interval(10*time.Second), Expiry(clock.Now().Add(10*time.Second)),
+		TripOnAverageLatency(1, time.Second))
+
+	clock.Advance(5 * time.Second)
+	rb.Do(succeedWork)
+	generation := rb.generation
+
+	//请求执行期间时钟回拨，延迟算作0，不是负数
+	rb.Do(func(ctx context.Context) (interface{}, error) {
+		clock.Advance(-time.Hour)
+		return nil, nil
+	})
+	if avg := rb.AverageLatency(); avg != 0 {
+		t.Errorf("latency across a step back should be 0, got %v", avg)
+	}
+
+	clock.Advance(5*time.Second + time.Nanosecond)
+	rb.Do(succeedWork)
+	if rb.generation != generation+1 {
+		t.Errorf("interval should roll over on time after a step back, generation %d -> %d", generation, rb.generation)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("expected closed, got %s", rb.State())
+	}
+}
@@ -0,0 +1,269 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 15:57:55
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:04:02
+ */
+
+package circuit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 多路断路器,门面模式
+/// 网关后面有很多上游服务，每个上游一个断路器
+/// 由 keyFn 从请求中算出上游的key，再找到对应的断路器
+////////////////////////////////
+
+//KeyFunc resolve the breaker key from a request
+type KeyFunc func(req interface{}) string
+
+//BreakerFactory create a breaker for key
+type BreakerFactory func(key string) *RequestBreaker
+
+//MultiBreaker pick a breaker for each request by key
+type MultiBreaker struct {
+	keyFn   KeyFunc
+	factory BreakerFactory
+	shards  []breakerShard
+	opts    []MultiOption
+	//每个分片最多保留的key数和空闲key的存活时间，0表示不限制
+	maxPerShard int
+	keyTTL      time.Duration
+	clock       Clock
+	cohort      *cohortRecovery //分批恢复，为空表示所有key一起恢复
+}
+
+//breakerShard own the breakers whose key hashes to it
+//不同分片的key互不阻塞，同一个key总落在同一个分片，创建仍然是串行的
+type breakerShard struct {
+	mutex    sync.Mutex
+	breakers map[string]*RequestBreaker
+	//按最近使用排序的key，最近的在前，只在限制了key数或存活时间时维护
+	lru   *list.List
+	elems map[string]*list.Element
+}
+
+type keyEntry struct {
+	key      string
+	lastUsed time.Time
+}
+
+//MultiOption set MultiBreaker
+type MultiOption func(mb *MultiBreaker)
+
+// WithMaxKeys keeps at most n breakers, the least recently used one is evicted when there are more.
+// The limit is split evenly over the shards, each shard keeps at most ceil(n/shards) breakers.
+// Breakers which are not closed are pinned and never evicted, so the state of an incident is not lost,
+// the MultiBreaker may hold more than n breakers while many of them are open.
+// An evicted breaker is dropped with StopHooks, a caller still holding it runs its hooks synchronously.
+func WithMaxKeys(n int) MultiOption {
+	return func(mb *MultiBreaker) {
+		if n > 0 {
+			mb.maxPerShard = (n + len(mb.shards) - 1) / len(mb.shards)
+		}
+	}
+}
+
+//WithKeyTTL evict a breaker which has not been used for ttl, breakers which are not closed are pinned just like WithMaxKeys
+func WithKeyTTL(ttl time.Duration) MultiOption {
+	return func(mb *MultiBreaker) {
+		mb.keyTTL = ttl
+	}
+}
+
+//WithKeyClock set the clock telling how long a key has been idle for WithKeyTTL
+func WithKeyClock(clock Clock) MultiOption {
+	return func(mb *MultiBreaker) {
+		mb.clock = clock
+	}
+}
+
+//NewMultiBreaker return a MultiBreaker guarded by a single lock, breakers are created lazily by factory
+func NewMultiBreaker(keyFn KeyFunc, factory BreakerFactory, opts ...MultiOption) *MultiBreaker {
+	return NewShardedMultiBreaker(keyFn, factory, 1, opts...)
+}
+
+//NewShardedMultiBreaker return a MultiBreaker whose breakers are spread over shards locks,
+//use it for high-cardinality keys, shards < 1 is treated as 1
+func NewShardedMultiBreaker(keyFn KeyFunc, factory BreakerFactory, shards int, opts ...MultiOption) *MultiBreaker {
+	if shards < 1 {
+		shards = 1
+	}
+	mb := &MultiBreaker{
+		keyFn:   keyFn,
+		factory: factory,
+		shards:  make([]breakerShard, shards),
+		opts:    opts,
+		clock:   systemClock{},
+	}
+	for _, setOption := range opts {
+		setOption(mb)
+	}
+	for i := range mb.shards {
+		mb.shards[i].breakers = make(map[string]*RequestBreaker)
+		if mb.evicting() {
+			mb.shards[i].lru = list.New()
+			mb.shards[i].elems = make(map[string]*list.Element)
+		}
+	}
+	return mb
+}
+
+func (mb *MultiBreaker) evicting() bool {
+	return mb.maxPerShard > 0 || mb.keyTTL > 0
+}
+
+//shard return the shard key belongs to, hashed by FNV-1a
+func (mb *MultiBreaker) shard(key string) *breakerShard {
+	if len(mb.shards) == 1 {
+		return &mb.shards[0]
+	}
+	return &mb.shards[keyHash(key)%uint32(len(mb.shards))]
+}
+
+//keyHash hash key by FNV-1a
+func keyHash(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
+
+//Breaker return the breaker for key, create it on first use
+func (mb *MultiBreaker) Breaker(key string) *RequestBreaker {
+	var evicted []*RequestBreaker
+	//放开分片锁之后再停被逐出的断路器的钩子，排队的钩子里可能又用到 MultiBreaker
+	defer func() {
+		for _, old := range evicted {
+			old.StopHooks()
+		}
+	}()
+
+	sh := mb.shard(key)
+	sh.mutex.Lock()
+	defer sh.mutex.Unlock()
+
+	rb, ok := sh.breakers[key]
+	if !ok {
+		rb = mb.factory(key)
+		sh.breakers[key] = rb
+		if mb.cohort != nil {
+			mb.cohort.watch(key, rb)
+		}
+	}
+	if mb.evicting() {
+		now := mb.clock.Now()
+		sh.touch(key, now)
+		evicted = mb.evict(sh, now)
+	}
+	return rb
+}
+
+//touch move key to the front of lru, must be called with the mutex of shard held
+func (sh *breakerShard) touch(key string, now time.Time) {
+	if e, ok := sh.elems[key]; ok {
+		e.Value.(*keyEntry).lastUsed = now
+		sh.lru.MoveToFront(e)
+		return
+	}
+	sh.elems[key] = sh.lru.PushFront(&keyEntry{key: key, lastUsed: now})
+}
+
+// evict drops idle and least recently used breakers of sh, but never the most recent key or a breaker which is not closed.
+// It returns the dropped breakers, whose hooks the caller stops once the mutex of sh is released.
+func (mb *MultiBreaker) evict(sh *breakerShard, now time.Time) (evicted []*RequestBreaker) {
+	for e := sh.lru.Back(); e != nil && e != sh.lru.Front(); {
+		prev := e.Prev()
+		entry := e.Value.(*keyEntry)
+		idle := mb.keyTTL > 0 && now.Sub(entry.lastUsed) >= mb.keyTTL
+		over := mb.maxPerShard > 0 && len(sh.breakers) > mb.maxPerShard
+		if !idle && !over {
+			//越往前越新，没有空闲的也没有超出，后面不用看了
+			return evicted
+		}
+		if rb := sh.breakers[entry.key]; rb.State() == StateClosed {
+			sh.lru.Remove(e)
+			delete(sh.elems, entry.key)
+			delete(sh.breakers, entry.key)
+			evicted = append(evicted, rb)
+		}
+		e = prev
+	}
+	return evicted
+}
+
+//Do run work through the breaker resolved from req
+func (mb *MultiBreaker) Do(req interface{}, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	key := mb.keyFn(req)
+	rb := mb.Breaker(key)
+	if mb.cohort != nil && !mb.cohort.admit(key, rb) {
+		return nil, ErrServiceUnavailable
+	}
+	return rb.Do(work)
+}
+
+//Summary aggregate the breakers of a MultiBreaker or a Registry
+type Summary struct {
+	Closed, HalfOpen, Open int
+	//HealthScore is the mean health score of all breakers, WorstHealthScore the lowest one,
+	//both are 1 when there is no breaker
+	HealthScore, WorstHealthScore float64
+}
+
+//Summary return the state counts and health of all breakers, it only reads and is cheap to poll
+func (mb *MultiBreaker) Summary() Summary {
+
+	var sum summarizer
+	for i := range mb.shards {
+		sh := &mb.shards[i]
+		sh.mutex.Lock()
+		for _, rb := range sh.breakers {
+			sum.add(rb)
+		}
+		sh.mutex.Unlock()
+	}
+	return sum.result()
+}
+
+//summarizer add up breakers into a Summary
+type summarizer struct {
+	sum   Summary
+	total float64
+	n     int
+}
+
+func (s *summarizer) add(rb *RequestBreaker) {
+	switch rb.State() {
+	case StateClosed:
+		s.sum.Closed++
+	case StateHalfOpen:
+		s.sum.HalfOpen++
+	case StateOpen:
+		s.sum.Open++
+	}
+	score := rb.HealthScore()
+	s.total += score
+	if s.n == 0 || score < s.sum.WorstHealthScore {
+		s.sum.WorstHealthScore = score
+	}
+	s.n++
+}
+
+func (s *summarizer) result() Summary {
+	sum := s.sum
+	sum.HealthScore, sum.WorstHealthScore = 1, 1
+	if s.n > 0 {
+		sum.HealthScore = s.total / float64(s.n)
+		sum.WorstHealthScore = s.sum.WorstHealthScore
+	}
+	return sum
+}
@@ -0,0 +1,100 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func tracked(mb *MultiBreaker) map[string]bool {
+	keys := map[string]bool{}
+	for key := range mb.shards[0].breakers {
+		keys[key] = true
+	}
+	return keys
+}
+
+func TestMultiBreakerEvictsLeastRecentlyUsed(t *testing.T) {
+
+	mb := NewMultiBreaker(nil, func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key))
+	}, WithMaxKeys(3))
+
+	mb.Breaker("a")
+	mb.Breaker("b")
+	mb.Breaker("c")
+	mb.Breaker("a") //a 变成最近使用的，b 最久没用
+
+	mb.Breaker("d")
+	if keys := tracked(mb); len(keys) != 3 || keys["b"] {
+		t.Fatalf("b should be evicted first, got %v", keys)
+	}
+	mb.Breaker("e")
+	if keys := tracked(mb); len(keys) != 3 || keys["c"] || !keys["a"] {
+		t.Fatalf("c should be evicted next, got %v", keys)
+	}
+}
+
+func TestMultiBreakerPinsOpenBreakers(t *testing.T) {
+
+	mb := NewMultiBreaker(nil, func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key))
+	}, WithMaxKeys(2))
+
+	incident := mb.Breaker("incident")
+	tripBreaker(t, incident)
+	mb.Breaker("x")
+	mb.Breaker("y")
+	mb.Breaker("z")
+
+	keys := tracked(mb)
+	if !keys["incident"] || len(keys) != 2 {
+		t.Fatalf("open breaker should be kept, got %v", keys)
+	}
+	if mb.Breaker("incident") != incident || incident.State() != StateOpen {
+		t.Error("the open breaker should keep its state")
+	}
+}
+
+func TestMultiBreakerEvictsIdleKeys(t *testing.T) {
+
+	clock := newFakeClock()
+	mb := NewMultiBreaker(nil, func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key))
+	}, WithKeyTTL(time.Minute), WithKeyClock(clock))
+
+	mb.Breaker("idle")
+	tripBreaker(t, mb.Breaker("open"))
+	clock.Advance(30 * time.Second)
+	mb.Breaker("busy")
+	clock.Advance(45 * time.Second)
+	mb.Breaker("new")
+
+	if keys := tracked(mb); keys["idle"] || !keys["open"] || !keys["busy"] || !keys["new"] {
+		t.Errorf("only the idle closed breaker should be evicted, got %v", keys)
+	}
+}
+
+func TestMultiBreakerStopsHooksOfEvicted(t *testing.T) {
+
+	created := map[string]*RequestBreaker{}
+	mb := NewMultiBreaker(nil, func(key string) *RequestBreaker {
+		rb := NewRequestBreaker(ActionName(key), WithAsyncHooks(8))
+		created[key] = rb
+		return rb
+	}, WithMaxKeys(1))
+
+	mb.Breaker("a").Do(succeedWork)
+	mb.Breaker("b")
+
+	//被逐出的断路器不再有钩子的 goroutine
+	select {
+	case <-created["a"].hooks.done:
+	case <-time.After(time.Second):
+		t.Fatal("the hook goroutine of an evicted breaker should stop")
+	}
+	select {
+	case <-created["b"].hooks.done:
+		t.Error("the hooks of a tracked breaker should keep running")
+	default:
+	}
+}
@@ -0,0 +1,151 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type userRequest struct{ id int }
+type orderRequest struct{ id int }
+
+func TestMultiBreakerRoutesByKey(t *testing.T) {
+
+	mb := NewMultiBreaker(func(req interface{}) string {
+		switch req.(type) {
+		case userRequest:
+			return "user-service"
+		case orderRequest:
+			return "order-service"
+		}
+		return "default"
+	}, func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key))
+	})
+
+	failing := func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+	ok := func(ctx context.Context) (interface{}, error) { return "ok", nil }
+
+	//trip only the user service breaker
+	for i := 0; i < 3; i++ {
+		mb.Do(userRequest{id: i}, failing)
+	}
+
+	if _, err := mb.Do(userRequest{id: 4}, ok); err != ErrServiceUnavailable {
+		t.Errorf("user breaker should be open, got %v", err)
+	}
+
+	res, err := mb.Do(orderRequest{id: 1}, ok)
+	if err != nil || res != "ok" {
+		t.Errorf("order breaker should be closed, got %v %v", res, err)
+	}
+
+	if mb.Breaker("user-service") == mb.Breaker("order-service") {
+		t.Error("each key should have its own breaker")
+	}
+	if mb.Breaker("user-service").options.Name != "user-service" {
+		t.Error("breaker should be created by factory with the key")
+	}
+}
+
+func TestShardedMultiBreakerCreatesOncePerKey(t *testing.T) {
+
+	var mutex sync.Mutex
+	created := map[string]int{}
+	mb := NewShardedMultiBreaker(func(req interface{}) string { return req.(string) },
+		func(key string) *RequestBreaker {
+			mutex.Lock()
+			created[key]++
+			mutex.Unlock()
+			return NewRequestBreaker(ActionName(key))
+		}, 8)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("backend-%d", i%16)
+			if mb.Breaker(key).options.Name != key {
+				t.Errorf("got breaker of another key for %s", key)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(created) != 16 {
+		t.Errorf("expected 16 breakers, got %d", len(created))
+	}
+	for key, n := range created {
+		if n != 1 {
+			t.Errorf("breaker %s created %d times", key, n)
+		}
+	}
+}
+
+func benchmarkMultiBreakerKeys(b *testing.B, shards int) {
+
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("backend-%d", i)
+	}
+	mb := NewShardedMultiBreaker(nil, func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key))
+	}, shards)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			mb.Breaker(keys[i%len(keys)])
+			i += 7
+		}
+	})
+}
+
+func BenchmarkMultiBreakerSingleLock(b *testing.B) {
+	benchmarkMultiBreakerKeys(b, 1)
+}
+
+func BenchmarkMultiBreakerSharded(b *testing.B) {
+	benchmarkMultiBreakerKeys(b, 64)
+}
+
+func TestMultiBreakerSummary(t *testing.T) {
+
+	clock := newFakeClock()
+	mb := NewShardedMultiBreaker(nil, func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key), WithClock(clock), Timeout(time.Second),
+			WithShoulderHalfToOpen(2), WithHealthWeighting(0.5))
+	}, 4)
+
+	if sum := mb.Summary(); sum != (Summary{HealthScore: 1, WorstHealthScore: 1}) {
+		t.Errorf("empty summary should be healthy, got %+v", sum)
+	}
+
+	mb.Breaker("closed-1")
+	mb.Breaker("closed-2")
+	tripBreaker(t, mb.Breaker("open"))
+	tripBreaker(t, mb.Breaker("half-open"))
+	clock.Advance(2 * time.Second)
+	mb.Breaker("half-open").Do(succeedWork)
+	if mb.Breaker("half-open").State() != StateHalfOpen {
+		t.Fatal("breaker should be half-open")
+	}
+
+	sum := mb.Summary()
+	if sum.Closed != 2 || sum.HalfOpen != 1 || sum.Open != 1 {
+		t.Errorf("unexpected state counts %+v", sum)
+	}
+
+	//open: 0.125 after 3 failures, half-open: 0.5625 after one more success
+	if sum.WorstHealthScore != 0.125 {
+		t.Errorf("worst score should be the open breaker, got %v", sum.WorstHealthScore)
+	}
+	if want := (1 + 1 + 0.125 + 0.5625) / 4; sum.HealthScore != want {
+		t.Errorf("expected mean score %v, got %v", want, sum.HealthScore)
+	}
+}
@@ -0,0 +1,31 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:41:16
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:41:16
+ */
+
+package circuit
+
+//NilResultPolicy decide how a work returning (nil, nil) is counted
+type NilResultPolicy int
+
+//policies of (nil, nil)
+const (
+	//NilResultCountSuccess count (nil, nil) as a success, "empty but OK", it is the default
+	NilResultCountSuccess NilResultPolicy = iota
+	//NilResultIgnore count (nil, nil) as neither success nor failure, a no-op which says nothing about the backend
+	NilResultIgnore
+)
+
+//WithNilResultPolicy set how the breaker counts a work returning a nil result with a nil error
+func WithNilResultPolicy(policy NilResultPolicy) Option {
+	return func(opts *Options) {
+		opts.NilResult = policy
+	}
+}
+
+func (rb *RequestBreaker) ignoreNilResult(result interface{}, err error) bool {
+	return rb.options.NilResult == NilResultIgnore && result == nil && err == nil
+}
@@ -0,0 +1,28 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNilResultPolicy(t *testing.T) {
+
+	noop := func(ctx context.Context) (interface{}, error) { return nil, nil }
+	value := func(ctx context.Context) (interface{}, error) { return "v", nil }
+
+	rb := NewRequestBreaker()
+	rb.Do(noop)
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 1 {
+		t.Errorf("(nil, nil) should count as success by default, got %+v", cnt)
+	}
+
+	rb = NewRequestBreaker(WithNilResultPolicy(NilResultIgnore))
+	if res, err := rb.Do(noop); res != nil || err != nil {
+		t.Errorf("ignored result should still be returned, got %v %v", res, err)
+	}
+	rb.Do(value)
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 1 || cnt.TotalFailures != 1 {
+		t.Errorf("only (nil, nil) should be ignored, got %+v", cnt)
+	}
+}
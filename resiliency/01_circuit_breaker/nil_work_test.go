@@ -0,0 +1,31 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNilWork(t *testing.T) {
+
+	rb := NewRequestBreaker(WithFallbackChain(func(err error) (interface{}, error) {
+		t.Error("a programming error should not be hidden by the fallback")
+		return nil, nil
+	}))
+
+	if _, err := rb.Do(nil); err != ErrNilWork {
+		t.Errorf("Do: expected ErrNilWork, got %v", err)
+	}
+	if _, err := rb.DoContext(context.Background(), nil); err != ErrNilWork {
+		t.Errorf("DoContext: expected ErrNilWork, got %v", err)
+	}
+	if admitted, _, err := rb.TryDo(nil); admitted || err != ErrNilWork {
+		t.Errorf("TryDo: expected rejected with ErrNilWork, got %v %v", admitted, err)
+	}
+
+	if cnt := rb.Counts(); cnt != (Counts{}) {
+		t.Errorf("nil work should not touch the counters, got %+v", cnt)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("nil work should not change the state, got %s", rb.State())
+	}
+}
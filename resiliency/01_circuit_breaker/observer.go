@@ -0,0 +1,94 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:05:03
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:33:46
+ */
+
+package circuit
+
+import (
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 观察者模式
+/// 断路器就是被观察的主体(Subject)，状态变化(StateChange)就是发布给观察者的事件
+////////////////////////////////
+
+//StateChange is published by a breaker when its state changes
+type StateChange struct {
+	Name     string
+	From, To State
+	At       time.Time
+	Reason   string //为什么变化，见 LastTransitionReason
+	DryRun   bool   //WithDryRun 的断路器只是假装变化，请求照常放行
+}
+
+//Observer receive events of type E
+type Observer[E any] interface {
+	OnEvent(event E)
+}
+
+//ObserverFunc adapt a func to Observer
+type ObserverFunc[E any] func(event E)
+
+//OnEvent call f
+func (f ObserverFunc[E]) OnEvent(event E) {
+	f(event)
+}
+
+//Subject publish events of type E to its observers,
+//the zero value is ready to use and observers can be added or removed concurrently
+type Subject[E any] struct {
+	mutex     sync.RWMutex
+	nextID    uint64
+	observers []subscription[E] //按订阅的顺序
+}
+
+type subscription[E any] struct {
+	id       uint64
+	observer Observer[E]
+}
+
+//Subscribe add o to the subject, call the returned func to remove it
+func (s *Subject[E]) Subscribe(o Observer[E]) (unsubscribe func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := s.nextID
+	s.nextID++
+	s.observers = append(s.observers, subscription[E]{id: id, observer: o})
+
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i, sub := range s.observers {
+			if sub.id == id {
+				//复制一份，正在通知的快照不受影响
+				s.observers = append(s.observers[:i:i], s.observers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+//Notify send event to every observer currently subscribed, in the order they subscribed
+func (s *Subject[E]) Notify(event E) {
+	s.mutex.RLock()
+	observers := s.observers
+	s.mutex.RUnlock()
+
+	for _, sub := range observers {
+		sub.observer.OnEvent(event)
+	}
+}
+
+// Events return the subject publishing state changes of rb.
+// Observers are notified while the breaker holds its lock, just like OnStateChanged,
+// so they must not call back into the breaker.
+func (rb *RequestBreaker) Events() *Subject[StateChange] {
+	return &rb.events
+}
@@ -0,0 +1,73 @@
+package circuit
+
+import (
+	"sync"
+	"testing"
+)
+
+//changeRecorder is an Observer collecting state changes
+type changeRecorder struct {
+	mutex   sync.Mutex
+	changes []StateChange
+}
+
+func (r *changeRecorder) OnEvent(change StateChange) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.changes = append(r.changes, change)
+}
+
+func (r *changeRecorder) Changes() []StateChange {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]StateChange(nil), r.changes...)
+}
+
+func TestObserversReceiveTransitions(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("observed"))
+
+	first, second := &changeRecorder{}, &changeRecorder{}
+	rb.Events().Subscribe(first)
+	rb.Events().Subscribe(second)
+
+	var funcCalls int
+	unsubscribe := rb.Events().Subscribe(ObserverFunc[StateChange](func(StateChange) { funcCalls++ }))
+	unsubscribe()
+
+	tripBreaker(t, rb)
+
+	for i, r := range []*changeRecorder{first, second} {
+		changes := r.Changes()
+		if len(changes) != 1 {
+			t.Fatalf("observer %d: expected one event, got %v", i, changes)
+		}
+		c := changes[0]
+		if c.Name != "observed" || c.From != StateClosed || c.To != StateOpen {
+			t.Errorf("observer %d: unexpected event %+v", i, c)
+		}
+	}
+	if funcCalls != 0 {
+		t.Error("unsubscribed observer should not be notified")
+	}
+}
+
+func TestSubjectConcurrentSubscribe(t *testing.T) {
+
+	var s Subject[int]
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unsubscribe := s.Subscribe(ObserverFunc[int](func(int) {}))
+			s.Notify(1)
+			unsubscribe()
+		}()
+	}
+	wg.Wait()
+
+	if len(s.observers) != 0 {
+		t.Errorf("all observers should be removed, %d left", len(s.observers))
+	}
+}
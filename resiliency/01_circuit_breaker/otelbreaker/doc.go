@@ -0,0 +1,7 @@
+// Package otelbreaker traces breaker requests and records breaker metrics with OpenTelemetry.
+//
+// The package needs go.opentelemetry.io/otel and is only built with the otel build tag:
+//
+//	go get go.opentelemetry.io/otel go.opentelemetry.io/otel/sdk go.opentelemetry.io/otel/sdk/metric
+//	go test -tags otel ./otelbreaker
+package otelbreaker
@@ -0,0 +1,111 @@
+//go:build otel
+
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:04:33
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:04:33
+ */
+
+package otelbreaker
+
+import (
+	"context"
+	"sync"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+//Instrument names registered by WithMeter
+const (
+	RequestsInstrument = "circuit.requests"
+	StateInstrument    = "circuit.state"
+	LatencyInstrument  = "circuit.latency"
+)
+
+//OutcomeKey is the outcome of a request on the requests instrument
+const OutcomeKey = attribute.Key("circuit.outcome")
+
+// WithMeter records every breaker built with it on instruments of meter, all carrying the breaker name:
+// RequestsInstrument counts admitted requests by outcome, StateInstrument is 1 for the current state
+// and 0 for the others, LatencyInstrument is the latency of admitted requests in seconds.
+// It wraps the OutcomeSink and OnStateChanged set before it, so place it after them.
+// Errors creating the instruments go to otel.Handle and the breaker is left unrecorded.
+func WithMeter(meter metric.Meter) circuit.Option {
+	m, err := newMeterHooks(meter)
+	if err != nil {
+		otel.Handle(err)
+		return func(opts *circuit.Options) {}
+	}
+
+	return func(opts *circuit.Options) {
+		sink, changed := opts.OutcomeSink, opts.OnStateChanged
+		opts.OutcomeSink = func(record circuit.OutcomeRecord) {
+			m.outcome(record)
+			if sink != nil {
+				sink(record)
+			}
+		}
+		opts.OnStateChanged = func(name string, from, to circuit.State) {
+			m.stateChanged(name, from, to)
+			if changed != nil {
+				changed(name, from, to)
+			}
+		}
+	}
+}
+
+type meterHooks struct {
+	requests metric.Int64Counter
+	state    metric.Int64UpDownCounter
+	latency  metric.Float64Histogram
+	//已经记录过初始闭合状态的断路器名字
+	seen sync.Map
+}
+
+func newMeterHooks(meter metric.Meter) (*meterHooks, error) {
+	requests, err := meter.Int64Counter(RequestsInstrument,
+		metric.WithDescription("Requests admitted by the breaker, by outcome"))
+	if err != nil {
+		return nil, err
+	}
+	state, err := meter.Int64UpDownCounter(StateInstrument,
+		metric.WithDescription("1 for the current state of the breaker"))
+	if err != nil {
+		return nil, err
+	}
+	latency, err := meter.Float64Histogram(LatencyInstrument,
+		metric.WithDescription("Latency of requests admitted by the breaker"), metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+	return &meterHooks{requests: requests, state: state, latency: latency}, nil
+}
+
+//init count a breaker in its initial state the first time it is seen
+func (m *meterHooks) init(name string, initial circuit.State) {
+	if _, seen := m.seen.LoadOrStore(name, true); !seen {
+		m.state.Add(context.Background(), 1, metric.WithAttributes(NameKey.String(name), StateKey.String(initial.String())))
+	}
+}
+
+func (m *meterHooks) outcome(record circuit.OutcomeRecord) {
+	ctx := context.Background()
+	m.init(record.Name, circuit.StateClosed)
+
+	name := NameKey.String(record.Name)
+	m.requests.Add(ctx, 1, metric.WithAttributes(name, OutcomeKey.String(record.Outcome.String())))
+	m.latency.Record(ctx, record.Latency.Seconds(), metric.WithAttributes(name))
+}
+
+func (m *meterHooks) stateChanged(name string, from, to circuit.State) {
+	ctx := context.Background()
+	m.init(name, from)
+
+	m.state.Add(ctx, -1, metric.WithAttributes(NameKey.String(name), StateKey.String(from.String())))
+	m.state.Add(ctx, 1, metric.WithAttributes(NameKey.String(name), StateKey.String(to.String())))
+}
@@ -0,0 +1,86 @@
+//go:build otel
+
+package otelbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+//collect return the instruments of reader by name
+func collect(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	instruments := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			instruments[m.Name] = m.Data
+		}
+	}
+	return instruments
+}
+
+//sumBy return the points of a sum by the value of key, all points must carry the name of breaker
+func sumBy(t *testing.T, data metricdata.Aggregation, key attribute.Key) map[string]int64 {
+	t.Helper()
+	sum, ok := data.(metricdata.Sum[int64])
+	if !ok {
+		t.Fatalf("expected an int64 sum, got %T", data)
+	}
+	points := make(map[string]int64)
+	for _, p := range sum.DataPoints {
+		if name, _ := p.Attributes.Value(NameKey); name.AsString() != "metered" {
+			t.Errorf("point without the breaker name: %v", p.Attributes)
+		}
+		v, _ := p.Attributes.Value(key)
+		points[v.AsString()] = p.Value
+	}
+	return points
+}
+
+func TestMeterTripCycle(t *testing.T) {
+
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	rb := circuit.NewRequestBreaker(circuit.ActionName("metered"), circuit.Timeout(time.Millisecond),
+		WithMeter(provider.Meter("test")))
+
+	errDown := errors.New("backend down")
+	rb.Do(func(ctx context.Context) (interface{}, error) { return "ok", nil })
+	for i := 0; i < 3; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errDown })
+	}
+
+	instruments := collect(t, reader)
+	if state := sumBy(t, instruments[StateInstrument], StateKey); state["open"] != 1 || state["closed"] != 0 {
+		t.Errorf("tripped breaker should be counted open, got %v", state)
+	}
+
+	//打开时间到了，试探成功后闭合
+	time.Sleep(5 * time.Millisecond)
+	rb.Do(func(ctx context.Context) (interface{}, error) { return "ok", nil })
+
+	instruments = collect(t, reader)
+	state := sumBy(t, instruments[StateInstrument], StateKey)
+	if state["closed"] != 1 || state["open"] != 0 || state["half-open"] != 0 {
+		t.Errorf("recovered breaker should be counted closed only, got %v", state)
+	}
+	requests := sumBy(t, instruments[RequestsInstrument], OutcomeKey)
+	if requests["success"] != 2 || requests["failure"] != 3 {
+		t.Errorf("unexpected outcomes %v", requests)
+	}
+	latency, ok := instruments[LatencyInstrument].(metricdata.Histogram[float64])
+	if !ok || len(latency.DataPoints) != 1 || latency.DataPoints[0].Count != 5 {
+		t.Errorf("every admitted request should record its latency, got %+v", instruments[LatencyInstrument])
+	}
+}
@@ -0,0 +1,62 @@
+//go:build otel
+
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:37:19
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:37:19
+ */
+
+package otelbreaker
+
+import (
+	"context"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//Attribute keys set on every span
+const (
+	NameKey           = attribute.Key("circuit.name")
+	StateKey          = attribute.Key("circuit.state")
+	ShortCircuitedKey = attribute.Key("circuit.short_circuited")
+)
+
+//ShortCircuitedEvent is added to the span of a rejected request
+const ShortCircuitedEvent = "short_circuited"
+
+// WithTracer starts a child span of the caller's context for every request of the breaker.
+// The span carries the breaker name, the state of breaker after the request and whether it was short-circuited,
+// a failed or rejected request also records its error.
+func WithTracer(tracer trace.Tracer) circuit.Option {
+	return circuit.WithRequestTracer(requestTracer{tracer: tracer})
+}
+
+type requestTracer struct {
+	tracer trace.Tracer
+}
+
+func (t requestTracer) Start(ctx context.Context, name string) (context.Context, circuit.RequestSpan) {
+	ctx, span := t.tracer.Start(ctx, "circuit "+name, trace.WithAttributes(NameKey.String(name)))
+	return ctx, requestSpan{span: span}
+}
+
+type requestSpan struct {
+	span trace.Span
+}
+
+func (s requestSpan) End(state circuit.State, admitted bool, err error) {
+	s.span.SetAttributes(StateKey.String(state.String()), ShortCircuitedKey.Bool(!admitted))
+	if !admitted {
+		s.span.AddEvent(ShortCircuitedEvent)
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
@@ -0,0 +1,64 @@
+//go:build otel
+
+package otelbreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+func TestTracerSpans(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	rb := circuit.NewRequestBreaker(circuit.ActionName("traced"), WithTracer(provider.Tracer("test")))
+
+	errDown := errors.New("backend down")
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+	for i := 0; i < 3; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errDown })
+	}
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+
+	spans := recorder.Ended()
+	if len(spans) != 5 {
+		t.Fatalf("every request should get a span, got %d", len(spans))
+	}
+
+	ok := attrs(spans[0])
+	if ok[NameKey].AsString() != "traced" || ok[StateKey].AsString() != "closed" || ok[ShortCircuitedKey].AsBool() {
+		t.Errorf("unexpected attributes of success: %v", ok)
+	}
+	if spans[0].Status().Code == codes.Error {
+		t.Error("success should not be an error")
+	}
+
+	failed := attrs(spans[3])
+	if failed[StateKey].AsString() != "open" || failed[ShortCircuitedKey].AsBool() || spans[3].Status().Code != codes.Error {
+		t.Errorf("unexpected span of failure: %v %v", failed, spans[3].Status())
+	}
+
+	rejected := attrs(spans[4])
+	if !rejected[ShortCircuitedKey].AsBool() || spans[4].Status().Description != circuit.ErrServiceUnavailable.Error() {
+		t.Errorf("unexpected span of rejection: %v %v", rejected, spans[4].Status())
+	}
+	events := spans[4].Events()
+	if len(events) == 0 || events[0].Name != ShortCircuitedEvent {
+		t.Errorf("rejection should have a %s event, got %v", ShortCircuitedEvent, events)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:02:09
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:03:59
+ */
+
+package circuit
+
+import (
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 泛型计数器
+/// 不只是成功/失败，可以按任意结果类型计数(例如成功/超时/错误/丢弃)
+/// 计数只在时间窗口内有效，窗口可以是滚动的，也可以对齐到整点(固定窗口)
+////////////////////////////////
+
+//WindowAlignment decide how the window of a Counter moves
+type WindowAlignment int
+
+const (
+	//Rolling window tracks the trailing window duration from now
+	Rolling WindowAlignment = iota
+	//Fixed window snaps to wall-clock boundaries, such as every minute on the minute
+	Fixed
+)
+
+//rollingBuckets is how many slots a rolling window is split into
+const rollingBuckets = 10
+
+type counterOptions struct {
+	alignment WindowAlignment
+	clock     Clock
+}
+
+//CounterOption set Counter
+type CounterOption func(opts *counterOptions)
+
+//WithWindowAlignment set the alignment of window, Rolling by default
+func WithWindowAlignment(alignment WindowAlignment) CounterOption {
+	return func(opts *counterOptions) {
+		opts.alignment = alignment
+	}
+}
+
+//WithCounterClock set the clock of Counter
+func WithCounterClock(clock Clock) CounterOption {
+	return func(opts *counterOptions) {
+		opts.clock = clock
+	}
+}
+
+type bucket[O comparable] struct {
+	epoch  int64
+	totals map[O]uint64
+}
+
+//Counter count outcomes of type O within a window, it is safe for concurrent use
+type Counter[O comparable] struct {
+	mutex   sync.Mutex
+	options counterOptions
+	span    time.Duration //每个bucket覆盖的时长
+	buckets []bucket[O]
+}
+
+//NewCounter return a counter, window 0 means counts never expire
+func NewCounter[O comparable](window time.Duration, opts ...CounterOption) *Counter[O] {
+
+	c := &Counter[O]{options: counterOptions{alignment: Rolling, clock: systemClock{}}}
+	for _, setOption := range opts {
+		setOption(&c.options)
+	}
+
+	size := 1
+	c.span = window
+	if window > 0 && c.options.alignment == Rolling {
+		size = rollingBuckets
+		c.span = window / rollingBuckets
+	}
+
+	c.buckets = make([]bucket[O], size)
+	c.Reset()
+	return c
+}
+
+//Count one outcome
+func (c *Counter[O]) Count(outcome O) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	epoch := c.epoch()
+	b := &c.buckets[epoch%int64(len(c.buckets))]
+	if b.epoch != epoch {
+		b.epoch = epoch
+		b.totals = make(map[O]uint64)
+	}
+	b.totals[outcome]++
+}
+
+//Total return the count of outcome in current window
+func (c *Counter[O]) Total(outcome O) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	epoch := c.epoch()
+	oldest := epoch - int64(len(c.buckets))
+
+	var total uint64
+	for _, b := range c.buckets {
+		if b.epoch > oldest && b.epoch <= epoch {
+			total += b.totals[outcome]
+		}
+	}
+	return total
+}
+
+//Reset clear all counts
+func (c *Counter[O]) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i := range c.buckets {
+		c.buckets[i] = bucket[O]{epoch: -1, totals: make(map[O]uint64)}
+	}
+}
+
+//epoch return the index of the bucket for now, buckets are aligned to unix time,
+//so a fixed window of one minute starts on the minute
+func (c *Counter[O]) epoch() int64 {
+	if c.span <= 0 {
+		return 0
+	}
+	return c.options.clock.Now().UnixNano() / int64(c.span)
+}
@@ -0,0 +1,90 @@
+package circuit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type callOutcome int
+
+const (
+	outcomeSuccess callOutcome = iota
+	outcomeTimeout
+	outcomeError
+	outcomeShed
+)
+
+func TestCounterPerOutcomeTotals(t *testing.T) {
+
+	c := NewCounter[callOutcome](0)
+
+	want := map[callOutcome]uint64{
+		outcomeSuccess: 40,
+		outcomeTimeout: 3,
+		outcomeError:   7,
+		outcomeShed:    10,
+	}
+
+	var wg sync.WaitGroup
+	for outcome, n := range want {
+		for i := uint64(0); i < n; i++ {
+			wg.Add(1)
+			go func(o callOutcome) {
+				defer wg.Done()
+				c.Count(o)
+			}(outcome)
+		}
+	}
+	wg.Wait()
+
+	for outcome, n := range want {
+		if got := c.Total(outcome); got != n {
+			t.Errorf("outcome %d: expected %d, got %d", outcome, n, got)
+		}
+	}
+}
+
+func TestCounterWindowExpires(t *testing.T) {
+
+	c := NewCounter[callOutcome](5 * time.Millisecond)
+	c.Count(outcomeError)
+	if c.Total(outcomeError) != 1 {
+		t.Fatal("count should be visible inside the window")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if got := c.Total(outcomeError); got != 0 {
+		t.Errorf("count should expire with the window, got %d", got)
+	}
+}
+
+func TestCounterFixedWindowResetsOnBoundary(t *testing.T) {
+
+	clock := newFakeClock()
+	//the first request arrives in the middle of a minute
+	clock.Advance(45 * time.Second)
+
+	fixed := NewCounter[callOutcome](time.Minute, WithWindowAlignment(Fixed), WithCounterClock(clock))
+	rolling := NewCounter[callOutcome](time.Minute, WithCounterClock(clock))
+	fixed.Count(outcomeError)
+	rolling.Count(outcomeError)
+
+	clock.Advance(14 * time.Second) //00:00:59
+	if fixed.Total(outcomeError) != 1 {
+		t.Fatal("fixed window should keep counts until the minute ends")
+	}
+
+	clock.Advance(time.Second) //00:01:00
+	if got := fixed.Total(outcomeError); got != 0 {
+		t.Errorf("fixed window should reset exactly on the minute, got %d", got)
+	}
+	if got := rolling.Total(outcomeError); got != 1 {
+		t.Errorf("rolling window should still hold the trailing count, got %d", got)
+	}
+
+	clock.Advance(time.Minute)
+	if got := rolling.Total(outcomeError); got != 0 {
+		t.Errorf("rolling window should forget counts older than the window, got %d", got)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:48:41
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:49:43
+ */
+
+package circuit
+
+import (
+	"errors"
+	"time"
+)
+
+////////////////////////////////
+/// 模板方法模式
+/// 一次请求的流程是固定的：放行 → 执行 → 分类 → 记录 → 计数并决定是否转换状态
+/// 其中分类(Classify)和记录(Record)两步可以替换，状态机不用重新实现
+////////////////////////////////
+
+//Outcome is what the result of an admitted request means to the breaker
+type Outcome int
+
+//outcomes of a request
+const (
+	//OutcomeSuccess is counted as a success
+	OutcomeSuccess Outcome = iota
+	//OutcomeFailure is counted as a failure
+	OutcomeFailure
+	//OutcomeIgnore is counted as neither, it says nothing about the backend
+	OutcomeIgnore
+)
+
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	case OutcomeIgnore:
+		return "ignore"
+	}
+	return "unknown"
+}
+
+//errClassifiedFailure stand for a nil error that Classify turned into a failure
+var errClassifiedFailure = errors.New("classified as failure")
+
+//Completion is a finished request handed to the hooks
+type Completion struct {
+	Name    string //name of breaker
+	Result  interface{}
+	Err     error
+	Default Outcome //what the built-in classification says, with IgnoreCanceled, NilResult and MinBudget applied
+	//time left before the deadline of the caller when the request was admitted, Unbudgeted without deadline
+	Budget time.Duration
+}
+
+// OutcomeHooks are the overridable steps of a request.
+// Classify decides the outcome, Record is then told about it before the breaker counts it.
+// Hooks run outside the mutex and may be called concurrently, a panicking Classify falls back to Default.
+type OutcomeHooks interface {
+	Classify(c Completion) Outcome
+	Record(c Completion, outcome Outcome)
+}
+
+//DefaultOutcomeHooks is the built-in steps, embed it to override only one of them
+type DefaultOutcomeHooks struct{}
+
+//Classify return the built-in outcome
+func (DefaultOutcomeHooks) Classify(c Completion) Outcome { return c.Default }
+
+//Record does nothing, the breaker keeps its own counts
+func (DefaultOutcomeHooks) Record(c Completion, outcome Outcome) {}
+
+//WithOutcomeHooks replace the classify and record steps of every request
+func WithOutcomeHooks(hooks OutcomeHooks) Option {
+	return func(opts *Options) {
+		opts.OutcomeHooks = hooks
+	}
+}
+
+//classify run the classify and record steps, the returned error is what the breaker counts
+func (rb *RequestBreaker) classify(c Completion) (Outcome, error) {
+
+	outcome := c.Default
+	if hooks := rb.options.OutcomeHooks; hooks != nil {
+		if !rb.guard("Classify", func() { outcome = hooks.Classify(c) }) {
+			outcome = c.Default
+		}
+		rb.guard("Record", func() { hooks.Record(c, outcome) })
+	}
+
+	switch {
+	case outcome == OutcomeSuccess:
+		return outcome, nil
+	case outcome == OutcomeFailure && c.Err == nil:
+		return outcome, errClassifiedFailure
+	}
+	return outcome, c.Err
+}
@@ -0,0 +1,102 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var errNotFound = errors.New("not found")
+
+//stepHooks treat not found as success and a "degraded" result as failure, and log every step
+type stepHooks struct {
+	DefaultOutcomeHooks
+	rb    *RequestBreaker
+	steps []string
+}
+
+func (h *stepHooks) Classify(c Completion) Outcome {
+	h.steps = append(h.steps, "classify")
+	switch {
+	case errors.Is(c.Err, errNotFound):
+		return OutcomeSuccess
+	case c.Result == "degraded":
+		return OutcomeFailure
+	}
+	return c.Default
+}
+
+func (h *stepHooks) Record(c Completion, outcome Outcome) {
+	//记录在计数之前
+	h.steps = append(h.steps, fmt.Sprintf("record %s after %d requests", outcome, h.rb.Counts().Requests))
+}
+
+func TestOutcomeHooksStepsInOrder(t *testing.T) {
+
+	hooks := &stepHooks{}
+	rb := NewRequestBreaker(WithOutcomeHooks(hooks))
+	hooks.rb = rb
+
+	results := []struct {
+		result interface{}
+		err    error
+	}{
+		{nil, errNotFound},
+		{"degraded", nil},
+		{"ok", nil},
+	}
+	for _, r := range results {
+		_, err := rb.Do(func(ctx context.Context) (interface{}, error) { return r.result, r.err })
+		if err != r.err {
+			t.Errorf("caller should see the error of work, got %v", err)
+		}
+	}
+
+	want := []string{
+		"classify", "record success after 0 requests",
+		"classify", "record failure after 1 requests",
+		"classify", "record success after 2 requests",
+	}
+	if fmt.Sprint(hooks.steps) != fmt.Sprint(want) {
+		t.Errorf("steps:\n got %v\nwant %v", hooks.steps, want)
+	}
+
+	cnt := rb.Counts()
+	if cnt.TotalSuccesses != 2 || cnt.TotalFailures != 1 {
+		t.Errorf("counts should follow Classify, got %+v", cnt)
+	}
+}
+
+func TestOutcomeHooksDriveTransitions(t *testing.T) {
+
+	hooks := &stepHooks{}
+	rb := NewRequestBreaker(WithOutcomeHooks(hooks))
+	hooks.rb = rb
+
+	for i := 0; i < 5; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errNotFound })
+	}
+	if rb.State() != StateClosed {
+		t.Fatalf("not found is a success, breaker should stay closed, got %s", rb.State())
+	}
+
+	for i := 0; i < 3; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return "degraded", nil })
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("degraded results are failures, breaker should open, got %s", rb.State())
+	}
+}
+
+func TestDefaultOutcomeHooks(t *testing.T) {
+
+	rb := NewRequestBreaker(WithOutcomeHooks(DefaultOutcomeHooks{}), WithNilResultPolicy(NilResultIgnore))
+
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, nil })
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+
+	if cnt := rb.Counts(); cnt.Requests != 1 || cnt.TotalFailures != 1 {
+		t.Errorf("default hooks should keep built-in classification, got %+v", cnt)
+	}
+}
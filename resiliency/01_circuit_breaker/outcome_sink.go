@@ -0,0 +1,48 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:56:04
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:41:06
+ */
+
+package circuit
+
+import "time"
+
+////////////////////////////////
+/// 把每次请求的结果送到数据管道，离线分析
+////////////////////////////////
+
+//OutcomeRecord is one admitted request as seen by the breaker
+type OutcomeRecord struct {
+	Name    string
+	At      time.Time //请求开始执行的时间
+	Latency time.Duration
+	Outcome Outcome
+	State   State //记录结果之后断路器的状态
+	Err     error
+}
+
+//OutcomeSink receive an OutcomeRecord for every admitted request
+type OutcomeSink func(record OutcomeRecord)
+
+// WithOutcomeSink calls sink after the outcome of every request run by the breaker is recorded,
+// ignored outcomes included. The sink is called outside the lock on the goroutine of the request,
+// so it delays the caller but never the breaker, a slow sink should buffer internally,
+// such as into a channel drained by its own goroutine, or use WithAsyncHooks.
+// The results of Commit are not sent.
+func WithOutcomeSink(sink OutcomeSink) Option {
+	return func(opts *Options) {
+		opts.OutcomeSink = sink
+	}
+}
+
+func (rb *RequestBreaker) emitOutcome(at time.Time, latency time.Duration, outcome Outcome, state State, err error) {
+	sink := rb.options.OutcomeSink
+	if sink == nil {
+		return
+	}
+	record := OutcomeRecord{Name: rb.options.Name, At: at, Latency: latency, Outcome: outcome, State: state, Err: err}
+	rb.hook(func() { rb.guard("OutcomeSink", func() { sink(record) }) })
+}
@@ -0,0 +1,46 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOutcomeSink(t *testing.T) {
+
+	clock := newFakeClock()
+	var records []OutcomeRecord
+	rb := NewRequestBreaker(ActionName("search"), WithClock(clock),
+		WithOutcomeSink(func(record OutcomeRecord) { records = append(records, record) }))
+
+	start := clock.Now()
+	rb.Do(func(ctx context.Context) (interface{}, error) {
+		clock.Advance(10 * time.Millisecond)
+		return "ok", nil
+	})
+	for i := 0; i < 3; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			clock.Advance(5 * time.Millisecond)
+			return nil, errBackendDown
+		})
+	}
+	//被拒绝的请求没有执行，不产生记录
+	rb.Do(succeedWork)
+
+	if len(records) != 4 {
+		t.Fatalf("expected a record per admitted call, got %d", len(records))
+	}
+
+	first := records[0]
+	if first.Name != "search" || !first.At.Equal(start) || first.Latency != 10*time.Millisecond ||
+		first.Outcome != OutcomeSuccess || first.State != StateClosed || first.Err != nil {
+		t.Errorf("unexpected success record %+v", first)
+	}
+	if r := records[1]; r.Outcome != OutcomeFailure || r.Err != errBackendDown || r.State != StateClosed ||
+		r.Latency != 5*time.Millisecond {
+		t.Errorf("unexpected failure record %+v", r)
+	}
+	if r := records[3]; r.Outcome != OutcomeFailure || r.State != StateOpen {
+		t.Errorf("the tripping failure should be recorded with the open state, got %+v", r)
+	}
+}
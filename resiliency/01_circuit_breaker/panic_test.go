@@ -0,0 +1,53 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func panicWork(ctx context.Context) (interface{}, error) {
+	panic("boom")
+}
+
+//doPanicking run panicWork through rb and return what the caller recovered
+func doPanicking(rb *RequestBreaker) (r interface{}) {
+	defer func() { r = recover() }()
+	rb.Do(panicWork)
+	return nil
+}
+
+func TestPanicReleasesProbe(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := halfOpenBreaker(t, clock)
+
+	if r := doPanicking(rb); r != "boom" {
+		t.Fatalf("the panic should reach the caller, got %v", r)
+	}
+	if cnt := rb.Counts(); cnt.ProbeFailures != 1 {
+		t.Errorf("a panic should count as a failure, got %+v", cnt)
+	}
+
+	//试探名额归还了，下一个请求可以继续试探
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Fatalf("the probe slot should be given back, got %v", err)
+	}
+}
+
+func TestPanicReleasesInFlight(t *testing.T) {
+
+	rb := NewRequestBreaker(WithLoadShedding(1))
+
+	doPanicking(rb)
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Fatalf("the in-flight slot should be given back after a panic, got %v", err)
+	}
+
+	//panic 和失败一样会让断路器打开
+	for i := 0; i < 3; i++ {
+		doPanicking(rb)
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("panics should trip the breaker like failures, got %s", rb.State())
+	}
+}
@@ -0,0 +1,81 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 15:57:24
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:15:14
+ */
+
+package circuit
+
+import (
+	"context"
+	"fmt"
+)
+
+////////////////////////////////
+/// 管道模式 + 断路器
+/// 每一级(stage)调用不同的后端，每一级都有自己的断路器
+/// 任意一级失败或断路器打开，整个管道就在该级停止
+////////////////////////////////
+
+//StageFunc is the work of one stage, it takes the output of the previous stage as input
+type StageFunc func(ctx context.Context, in interface{}) (interface{}, error)
+
+//StageError tells which stage stopped the pipeline
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("pipeline stopped at stage %q: %v", e.Stage, e.Err)
+}
+
+//Unwrap return the error of the stage
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+type stage struct {
+	name string
+	rb   *RequestBreaker
+	fn   StageFunc
+}
+
+//Pipeline run stages in order, each stage is guarded by its own breaker
+type Pipeline struct {
+	stages []stage
+}
+
+//NewPipeline return an empty pipeline
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+//AddStage append a stage guarded by rb to the end of pipeline
+func (p *Pipeline) AddStage(name string, rb *RequestBreaker, fn StageFunc) *Pipeline {
+	p.stages = append(p.stages, stage{name: name, rb: rb, fn: fn})
+	return p
+}
+
+// Run feeds input through all stages in order.
+// Run stops at the first stage whose breaker rejects the request or whose work fails,
+// the returned error is a *StageError carrying the name of that stage.
+func (p *Pipeline) Run(ctx context.Context, input interface{}) (interface{}, error) {
+
+	data := input
+
+	for _, st := range p.stages {
+		in := data
+		out, err := st.rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+			return st.fn(ctx, in)
+		})
+		if err != nil {
+			return nil, &StageError{Stage: st.name, Err: err}
+		}
+		data = out
+	}
+
+	return data, nil
+}
@@ -0,0 +1,82 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errBackendDown = errors.New("backend down")
+
+//tripBreaker drive rb to open state with failures
+func tripBreaker(t *testing.T, rb *RequestBreaker) {
+	t.Helper()
+	for i := 0; i < 3; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			return nil, errBackendDown
+		})
+	}
+	if rb.state != StateOpen {
+		t.Fatalf("breaker should be open, got %v", rb.state)
+	}
+}
+
+func TestPipelineStopsAtOpenStage(t *testing.T) {
+
+	first := NewRequestBreaker(ActionName("first"))
+	middle := NewRequestBreaker(ActionName("middle"))
+	last := NewRequestBreaker(ActionName("last"))
+
+	tripBreaker(t, middle)
+
+	var ran []string
+	step := func(name string) StageFunc {
+		return func(ctx context.Context, in interface{}) (interface{}, error) {
+			ran = append(ran, name)
+			return in.(int) + 1, nil
+		}
+	}
+
+	p := NewPipeline().
+		AddStage("first", first, step("first")).
+		AddStage("middle", middle, step("middle")).
+		AddStage("last", last, step("last"))
+
+	out, err := p.Run(context.Background(), 0)
+	if out != nil {
+		t.Errorf("unexpected output: %v", out)
+	}
+
+	var stageErr *StageError
+	if !errors.As(err, &stageErr) {
+		t.Fatalf("expected StageError, got %v", err)
+	}
+	if stageErr.Stage != "middle" {
+		t.Errorf("pipeline should stop at middle, stopped at %s", stageErr.Stage)
+	}
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("expected ErrServiceUnavailable, got %v", err)
+	}
+	if len(ran) != 1 || ran[0] != "first" {
+		t.Errorf("only first stage should run, ran: %v", ran)
+	}
+}
+
+func TestPipelineRunsAllStages(t *testing.T) {
+
+	double := func(ctx context.Context, in interface{}) (interface{}, error) {
+		return in.(int) * 2, nil
+	}
+
+	p := NewPipeline().
+		AddStage("a", NewRequestBreaker(), double).
+		AddStage("b", NewRequestBreaker(), double)
+
+	out, err := p.Run(context.Background(), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.(int) != 12 {
+		t.Errorf("expected 12, got %v", out)
+	}
+}
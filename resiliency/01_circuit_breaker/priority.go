@@ -0,0 +1,46 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:29:12
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:29:12
+ */
+
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+//Priority of a request, the zero value is PriorityNormal
+type Priority int
+
+//priorities of request
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+type priorityKey struct{}
+
+//WithPriority return a context carrying the priority of request for DoContext
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+func priorityOf(ctx context.Context) Priority {
+	p, _ := ctx.Value(priorityKey{}).(Priority)
+	return p
+}
+
+// WithPriorityProbes reserves the half-open probes for high-priority requests during the first
+// grace of each half-open window, requests below PriorityHigh are rejected with ErrTooManyRequests
+// meanwhile. After grace any request may probe, so the breaker still recovers without important traffic.
+// Requests are never queued, the rejection is immediate.
+func WithPriorityProbes(grace time.Duration) Option {
+	return func(opts *Options) {
+		opts.PriorityProbeGrace = grace
+	}
+}
@@ -0,0 +1,70 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPriorityWinsHalfOpenSlot(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(1),
+		WithShoulderHalfToOpen(2), WithPriorityProbes(time.Minute))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	release := make(chan struct{})
+	var mutex sync.Mutex
+	admitted := map[Priority]int{}
+
+	var wg sync.WaitGroup
+	call := func(p Priority) {
+		defer wg.Done()
+		ctx := WithPriority(context.Background(), p)
+		rb.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+			mutex.Lock()
+			admitted[p]++
+			mutex.Unlock()
+			<-release
+			return nil, nil
+		})
+	}
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go call(PriorityLow)
+		wg.Add(1)
+		go call(PriorityNormal)
+	}
+	wg.Add(1)
+	go call(PriorityHigh)
+
+	//等到所有低优先级的请求都被拒绝
+	for rb.Counts().RejectedTooManyRequests < 20 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if admitted[PriorityHigh] != 1 || admitted[PriorityLow] != 0 || admitted[PriorityNormal] != 0 {
+		t.Errorf("only the high-priority caller should probe, got %v", admitted)
+	}
+}
+
+func TestPriorityProbesAfterGrace(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), WithPriorityProbes(time.Minute))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	if _, err := rb.Do(succeedWork); err != ErrTooManyRequests {
+		t.Fatalf("normal request should wait for the grace, got %v", err)
+	}
+	clock.Advance(time.Minute)
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Errorf("any request may probe after the grace, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:40:55
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:40:55
+ */
+
+package circuit
+
+import "context"
+
+type probeEligibleKey struct{}
+
+//WithProbeEligible return a context marking the request as cheap enough to probe a half-open breaker,
+//such as a read which is safe to retry, it only matters with WithEligibleProbesOnly
+func WithProbeEligible(ctx context.Context) context.Context {
+	return context.WithValue(ctx, probeEligibleKey{}, true)
+}
+
+func isProbeEligible(ctx context.Context) bool {
+	eligible, _ := ctx.Value(probeEligibleKey{}).(bool)
+	return eligible
+}
+
+// WithEligibleProbesOnly makes a half-open breaker admit only requests marked by WithProbeEligible,
+// other requests are rejected with ErrTooManyRequests until the breaker closes.
+// Recovery is then tested with cheap requests before expensive ones are let through,
+// a breaker without any eligible traffic stays half-open.
+func WithEligibleProbesOnly() Option {
+	return func(opts *Options) {
+		opts.EligibleProbesOnly = true
+	}
+}
@@ -0,0 +1,35 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOnlyEligibleRequestsProbe(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(5),
+		WithShoulderHalfToOpen(2), WithEligibleProbesOnly())
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	write := context.Background()
+	read := WithProbeEligible(context.Background())
+
+	if _, err := rb.DoContext(write, succeedWork); err != ErrTooManyRequests {
+		t.Fatalf("an expensive write should not probe, got %v", err)
+	}
+	if _, err := rb.DoContext(read, succeedWork); err != nil {
+		t.Fatalf("a cheap read should probe, got %v", err)
+	}
+	if _, err := rb.DoContext(write, succeedWork); err != ErrTooManyRequests || rb.State() != StateHalfOpen {
+		t.Fatalf("writes stay rejected until the breaker closes, got %v %v", err, rb.State())
+	}
+	if _, err := rb.DoContext(read, succeedWork); err != nil || rb.State() != StateClosed {
+		t.Fatalf("second good probe should close the breaker, got %v %v", err, rb.State())
+	}
+	if _, err := rb.DoContext(write, succeedWork); err != nil {
+		t.Errorf("writes pass once closed, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:03:38
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:06:04
+ */
+
+package circuit
+
+import "context"
+
+// ProbeNow lets an operator test a tripped backend without waiting for the open Timeout.
+// An open breaker turns half-open at once and work runs as one of its probes, so MaxRequests
+// still bounds how many concurrent ProbeNow calls reach the backend, the others are rejected
+// with ErrTooManyRequests. A successful probe closes the breaker as any probe would,
+// a failed one opens it again. A probe that is not admitted, such as a request WithEligibleProbesOnly rejects,
+// opens it again too, unless other probes are running. On a closed or half-open breaker ProbeNow is just DoContext.
+// A breaker WithManualRecoveryOnly is never forced half-open, while it is open ProbeNow is rejected
+// with ErrServiceUnavailable like any request, only Reset closes it.
+func (rb *RequestBreaker) ProbeNow(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	rb.lazyInit()
+	if work == nil {
+		return nil, ErrNilWork
+	}
+	admitted, result, err := rb.probe(ctx, work, "probe forced by ProbeNow")
+	return rb.withFallback(admitted, result, err)
+}
+
+//probe turn an open breaker half-open for why and run work as its probe, reopening it if the probe fails or is not admitted,
+//a breaker with ManualRecoveryOnly is not forced
+func (rb *RequestBreaker) probe(ctx context.Context, work func(ctx context.Context) (interface{}, error), why string) (bool, interface{}, error) {
+
+	rb.mutex.Lock()
+	//只能手动恢复的断路器不能被试探闭合
+	forced := rb.state == StateOpen && !rb.options.ManualRecoveryOnly
+	if forced {
+		rb.because(why)
+		rb.changeStateTo(StateHalfOpen)
+	}
+	generation := rb.generation
+	rb.mutex.Unlock()
+
+	admitted, result, err := rb.do(ctx, work, false)
+
+	//失败的试探不一定满足打开条件，强制的试探失败就重新打开
+	if forced && admitted && err != nil && ctx.Err() == nil {
+		rb.mutex.Lock()
+		if rb.state == StateHalfOpen && rb.generation == generation {
+			rb.because("%s failed: %v", why, err)
+			rb.changeStateTo(StateOpen)
+		}
+		rb.mutex.Unlock()
+	}
+	//强制的试探没有被放行，没有别的试探在跑的话也重新打开，不然半开状态没人试探
+	if forced && !admitted {
+		rb.mutex.Lock()
+		if rb.state == StateHalfOpen && rb.generation == generation && rb.probes == 0 {
+			rb.because("%s was not admitted: %v", why, err)
+			rb.changeStateTo(StateOpen)
+		}
+		rb.mutex.Unlock()
+	}
+	return admitted, result, err
+}
@@ -0,0 +1,110 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProbeNowClosesEarly(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(time.Hour))
+	tripBreaker(t, rb)
+
+	res, err := rb.ProbeNow(context.Background(), func(ctx context.Context) (interface{}, error) { return "ok", nil })
+	if err != nil || res != "ok" {
+		t.Fatalf("probe should run, got %v %v", res, err)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("successful probe should close the breaker before Timeout, got %s", rb.State())
+	}
+}
+
+func TestProbeNowFailureReopens(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(time.Hour))
+	tripBreaker(t, rb)
+
+	if _, err := rb.ProbeNow(context.Background(), failWork); err != errBackendDown {
+		t.Fatalf("probe should run, got %v", err)
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("failed probe should open the breaker again, got %s", rb.State())
+	}
+}
+
+func TestProbeNowRespectsMaxRequests(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(time.Hour), MaxRequests(1))
+	tripBreaker(t, rb)
+
+	running, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		_, err := rb.ProbeNow(context.Background(), func(ctx context.Context) (interface{}, error) {
+			close(running)
+			<-release
+			return "ok", nil
+		})
+		done <- err
+	}()
+	<-running
+
+	ran := false
+	_, err := rb.ProbeNow(context.Background(), func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return "ok", nil
+	})
+	if err != ErrTooManyRequests || ran {
+		t.Errorf("second probe should be rejected while the first runs, got %v, ran %v", err, ran)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("first probe should close the breaker, got %s", rb.State())
+	}
+}
+
+func TestProbeNowNotAdmittedReopens(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(time.Hour), WithEligibleProbesOnly())
+	tripBreaker(t, rb)
+
+	//没有标记的请求不能试探，强制转成的半开状态不能留着没人试探
+	if _, err := rb.ProbeNow(context.Background(), succeedWork); err != ErrTooManyRequests {
+		t.Fatalf("an ineligible probe should be rejected, got %v", err)
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("a probe that was not admitted should open the breaker again, got %s", rb.State())
+	}
+
+	if _, err := rb.ProbeNow(WithProbeEligible(context.Background()), succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("an eligible probe should close the breaker, got %v in %s", err, rb.State())
+	}
+}
+
+func TestProbeNowManualRecoveryOnly(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(time.Hour), WithManualRecoveryOnly(true))
+	tripBreaker(t, rb)
+
+	ran := false
+	_, err := rb.ProbeNow(context.Background(), func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return "ok", nil
+	})
+	if err != ErrServiceUnavailable || ran {
+		t.Fatalf("a manual-only breaker should reject the probe without running it, got %v", err)
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("only Reset closes a manual-only breaker, got %s", rb.State())
+	}
+
+	rb.Reset()
+	if _, err := rb.ProbeNow(context.Background(), succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("after Reset ProbeNow is just DoContext, got %s %v", rb.State(), err)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:40:25
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:04:27
+ */
+
+package circuit
+
+import (
+	"math/rand"
+	"time"
+)
+
+////////////////////////////////
+/// 原型模式
+/// 以一个配置好的断路器(或者整个MultiBreaker、注册表)为原型，复制出配置相同、状态全新的副本
+/// 多租户环境可以用它给每个租户复制一套断路器
+////////////////////////////////
+
+//CounterCloner is implemented by custom counters which can be copied for a cloned breaker,
+//a clone of a breaker whose counter is not a CounterCloner uses the built-in counter
+type CounterCloner interface {
+	CloneCounter() ICounter
+}
+
+//Clone return a new closed breaker with the options of rb and nothing shared but the callbacks, clock and context
+func (rb *RequestBreaker) Clone() *RequestBreaker {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	opts := *rb.options
+	rb.mutex.Unlock()
+
+	//让副本重新计算到期时间
+	opts.Expiry = time.Time{}
+	if opts.AdaptiveTimeout != nil {
+		adaptive := *opts.AdaptiveTimeout
+		opts.AdaptiveTimeout = &adaptive
+	}
+	if cloner, ok := opts.Counter.(CounterCloner); ok {
+		opts.Counter = cloner.CloneCounter()
+	} else {
+		opts.Counter = nil
+	}
+	//rand.Rand 不能并发使用，副本用从原来的随机数派生的新随机数
+	if opts.JitterRand != nil {
+		rb.mutex.Lock()
+		opts.JitterRand = rand.New(rand.NewSource(opts.JitterRand.Int63()))
+		rb.mutex.Unlock()
+	}
+	if opts.ChaosRand != nil {
+		rb.chaos.Lock()
+		opts.ChaosRand = rand.New(rand.NewSource(opts.ChaosRand.Int63()))
+		rb.chaos.Unlock()
+	}
+
+	clone := &RequestBreaker{}
+	clone.initOnce.Do(func() {
+		clone.init([]Option{func(o *Options) { *o = opts }})
+	})
+	return clone
+}
+
+//Clone return a MultiBreaker with the same key function and factory holding a clone of every breaker of mb
+func (mb *MultiBreaker) Clone() *MultiBreaker {
+
+	clone := NewShardedMultiBreaker(mb.keyFn, mb.factory, len(mb.shards), mb.opts...)
+	for i := range mb.shards {
+		sh := &mb.shards[i]
+		sh.mutex.Lock()
+		for key, rb := range sh.breakers {
+			//分片数相同，key落在同样编号的分片
+			cloned := rb.Clone()
+			clone.shards[i].breakers[key] = cloned
+			//副本的分批恢复也要跟踪这些断路器
+			if clone.cohort != nil {
+				clone.cohort.watch(key, cloned)
+			}
+		}
+		//保持最近使用的顺序
+		if sh.lru != nil {
+			for e := sh.lru.Back(); e != nil; e = e.Prev() {
+				entry := *e.Value.(*keyEntry)
+				clone.shards[i].elems[entry.key] = clone.shards[i].lru.PushFront(&entry)
+			}
+		}
+		sh.mutex.Unlock()
+	}
+	return clone
+}
+
+//Clone return a Registry holding a clone of every breaker of reg under the same name
+func (reg *Registry) Clone() *Registry {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	clone := NewRegistry()
+	for name, rb := range reg.breakers {
+		clone.breakers[name] = rb.Clone()
+	}
+	return clone
+}
@@ -0,0 +1,79 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRequestBreakerClone(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("prototype"), Timeout(time.Minute), MaxRequests(3),
+		WithAdaptiveTimeout(time.Second, time.Hour, 2))
+	tripBreaker(t, rb)
+
+	clone := rb.Clone()
+	if clone.State() != StateClosed || clone.Counts().Requests != 0 {
+		t.Fatalf("clone should start closed and fresh, got %v %+v", clone.State(), clone.Counts())
+	}
+	if clone.options.Name != "prototype" || clone.options.Timeout != time.Minute || clone.options.MaxRequests != 3 {
+		t.Errorf("options should be copied: %+v", clone.options)
+	}
+	if clone.options.AdaptiveTimeout == rb.options.AdaptiveTimeout || *clone.options.AdaptiveTimeout != *rb.options.AdaptiveTimeout {
+		t.Error("adaptive timeout should be copied, not shared")
+	}
+	if rb.State() != StateOpen {
+		t.Error("cloning should not touch the prototype")
+	}
+}
+
+func TestMultiBreakerClone(t *testing.T) {
+
+	factory := func(key string) *RequestBreaker {
+		return NewRequestBreaker(ActionName(key), MaxRequests(2))
+	}
+	mb := NewShardedMultiBreaker(func(req interface{}) string { return req.(string) }, factory, 4)
+	for _, key := range []string{"orders", "users", "billing"} {
+		mb.Breaker(key)
+	}
+	tripBreaker(t, mb.Breaker("orders"))
+
+	clone := mb.Clone()
+	if sum := clone.Summary(); sum.Closed != 3 || sum.Open != 0 {
+		t.Fatalf("every cloned breaker should be closed, got %+v", sum)
+	}
+	for _, key := range []string{"orders", "users", "billing"} {
+		if clone.Breaker(key) == mb.Breaker(key) || clone.Breaker(key).options.Name != key {
+			t.Errorf("%s should be a copy with its own config", key)
+		}
+	}
+
+	tripBreaker(t, clone.Breaker("users"))
+	if mb.Breaker("users").State() != StateClosed || mb.Breaker("orders").State() != StateOpen {
+		t.Error("states of the clone should be independent")
+	}
+}
+
+func TestRegistryClone(t *testing.T) {
+
+	reg := NewRegistry()
+	for _, name := range []string{"orders", "users"} {
+		reg.Register(NewRequestBreaker(ActionName(name), MaxRequests(2)))
+	}
+	orders := reg.Breakers()[0]
+	tripBreaker(t, orders)
+
+	clone := reg.Clone()
+	breakers := clone.Breakers()
+	if len(breakers) != 2 || breakers[0].Name() != "orders" || breakers[1].Name() != "users" {
+		t.Fatalf("clone should hold every breaker under its name, got %d", len(breakers))
+	}
+	if breakers[0] == orders || breakers[0].State() != StateClosed || breakers[0].options.MaxRequests != 2 {
+		t.Error("orders should be a fresh copy with its own config")
+	}
+
+	//副本的登记和原来的互不影响
+	clone.Register(NewRequestBreaker(ActionName("billing")))
+	if len(reg.Breakers()) != 2 || reg.Summary().Open != 1 {
+		t.Error("the prototype registry should not change")
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:15:52
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:07:40
+ */
+
+package circuit
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+////////////////////////////////
+/// 消息队列的消费者 + 断路器
+/// 下游挂了还继续拉消息，只会把消息一条条处理失败、反复重投
+/// 断路器打开时暂停拉取，可以试探时再拉一条，恢复后继续消费
+////////////////////////////////
+
+//Puller fetch the next message of a queue, it blocks until there is one or ctx is done
+type Puller[M any] interface {
+	Pull(ctx context.Context) (M, error)
+}
+
+//Acker settle a pulled message
+type Acker[M any] interface {
+	//Ack remove a processed message from the queue
+	Ack(ctx context.Context, msg M) error
+	//Nack give back a message which was not processed, so it is delivered again
+	Nack(ctx context.Context, msg M) error
+}
+
+//ConsumerOption set Consumer
+type ConsumerOption func(c *consumerOptions)
+
+type consumerOptions struct {
+	pause time.Duration
+}
+
+//PauseInterval set how long the consumer waits after a rejection when the breaker cannot tell
+//when it will recover, such as while it is half-open or with WithManualRecoveryOnly, 100ms by default
+func PauseInterval(d time.Duration) ConsumerOption {
+	return func(c *consumerOptions) {
+		c.pause = d
+	}
+}
+
+//Consumer process the messages of a queue through a breaker, one at a time
+type Consumer[M any] struct {
+	puller  Puller[M]
+	acker   Acker[M]
+	rb      *RequestBreaker
+	process func(ctx context.Context, msg M) error
+	options consumerOptions
+}
+
+//NewConsumer return a consumer pulling from puller and settling through acker, process is run through rb
+func NewConsumer[M any](puller Puller[M], acker Acker[M], rb *RequestBreaker, process func(ctx context.Context, msg M) error, opts ...ConsumerOption) *Consumer[M] {
+	c := &Consumer[M]{
+		puller:  puller,
+		acker:   acker,
+		rb:      rb,
+		process: process,
+		options: consumerOptions{pause: 100 * time.Millisecond},
+	}
+	for _, setOption := range opts {
+		setOption(&c.options)
+	}
+	return c
+}
+
+// Run consumes until ctx is done or the queue fails and returns why it stopped.
+// A processed message is acked, a failed or rejected one is nacked to be delivered again,
+// the fallback chain of rb is not run, a fallback cannot process a message.
+// While the breaker is open nothing is pulled, Run waits until the breaker may turn half-open,
+// then the next message is its probe, consumption resumes once the breaker closes.
+// An error of Pull, Ack or Nack stops Run.
+func (c *Consumer[M]) Run(ctx context.Context) error {
+	for {
+		//打开期间不拉消息，等到可以试探，不会自己恢复的话隔一会儿再看
+		if wait := c.rb.RetryAfter(); wait != 0 {
+			if wait == RetryNever {
+				wait = c.options.pause
+			}
+			if err := sleep(ctx, c.rb.clock(), wait); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		msg, err := c.puller.Pull(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		//不走降级链，降级给出的结果不等于消息被处理了
+		admitted, _, err := c.rb.do(ctx, func(ctx context.Context) (interface{}, error) {
+			return nil, c.process(ctx, msg)
+		}, false)
+		if err == nil {
+			if err := c.acker.Ack(ctx, msg); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.acker.Nack(ctx, msg); err != nil {
+			return err
+		}
+
+		//被拒绝但是不知道什么时候能试探，隔一会儿再拉
+		if !admitted && (errors.Is(err, ErrServiceUnavailable) || errors.Is(err, ErrTooManyRequests)) {
+			if c.rb.RetryAfter() == 0 {
+				if err := sleep(ctx, c.rb.clock(), c.options.pause); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+//sleep wait d on clock or until ctx is done
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,140 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//fakeQueue redeliver nacked messages at the back and count pulls
+type fakeQueue struct {
+	mutex sync.Mutex
+	msgs  chan int
+	pulls int
+	acked []int
+}
+
+func newFakeQueue(n int) *fakeQueue {
+	q := &fakeQueue{msgs: make(chan int, n)}
+	for i := 0; i < n; i++ {
+		q.msgs <- i
+	}
+	return q
+}
+
+func (q *fakeQueue) Pull(ctx context.Context) (int, error) {
+	select {
+	case msg := <-q.msgs:
+		q.mutex.Lock()
+		q.pulls++
+		q.mutex.Unlock()
+		return msg, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func (q *fakeQueue) Ack(ctx context.Context, msg int) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.acked = append(q.acked, msg)
+	return nil
+}
+
+func (q *fakeQueue) Nack(ctx context.Context, msg int) error {
+	q.msgs <- msg
+	return nil
+}
+
+func (q *fakeQueue) stats() (pulls, acked int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.pulls, len(q.acked)
+}
+
+func TestConsumerPausesWhileOpen(t *testing.T) {
+
+	const msgs = 10
+
+	q := newFakeQueue(msgs)
+	rb := NewRequestBreaker(Timeout(100 * time.Millisecond))
+
+	var down int32 = 1
+	c := NewConsumer[int](q, q, rb, func(ctx context.Context, msg int) error {
+		if atomic.LoadInt32(&down) == 1 {
+			return errBackendDown
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopped := make(chan error)
+	go func() { stopped <- c.Run(ctx) }()
+
+	//连续失败3次打开，之后不再拉消息
+	eventually(t, rb, StateOpen)
+	paused, _ := q.stats()
+	time.Sleep(50 * time.Millisecond)
+	if pulls, acked := q.stats(); pulls != paused || acked != 0 {
+		t.Fatalf("consumer should pause while the breaker is open, pulls %d -> %d, acked %d", paused, pulls, acked)
+	}
+	if paused != 3 {
+		t.Errorf("expected 3 pulls before the trip, got %d", paused)
+	}
+
+	//下游恢复，试探成功后继续消费
+	atomic.StoreInt32(&down, 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for _, acked := q.stats(); acked < msgs && time.Now().Before(deadline); _, acked = q.stats() {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, acked := q.stats(); acked != msgs {
+		t.Fatalf("consumer should resume after recovery, acked %d of %d", acked, msgs)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("breaker should be closed, got %s", rb.State())
+	}
+
+	cancel()
+	if err := <-stopped; err != context.Canceled {
+		t.Errorf("Run should stop with the context, got %v", err)
+	}
+}
+
+func TestConsumerNacksRejectedWithFallback(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(1),
+		WithFallbackChain(func(err error) (interface{}, error) { return "cached", nil }))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+	//占住唯一的试探名额，消费者的请求会被拒绝
+	if _, err := rb.Prepare(); err != nil {
+		t.Fatal(err)
+	}
+
+	q := newFakeQueue(1)
+	processed := int32(0)
+	c := NewConsumer[int](q, q, rb, func(ctx context.Context, msg int) error {
+		atomic.AddInt32(&processed, 1)
+		return nil
+	}, PauseInterval(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error)
+	go func() { stopped <- c.Run(ctx) }()
+
+	//被拒绝之后在时钟上等待，这时消息已经处理完
+	waitTimer(t, clock)
+	cancel()
+	<-stopped
+
+	pulls, acked := q.stats()
+	if pulls != 1 || acked != 0 || len(q.msgs) != 1 || atomic.LoadInt32(&processed) != 0 {
+		t.Errorf("a rejected message should be nacked even if a fallback handles it, pulls %d acked %d queued %d",
+			pulls, acked, len(q.msgs))
+	}
+}
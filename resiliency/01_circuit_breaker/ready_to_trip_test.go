@@ -0,0 +1,80 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestReadyToTripContextByState(t *testing.T) {
+
+	var names []string
+	clock := newFakeClock()
+	rb := NewRequestBreaker(ActionName("payments"), WithClock(clock), Timeout(time.Second), WithShoulderHalfToOpen(5),
+		//ignored, the context variant takes precedence
+		WithBreakCondition(func(State, Counts) bool { return true }),
+		WithReadyToTripContext(func(name string, state State, cnt Counts) bool {
+			names = append(names, name)
+			if state == StateHalfOpen {
+				return true //试探失败一次就重新打开
+			}
+			return cnt.ConsecutiveFailures >= 5
+		}))
+
+	fail := func(ctx context.Context) (interface{}, error) { return nil, errBackendDown }
+
+	for i := 0; i < 4; i++ {
+		rb.Do(fail)
+	}
+	if rb.State() != StateClosed {
+		t.Fatalf("closed should tolerate 4 failures, got %s", rb.State())
+	}
+	rb.Do(fail)
+	if rb.State() != StateOpen {
+		t.Fatalf("closed should trip at 5 failures, got %s", rb.State())
+	}
+
+	clock.Advance(2 * time.Second)
+	rb.Do(fail)
+	if rb.State() != StateOpen {
+		t.Errorf("half-open should trip at the first failure, got %s", rb.State())
+	}
+
+	if len(names) != 6 || names[0] != "payments" {
+		t.Errorf("policy should get the breaker name on each failure, got %v", names)
+	}
+}
+
+func TestReadyToTripChoosesOpenDuration(t *testing.T) {
+
+	var outage bool
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second),
+		WithReadyToTrip(func(state State, cnt Counts) (bool, time.Duration) {
+			if outage {
+				return true, 5 * time.Minute //看起来是彻底挂了，多等一会儿
+			}
+			return cnt.ConsecutiveFailures > 2, 0
+		}))
+
+	//openFor 为0时按Timeout
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Fatalf("zero openFor should use Timeout, got %s %v", rb.State(), err)
+	}
+
+	outage = true
+	rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	if rb.State() != StateOpen {
+		t.Fatalf("policy should trip, got %s", rb.State())
+	}
+	clock.Advance(4 * time.Minute)
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Errorf("breaker should stay open for the requested 5 minutes, got %v", err)
+	}
+	clock.Advance(time.Minute + time.Second)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("breaker should recover after 5 minutes, got %s %v", rb.State(), err)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:23:13
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:27:44
+ */
+
+package circuit
+
+import (
+	"fmt"
+	"time"
+)
+
+////////////////////////////////
+/// 状态变化的原因，给人看的
+/// 做出判断的地方(打开条件、延迟、失败权重、手动Reset...)先写下原因，changeStateTo 取走
+/// 没人写原因的变化按前后两个状态描述
+////////////////////////////////
+
+//StateChangedReasonHandler is a StateChangedEventHandler that is also told why the state changed
+type StateChangedReasonHandler func(name string, from State, to State, reason string)
+
+//WithStateChangedReason set a handler of state changes that receives the reason too, it runs after OnStateChanged
+func WithStateChangedReason(handler StateChangedReasonHandler) Option {
+	return func(opts *Options) {
+		opts.OnStateChangedReason = handler
+	}
+}
+
+//LastTransitionReason return why the breaker last changed state, such as
+//"trip policy ratio(minRequests=4, ratio=0.5) met in closed: 3 of 5 requests failed (0.60), 2 consecutive", empty before the first change
+func (rb *RequestBreaker) LastTransitionReason() string {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.lastReason
+}
+
+//because record why the state is about to change, must be called with the mutex held
+func (rb *RequestBreaker) because(format string, args ...interface{}) {
+	rb.reason = fmt.Sprintf(format, args...)
+}
+
+//conditionMet record the counters that met the break condition
+func (rb *RequestBreaker) conditionMet(state State, cnt Counts) {
+	policy := "break condition"
+	if rb.options.CanOpenFor == nil && rb.options.CanOpenContext == nil {
+		if threshold := rb.threshold.Load(); threshold != nil {
+			policy = "trip " + threshold.String()
+		} else if name := rb.options.TripPolicy; name != "" {
+			policy = "trip policy " + name
+		}
+	}
+	failures := cnt.TotalFailures + cnt.ProbeFailures
+	ratio := 0.0
+	if cnt.Requests > 0 {
+		ratio = float64(failures) / float64(cnt.Requests)
+	}
+	rb.because("%s met in %s: %d of %d requests failed (%.2f), %d consecutive",
+		policy, state, failures, cnt.Requests, ratio, cnt.ConsecutiveFailures)
+}
+
+//transitionReason take the recorded reason of the change to state to, or describe the change when nobody recorded one.
+//It must be called with the mutex held, before the state and the counters change
+func (rb *RequestBreaker) transitionReason(to State, now time.Time) string {
+	reason := rb.reason
+	rb.reason = ""
+	if reason != "" {
+		return reason
+	}
+
+	switch {
+	case rb.state == StateOpen && to == StateHalfOpen:
+		return fmt.Sprintf("open timeout elapsed after %v", now.Sub(rb.stateSince))
+	case rb.state == StateHalfOpen && to == StateClosed:
+		return fmt.Sprintf("recovered after %d consecutive successful probes", rb.snapshot().ConsecutiveSuccesses)
+	case rb.state == StateHalfOpen && to == StateOpen:
+		return "probe failed"
+	}
+	return fmt.Sprintf("%s to %s", rb.state, to)
+}
@@ -0,0 +1,77 @@
+package circuit
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRatioTripRecordsReason(t *testing.T) {
+
+	policy, err := WithTripPolicy("ratio", map[string]interface{}{"minRequests": 4, "ratio": 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var notified []string
+	rb := NewRequestBreaker(policy, WithTransitionHistory(4),
+		WithStateChanged(func(name string, from, to State) {}),
+		WithStateChangedReason(func(name string, from, to State, reason string) {
+			notified = append(notified, fmt.Sprintf("%s -> %s: %s", from, to, reason))
+		}))
+	if got := rb.LastTransitionReason(); got != "" {
+		t.Errorf("no reason before the first change, got %q", got)
+	}
+
+	for _, work := range []Work{succeedWork, failWork, succeedWork, failWork} {
+		rb.Do(work)
+	}
+	if rb.State() != StateOpen {
+		t.Fatalf("half of 4 requests failed, breaker should open, got %s", rb.State())
+	}
+
+	want := "trip policy ratio(minRequests=4, ratio=0.5) met in closed: 2 of 4 requests failed (0.50), 1 consecutive"
+	if got := rb.LastTransitionReason(); got != want {
+		t.Errorf("reason:\n got %q\nwant %q", got, want)
+	}
+	if len(notified) != 1 || notified[0] != "closed -> open: "+want {
+		t.Errorf("handler should be told the reason, got %q", notified)
+	}
+	it := rb.TransitionIterator()
+	if !it.HasNext() || it.Next().Reason != want {
+		t.Error("the history should keep the reason")
+	}
+}
+
+func TestTransitionReasons(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(10*time.Second), WithTransitionHistory(4))
+
+	tripBreaker(t, rb)
+	clock.Advance(10*time.Second + time.Nanosecond)
+	rb.Do(succeedWork)
+	want := []string{
+		"trip policy default met in closed: 3 of 3 requests failed (1.00), 3 consecutive",
+		"open timeout elapsed after 10.000000001s",
+		"recovered after 1 consecutive successful probes",
+	}
+	var got []string
+	for it := rb.TransitionIterator(); it.HasNext(); {
+		got = append(got, it.Next().Reason)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("reasons:\n got %q\nwant %q", got, want)
+	}
+
+	tripBreaker(t, rb)
+	rb.ResetWithReason("reset by admin")
+	if got := rb.LastTransitionReason(); got != "reset by admin" || rb.State() != StateClosed {
+		t.Errorf("reset should record its reason, got %q %s", got, rb.State())
+	}
+	tripBreaker(t, rb)
+	rb.Reset()
+	if got := rb.LastTransitionReason(); got != "manual reset" {
+		t.Errorf("unexpected reset reason %q", got)
+	}
+}
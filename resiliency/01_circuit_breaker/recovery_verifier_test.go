@@ -0,0 +1,39 @@
+package circuit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRecoveryVerifierHoldsHalfOpen(t *testing.T) {
+
+	var healthy, asked int32
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(1),
+		WithRecoveryVerifier(func(ctx context.Context) bool {
+			atomic.AddInt32(&asked, 1)
+			return atomic.LoadInt32(&healthy) == 1
+		}))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+
+	//试探都成功了，但是依赖的健康检查还没通过
+	for i := 0; i < 3; i++ {
+		if _, err := rb.Do(succeedWork); err != nil {
+			t.Fatalf("probe %d should keep being admitted, got %v", i, err)
+		}
+		if rb.State() != StateHalfOpen {
+			t.Fatalf("breaker should not close before the verifier passes, got %v", rb.State())
+		}
+	}
+	if atomic.LoadInt32(&asked) != 3 {
+		t.Errorf("verifier should be asked on every good probe, asked %d", asked)
+	}
+
+	atomic.StoreInt32(&healthy, 1)
+	if _, err := rb.Do(succeedWork); err != nil || rb.State() != StateClosed {
+		t.Errorf("breaker should close once verified, got %v %v", err, rb.State())
+	}
+}
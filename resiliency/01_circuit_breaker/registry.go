@@ -0,0 +1,75 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:53:57
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:04:02
+ */
+
+package circuit
+
+import (
+	"sort"
+	"sync"
+)
+
+////////////////////////////////
+/// 断路器的注册表
+/// 一个进程里的断路器按名字登记在一起，监控和管理界面只需要拿到注册表
+////////////////////////////////
+
+//Registry hold the breakers of a process by name
+type Registry struct {
+	mutex    sync.Mutex
+	breakers map[string]*RequestBreaker
+}
+
+//NewRegistry return an empty registry
+func NewRegistry() *Registry {
+	return &Registry{breakers: make(map[string]*RequestBreaker)}
+}
+
+//Register add rb under its name, replacing the breaker of the same name, call the returned func to remove it
+func (reg *Registry) Register(rb *RequestBreaker) (unregister func()) {
+	name := rb.Name()
+	reg.mutex.Lock()
+	reg.breakers[name] = rb
+	reg.mutex.Unlock()
+
+	return func() {
+		reg.mutex.Lock()
+		//已经被同名的断路器替换了就不要删
+		if reg.breakers[name] == rb {
+			delete(reg.breakers, name)
+		}
+		reg.mutex.Unlock()
+	}
+}
+
+//Breakers return the registered breakers sorted by name
+func (reg *Registry) Breakers() []*RequestBreaker {
+	reg.mutex.Lock()
+	names := make([]string, 0, len(reg.breakers))
+	for name := range reg.breakers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	breakers := make([]*RequestBreaker, len(names))
+	for i, name := range names {
+		breakers[i] = reg.breakers[name]
+	}
+	reg.mutex.Unlock()
+	return breakers
+}
+
+//Summary return the state counts and health of the registered breakers, taken under one lock of the registry
+func (reg *Registry) Summary() Summary {
+	reg.mutex.Lock()
+	defer reg.mutex.Unlock()
+
+	var sum summarizer
+	for _, rb := range reg.breakers {
+		sum.add(rb)
+	}
+	return sum.result()
+}
@@ -0,0 +1,29 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:25:44
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:25:44
+ */
+
+package circuit
+
+//RejectHandler is told about a request the breaker short-circuited, err is why it was rejected
+type RejectHandler func(name string, state State, err error)
+
+// WithOnReject call handler whenever the breaker rejects a request without running it,
+// with ErrServiceUnavailable, ErrTooManyRequests or ErrLoadShed, such as to signal backpressure upstream.
+// It is never called for a failure of work. Like OnStateChanged it runs with the mutex held, keep it short.
+func WithOnReject(handler RejectHandler) Option {
+	return func(opts *Options) {
+		opts.OnReject = handler
+	}
+}
+
+//notifyReject tell OnReject about a rejection, must be called with the mutex held
+func (rb *RequestBreaker) notifyReject(err error) {
+	if handler := rb.options.OnReject; handler != nil {
+		state := rb.state
+		rb.guard("OnReject", func() { handler(rb.options.Name, state, err) })
+	}
+}
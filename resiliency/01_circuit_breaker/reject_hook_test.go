@@ -0,0 +1,55 @@
+package circuit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestOnRejectOnlyOnShortCircuit(t *testing.T) {
+
+	clock := newFakeClock()
+	var rejections []string
+	rb := NewRequestBreaker(ActionName("rejecting"), WithClock(clock), Timeout(time.Second), MaxRequests(1),
+		WithOnReject(func(name string, state State, err error) {
+			rejections = append(rejections, fmt.Sprintf("%s %s: %v", name, state, err))
+		}))
+
+	//真正的失败不算拒绝
+	tripBreaker(t, rb)
+	if len(rejections) != 0 {
+		t.Fatalf("failures of work should not call OnReject, got %q", rejections)
+	}
+
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Fatalf("expected ErrServiceUnavailable, got %v", err)
+	}
+
+	//半开，唯一的试探名额被占着
+	clock.Advance(time.Second + time.Nanosecond)
+	release, started := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			close(started)
+			<-release
+			return nil, errBackendDown
+		})
+	}()
+	<-started
+	if _, err := rb.Do(succeedWork); err != ErrTooManyRequests {
+		t.Fatalf("expected ErrTooManyRequests, got %v", err)
+	}
+	close(release)
+	<-done
+
+	want := []string{
+		"rejecting open: " + ErrServiceUnavailable.Error(),
+		"rejecting half-open: " + ErrTooManyRequests.Error(),
+	}
+	if fmt.Sprint(rejections) != fmt.Sprint(want) {
+		t.Errorf("rejections:\n got %q\nwant %q", rejections, want)
+	}
+}
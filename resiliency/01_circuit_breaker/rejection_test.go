@@ -0,0 +1,111 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRejectionReasons(t *testing.T) {
+
+	ok := func(ctx context.Context) (interface{}, error) { return nil, nil }
+
+	t.Run("open", func(t *testing.T) {
+		rb := NewRequestBreaker()
+		tripBreaker(t, rb)
+
+		if _, err := rb.Do(ok); err != ErrServiceUnavailable {
+			t.Fatalf("expected ErrServiceUnavailable, got %v", err)
+		}
+		cnt := rb.Counts()
+		if cnt.RejectedOpen != 1 || cnt.RejectedTooManyRequests != 0 || cnt.RejectedShed != 0 {
+			t.Errorf("expected one open rejection, got %+v", cnt)
+		}
+	})
+
+	t.Run("too many requests", func(t *testing.T) {
+		rb := NewRequestBreaker(Timeout(time.Millisecond), MaxRequests(1))
+		tripBreaker(t, rb)
+		time.Sleep(5 * time.Millisecond)
+
+		release := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			rb.Do(func(ctx context.Context) (interface{}, error) {
+				<-release
+				return nil, nil
+			})
+			close(done)
+		}()
+
+		for rb.State() != StateHalfOpen {
+			time.Sleep(time.Millisecond)
+		}
+		_, err := rb.Do(ok)
+		cnt := rb.Counts()
+		close(release)
+		<-done
+
+		if err != ErrTooManyRequests {
+			t.Fatalf("expected ErrTooManyRequests, got %v", err)
+		}
+		if cnt.RejectedTooManyRequests != 1 || cnt.RejectedOpen != 0 {
+			t.Errorf("expected one too-many-requests rejection, got %+v", cnt)
+		}
+	})
+
+	t.Run("shed", func(t *testing.T) {
+		rb := NewRequestBreaker(WithLoadShedding(1))
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			rb.Do(func(ctx context.Context) (interface{}, error) {
+				close(started)
+				<-release
+				return nil, nil
+			})
+			close(done)
+		}()
+
+		<-started
+		_, err := rb.Do(ok)
+		cnt := rb.Counts()
+		close(release)
+		<-done
+
+		if err != ErrLoadShed {
+			t.Fatalf("expected ErrLoadShed, got %v", err)
+		}
+		if cnt.RejectedShed != 1 || cnt.RejectedOpen != 0 {
+			t.Errorf("expected one shed rejection, got %+v", cnt)
+		}
+	})
+}
+
+func TestTryDoReportsAdmission(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	admitted, res, err := rb.TryDo(func(ctx context.Context) (interface{}, error) {
+		return "partial", errBackendDown
+	})
+	if !admitted || err != errBackendDown || res != "partial" {
+		t.Errorf("work ran and failed, got admitted=%v %v %v", admitted, res, err)
+	}
+
+	tripBreaker(t, rb)
+
+	ran := false
+	admitted, res, err = rb.TryDo(func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	if admitted || ran || res != nil {
+		t.Errorf("open breaker should not admit, got admitted=%v ran=%v", admitted, ran)
+	}
+	if err != ErrServiceUnavailable {
+		t.Errorf("expected ErrServiceUnavailable, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:38:10
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package circuit
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+////////////////////////////////
+/// 两阶段提交
+/// Prepare 先占一个放行名额，真正的调用在别处完成之后再 Commit 结果，或者 Rollback 放弃
+/// 名额有有效期，忘了提交的令牌到期后自动归还
+////////////////////////////////
+
+//ErrTokenExpired is returned by Commit and Rollback for a token which expired or was already used
+var ErrTokenExpired = newBreakerError("token expired or already used")
+
+//Token is a reservation of admission made by Prepare
+type Token struct {
+	id         uint64
+	generation uint32
+	expires    time.Time
+}
+
+//Expires return when the reservation is released if neither committed nor rolled back
+func (t Token) Expires() time.Time {
+	return t.expires
+}
+
+//WithReservationTTL set how long a token of Prepare holds its reservation, default 30 seconds
+func WithReservationTTL(ttl time.Duration) Option {
+	return func(opts *Options) {
+		opts.ReservationTTL = ttl
+	}
+}
+
+// Prepare reserves admission for a request which runs elsewhere, just like Do would admit it,
+// it returns the rejection error when Do would reject, a half-open probe slot is taken by the reservation.
+// The returned token must be passed to Commit or Rollback before it expires.
+func (rb *RequestBreaker) Prepare() (Token, error) {
+	rb.lazyInit()
+
+	generation, err := rb.beforeRequest(requestTags{})
+	if err != nil {
+		return Token{}, err
+	}
+
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	if rb.reservations == nil {
+		rb.reservations = make(map[uint64]Token)
+	}
+	rb.lastToken++
+	token := Token{id: rb.lastToken, generation: generation, expires: rb.now().Add(rb.options.ReservationTTL)}
+	rb.reservations[token.id] = token
+	return token, nil
+}
+
+//Commit record outcome of the reserved request, nil means success, just like the result of work in Do
+func (rb *RequestBreaker) Commit(token Token, outcome error) error {
+	rb.lazyInit()
+
+	rb.mutex.Lock()
+	ok := rb.takeReservation(token)
+	rb.mutex.Unlock()
+	if !ok {
+		return ErrTokenExpired
+	}
+
+	rb.observeHealth(outcome, 0)
+	rb.reportResult(context.Background(), outcome)
+	if rb.options.MaxInFlight > 0 {
+		atomic.AddInt32(&rb.inflight, -1)
+	}
+	rb.afterRequest(token.generation, outcome, unmeasured)
+	return nil
+}
+
+//Rollback release the reservation of token without counting anything
+func (rb *RequestBreaker) Rollback(token Token) error {
+	rb.lazyInit()
+
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	if !rb.takeReservation(token) {
+		return ErrTokenExpired
+	}
+	rb.release(token)
+	return nil
+}
+
+//takeReservation remove token from the reservations, false if it is unknown or expired, must be called with the mutex held
+func (rb *RequestBreaker) takeReservation(token Token) bool {
+	rb.sweepReservations()
+	if _, ok := rb.reservations[token.id]; !ok {
+		return false
+	}
+	delete(rb.reservations, token.id)
+	return true
+}
+
+//sweepReservations release the expired reservations, must be called with the mutex held
+func (rb *RequestBreaker) sweepReservations() {
+	if len(rb.reservations) == 0 {
+		return
+	}
+	now := rb.now()
+	for id, token := range rb.reservations {
+		if !now.Before(token.expires) {
+			delete(rb.reservations, id)
+			rb.release(token)
+		}
+	}
+}
+
+//release give back what beforeRequest took for token, must be called with the mutex held
+func (rb *RequestBreaker) release(token Token) {
+	if rb.options.MaxInFlight > 0 {
+		atomic.AddInt32(&rb.inflight, -1)
+	}
+	rb.releaseProbe(token.generation)
+}
+
+//releaseProbe give back the probe slot of a request admitted in generation, must be called with the mutex held
+func (rb *RequestBreaker) releaseProbe(generation uint32) {
+	//同一轮半开状态里，归还试探名额
+	if generation == rb.generation && rb.state == StateHalfOpen && rb.probes > 0 {
+		rb.probes--
+	}
+}
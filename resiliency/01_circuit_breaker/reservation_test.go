@@ -0,0 +1,91 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPrepareCommit(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	token, err := rb.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rb.Commit(token, nil); err != nil {
+		t.Fatal(err)
+	}
+	if c := rb.Counts(); c.TotalSuccesses != 1 || c.Requests != 1 {
+		t.Errorf("commit should count the success, got %+v", c)
+	}
+	if err := rb.Commit(token, nil); err != ErrTokenExpired {
+		t.Errorf("a token can be committed only once, got %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		token, _ := rb.Prepare()
+		rb.Commit(token, errBackendDown)
+	}
+	if _, err := rb.Prepare(); err != ErrServiceUnavailable {
+		t.Errorf("committed failures should trip the breaker, got %v", err)
+	}
+}
+
+//halfOpenBreaker return a breaker with a single probe slot, just turned half-open
+func halfOpenBreaker(t *testing.T, clock *fakeClock) *RequestBreaker {
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second), MaxRequests(1),
+		WithShoulderHalfToOpen(2), WithReservationTTL(time.Minute))
+	tripBreaker(t, rb)
+	clock.Advance(2 * time.Second)
+	return rb
+}
+
+func TestPrepareRollback(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := halfOpenBreaker(t, clock)
+
+	token, err := rb.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rb.Do(succeedWork); err != ErrTooManyRequests {
+		t.Fatalf("the reservation should hold the probe slot, got %v", err)
+	}
+
+	if err := rb.Rollback(token); err != nil {
+		t.Fatal(err)
+	}
+	if c := rb.Counts(); c.ProbeSuccesses != 0 || c.ProbeFailures != 0 {
+		t.Errorf("rollback should count nothing, got %+v", c)
+	}
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Errorf("rollback should release the probe slot, got %v", err)
+	}
+}
+
+func TestReservationExpires(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := halfOpenBreaker(t, clock)
+
+	token, err := rb.Prepare()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Expires() != clock.Now().Add(time.Minute) {
+		t.Errorf("unexpected expiry %v", token.Expires())
+	}
+
+	clock.Advance(time.Minute)
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Errorf("an expired reservation should release the probe slot, got %v", err)
+	}
+	if err := rb.Commit(token, nil); err != ErrTokenExpired {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+	if err := rb.Rollback(token); err != ErrTokenExpired {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+}
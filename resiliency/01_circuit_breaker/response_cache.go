@@ -0,0 +1,50 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:06:09
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:19:16
+ */
+
+package circuit
+
+import (
+	"context"
+	"time"
+)
+
+type responseCache struct {
+	result   interface{}
+	storedAt time.Time
+	valid    bool
+}
+
+// DoWithCache is like Do, but remembers the last successful result.
+// When the breaker rejects the request (open, half-open full or shed), the remembered result
+// is returned instead of the rejection error as long as it is younger than the ttl set by WithResponseCache.
+// Errors returned by work itself are never hidden.
+func (rb *RequestBreaker) DoWithCache(work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	rb.lazyInit()
+	ctx := rb.options.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	//回退链的结果不是后端的结果，不能缓存，只在缓存也帮不上时才用
+	admitted, result, err := rb.do(ctx, work, false)
+
+	rb.mutex.Lock()
+	if err == nil {
+		rb.cache = responseCache{result: result, storedAt: rb.now(), valid: true}
+		rb.mutex.Unlock()
+		return result, nil
+	}
+	if IsRejection(err) && rb.cache.valid && rb.now().Sub(rb.cache.storedAt) < rb.options.ResponseCacheTTL {
+		cached := rb.cache.result
+		rb.mutex.Unlock()
+		return cached, nil
+	}
+	rb.mutex.Unlock()
+
+	return rb.withFallback(admitted, result, err)
+}
@@ -0,0 +1,62 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDoWithCacheServesWhileOpen(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Hour), WithResponseCache(time.Minute))
+
+	res, err := rb.DoWithCache(func(ctx context.Context) (interface{}, error) { return "fresh", nil })
+	if err != nil || res != "fresh" {
+		t.Fatalf("unexpected %v %v", res, err)
+	}
+
+	//failures of work itself are not hidden by the cache
+	for i := 0; i < 3; i++ {
+		if _, err := rb.DoWithCache(func(ctx context.Context) (interface{}, error) {
+			return nil, errBackendDown
+		}); err != errBackendDown {
+			t.Fatalf("expected work error, got %v", err)
+		}
+	}
+	if rb.State() != StateOpen {
+		t.Fatalf("breaker should be open, got %s", rb.State())
+	}
+
+	untouched := func(ctx context.Context) (interface{}, error) {
+		t.Error("work should not run while open")
+		return nil, nil
+	}
+
+	clock.Advance(30 * time.Second)
+	res, err = rb.DoWithCache(untouched)
+	if err != nil || res != "fresh" {
+		t.Errorf("cached value should be served within ttl, got %v %v", res, err)
+	}
+
+	clock.Advance(31 * time.Second)
+	if _, err = rb.DoWithCache(untouched); err != ErrServiceUnavailable {
+		t.Errorf("stale cache should return the rejection, got %v", err)
+	}
+}
+
+func TestDoWithCacheDoesNotCacheFallback(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), WithResponseCache(time.Hour),
+		WithFallbackChain(func(err error) (interface{}, error) { return "default", nil }))
+	tripBreaker(t, rb)
+
+	//没有缓存，回退链兜底
+	if res, err := rb.DoWithCache(succeedWork); res != "default" || err != nil {
+		t.Fatalf("the fallback should answer without a cached result, got %v %v", res, err)
+	}
+	if rb.cache.valid {
+		t.Errorf("the fallback value should not be cached, got %+v", rb.cache)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:03:32
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:03:32
+ */
+
+package circuit
+
+import "context"
+
+//Result hold either a value or an error of a work done through the breaker
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+//IsOk report whether work succeeded
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+//Value return the value of work, it is the zero value when work failed
+func (r Result[T]) Value() T {
+	return r.value
+}
+
+//Err return the error of work or the rejection error of breaker
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+//OrElse return the value when ok and fallback otherwise
+func (r Result[T]) OrElse(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
+
+// DoResult run work through rb and wrap the outcome into a Result.
+// When rb rejects the request, the Result carries the rejection error such as ErrServiceUnavailable.
+func DoResult[T any](rb *RequestBreaker, work func(ctx context.Context) (T, error)) Result[T] {
+
+	res, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+		return work(ctx)
+	})
+	if err != nil {
+		return Result[T]{err: err}
+	}
+
+	value, _ := res.(T)
+	return Result[T]{value: value}
+}
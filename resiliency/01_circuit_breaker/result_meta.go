@@ -0,0 +1,79 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:27:01
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:41:06
+ */
+
+package circuit
+
+import (
+	"context"
+	"log/slog"
+)
+
+//ResultHandler receive the outcome of every request which ran, meta is nil unless it ran through DoWithMeta
+type ResultHandler func(name string, err error, meta map[string]string)
+
+//WithOnResult call handler after each request which ran, rejected requests are not reported
+func WithOnResult(handler ResultHandler) Option {
+	return func(opts *Options) {
+		opts.OnResult = handler
+	}
+}
+
+type metaKey struct{}
+
+//metaHolder carry the metadata of DoWithMeta from work back to the breaker
+type metaHolder struct {
+	meta map[string]string
+}
+
+// DoWithMeta is like Do, but work also returns metadata about its outcome,
+// such as "served from cache" or the upstream region. The metadata is passed to
+// the OnResult handler and the logger along with the outcome, it never affects tripping.
+func (rb *RequestBreaker) DoWithMeta(work func(ctx context.Context) (interface{}, map[string]string, error)) (interface{}, error) {
+
+	ctx := rb.options.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	holder := &metaHolder{}
+	return rb.DoContext(context.WithValue(ctx, metaKey{}, holder), func(ctx context.Context) (interface{}, error) {
+		result, meta, err := work(ctx)
+		holder.meta = meta
+		return result, err
+	})
+}
+
+//reportResult hand the outcome of an executed request to OnResult and the logger
+func (rb *RequestBreaker) reportResult(ctx context.Context, err error) {
+
+	handler, logger := rb.options.OnResult, rb.options.Logger
+	if handler == nil && logger == nil {
+		return
+	}
+
+	var meta map[string]string
+	if holder, ok := ctx.Value(metaKey{}).(*metaHolder); ok {
+		meta = holder.meta
+	}
+
+	rb.hook(func() {
+		if handler != nil {
+			rb.guard("OnResult", func() { handler(rb.options.Name, err, meta) })
+		}
+		if logger != nil && meta != nil {
+			attrs := make([]any, 0, len(meta))
+			for k, v := range meta {
+				attrs = append(attrs, slog.String(k, v))
+			}
+			logger.LogAttrs(context.Background(), slog.LevelDebug, "circuit breaker request done",
+				slog.String("name", rb.options.Name),
+				slog.Bool("success", err == nil),
+				slog.Group("meta", attrs...))
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDoWithMetaReachesOnResult(t *testing.T) {
+
+	var gotErr error
+	var gotMeta map[string]string
+	calls := 0
+	rb := NewRequestBreaker(ActionName("catalog"), WithOnResult(func(name string, err error, meta map[string]string) {
+		calls++
+		gotErr, gotMeta = err, meta
+		if name != "catalog" {
+			t.Errorf("unexpected name %s", name)
+		}
+	}))
+
+	res, err := rb.DoWithMeta(func(ctx context.Context) (interface{}, map[string]string, error) {
+		return "item", map[string]string{"source": "cache", "region": "eu-west"}, nil
+	})
+	if err != nil || res != "item" {
+		t.Fatalf("unexpected %v %v", res, err)
+	}
+	if calls != 1 || gotErr != nil || gotMeta["source"] != "cache" || gotMeta["region"] != "eu-west" {
+		t.Errorf("metadata should reach OnResult, got %d calls %v %v", calls, gotErr, gotMeta)
+	}
+
+	//plain Do reports without metadata, rejections are not reported
+	rb.Do(succeedWork)
+	if calls != 2 || gotMeta != nil {
+		t.Errorf("Do should report nil metadata, got %v", gotMeta)
+	}
+	tripBreaker(t, rb)
+	calls = 0
+	rb.DoWithMeta(func(ctx context.Context) (interface{}, map[string]string, error) {
+		return nil, map[string]string{"source": "upstream"}, nil
+	})
+	if calls != 0 {
+		t.Error("rejected request should not be reported")
+	}
+}
@@ -0,0 +1,41 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDoResult(t *testing.T) {
+
+	rb := NewRequestBreaker()
+
+	ok := DoResult(rb, func(ctx context.Context) (int, error) { return 42, nil })
+	if !ok.IsOk() || ok.Value() != 42 || ok.Err() != nil || ok.OrElse(-1) != 42 {
+		t.Errorf("unexpected ok result: %+v", ok)
+	}
+
+	failed := DoResult(rb, func(ctx context.Context) (int, error) { return 7, errBackendDown })
+	if failed.IsOk() || failed.Err() != errBackendDown || failed.Value() != 0 {
+		t.Errorf("unexpected failed result: %+v", failed)
+	}
+	if failed.OrElse(-1) != -1 {
+		t.Errorf("OrElse should return the fallback for a failure, got %d", failed.OrElse(-1))
+	}
+
+	tripBreaker(t, rb)
+
+	ran := false
+	rejected := DoResult(rb, func(ctx context.Context) (string, error) {
+		ran = true
+		return "unreachable", nil
+	})
+	if ran {
+		t.Error("work should not run while open")
+	}
+	if rejected.IsOk() || rejected.Err() != ErrServiceUnavailable {
+		t.Errorf("expected rejection, got %+v", rejected)
+	}
+	if rejected.OrElse("cached") != "cached" {
+		t.Errorf("OrElse should return the fallback for a rejection, got %s", rejected.OrElse("cached"))
+	}
+}
@@ -0,0 +1,40 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:11:00
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:36:49
+ */
+
+package circuit
+
+import "context"
+
+////////////////////////////////
+/// 有生命周期的断路器
+/// 断路器本身没有后台goroutine，但是每个请求可能有，比如强制超时模式下执行work的goroutine
+/// 跟着一个请求范围创建的断路器，范围结束时这些goroutine也要结束
+////////////////////////////////
+
+// NewRequestBreakerWithContext returns a breaker living as long as ctx.
+// Once ctx is done the context of every running request is canceled through context.AfterFunc,
+// so the goroutines of WithForceTimeout and work honoring its context return,
+// those requests are ignored just like the ones canceled by their caller.
+// New requests are then rejected with ctx.Err() without being counted.
+func NewRequestBreakerWithContext(ctx context.Context, opts ...Option) *RequestBreaker {
+	opts = append(opts[:len(opts):len(opts)], func(opts *Options) {
+		opts.Scope = ctx
+	})
+	return NewRequestBreaker(opts...)
+}
+
+//cancelWith derive a context of ctx which is also canceled with the cause of other when other is done,
+//call the returned func when finished
+func cancelWith(ctx, other context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(other, func() { cancel(context.Cause(other)) })
+	return ctx, func() {
+		stop()
+		cancel(nil)
+	}
+}
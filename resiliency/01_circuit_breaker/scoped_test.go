@@ -0,0 +1,58 @@
+package circuit
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+//waitGoroutines wait until there are at most n goroutines, it returns the last count
+func waitGoroutines(n int) int {
+	count := runtime.NumGoroutine()
+	for i := 0; i < 100 && count > n; i++ {
+		time.Sleep(10 * time.Millisecond)
+		count = runtime.NumGoroutine()
+	}
+	return count
+}
+
+func TestScopedBreakerStopsGoroutines(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+
+	scope, cancel := context.WithCancel(context.Background())
+	rb := NewRequestBreakerWithContext(scope, WithForceTimeout(true))
+
+	//调用方的context没有deadline，work只能靠断路器的生命周期结束
+	var started, done sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+				started.Done()
+				<-ctx.Done()
+				return nil, ctx.Err()
+			})
+		}()
+	}
+	started.Wait()
+
+	cancel()
+	done.Wait()
+	if after := waitGoroutines(before); after > before {
+		t.Errorf("goroutines of the scoped breaker should exit, %d before and %d after", before, after)
+	}
+
+	ran := false
+	_, err := rb.Do(func(ctx context.Context) (interface{}, error) { ran = true; return nil, nil })
+	if err != context.Canceled || ran {
+		t.Errorf("a breaker out of scope should reject, got %v, ran %v", err, ran)
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 0 || rb.State() != StateClosed {
+		t.Errorf("the end of scope says nothing about the backend, got %+v", cnt)
+	}
+}
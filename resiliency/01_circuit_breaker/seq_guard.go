@@ -0,0 +1,39 @@
+//go:build go1.23
+
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:45:32
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:45:32
+ */
+
+package circuit
+
+import (
+	"context"
+	"iter"
+)
+
+////////////////////////////////
+/// 用断路器保护 range-over-func 迭代器中的每一项
+/// 和 GuardChannel 一样，打开之后剩下的项直接得到拒绝错误
+/// 需要 Go 1.23，模块仍然是 go 1.21，这个文件单独用构建约束升级
+////////////////////////////////
+
+// DoStream processes each item of seq through rb and yields the item with its result.
+// The state of rb is updated after every item, so failures partway trip it and the remaining
+// items are short-circuited with the rejection error of rb without calling process.
+// Nothing runs until the returned sequence is ranged over, stopping the range stops pulling from seq.
+func DoStream[T any](seq iter.Seq[T], rb *RequestBreaker, process func(T) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for item := range seq {
+			_, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+				return nil, process(item)
+			})
+			if !yield(item, err) {
+				return
+			}
+		}
+	}
+}
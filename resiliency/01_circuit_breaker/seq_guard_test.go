@@ -0,0 +1,64 @@
+//go:build go1.23
+
+package circuit
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDoStreamTripsPartway(t *testing.T) {
+
+	var processed []int
+	//2,3,4 fail and trip the breaker, the items after them are short-circuited
+	results := DoStream(slices.Values([]int{0, 1, 2, 3, 4, 5, 6, 7}), NewRequestBreaker(), func(i int) error {
+		processed = append(processed, i)
+		if i >= 2 && i <= 4 {
+			return errBackendDown
+		}
+		return nil
+	})
+
+	var ok, failed, rejected []int
+	for item, err := range results {
+		switch err {
+		case nil:
+			ok = append(ok, item)
+		case errBackendDown:
+			failed = append(failed, item)
+		case ErrServiceUnavailable:
+			rejected = append(rejected, item)
+		default:
+			t.Fatalf("item %d: unexpected error %v", item, err)
+		}
+	}
+
+	if !slices.Equal(ok, []int{0, 1}) || !slices.Equal(failed, []int{2, 3, 4}) || !slices.Equal(rejected, []int{5, 6, 7}) {
+		t.Errorf("unexpected results: ok %v failed %v rejected %v", ok, failed, rejected)
+	}
+	if !slices.Equal(processed, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("short-circuited items should not be processed, processed %v", processed)
+	}
+}
+
+func TestDoStreamStopsPulling(t *testing.T) {
+
+	pulled := 0
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+
+	for item := range DoStream(seq, NewRequestBreaker(), func(int) error { return nil }) {
+		if item == 2 {
+			break
+		}
+	}
+	if pulled != 3 {
+		t.Errorf("breaking the range should stop pulling, pulled %d", pulled)
+	}
+}
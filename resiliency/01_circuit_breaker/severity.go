@@ -0,0 +1,89 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:03:02
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:29:21
+ */
+
+package circuit
+
+////////////////////////////////
+/// 按严重程度加权计数失败
+/// 偶尔断一次连接是噪音，持续的故障要尽快打开
+/// 持续性失败在打开阈值里占更大的比重
+////////////////////////////////
+
+//Severity tell a short blip from a sustained failure
+type Severity int
+
+//severities of a failure
+const (
+	//SeverityTransient is a failure likely gone on the next try, such as a dropped connection
+	SeverityTransient Severity = iota
+	//SeverityPersistent is a failure likely to last, such as connection refused
+	SeverityPersistent
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityTransient:
+		return "transient"
+	case SeverityPersistent:
+		return "persistent"
+	}
+	return "unknown"
+}
+
+//WithSeverityClassifier tell the severity of every counted failure, without it all failures are transient
+func WithSeverityClassifier(classify func(error) Severity) Option {
+	return func(opts *Options) {
+		opts.Severity = classify
+	}
+}
+
+//TripOnWeightedFailures open a closed breaker once the weighted failures of current generation reach threshold,
+//a transient failure weighs 1 and a persistent one weighs persistentWeight, e.g. TripOnWeightedFailures(6, 3).
+//It trips on top of the normal break condition, nothing trips during the warm-up
+func TripOnWeightedFailures(threshold, persistentWeight uint32) Option {
+	return func(opts *Options) {
+		opts.WeightedThreshold = threshold
+		opts.PersistentWeight = persistentWeight
+	}
+}
+
+//FailureSeverities return the failures of current generation by severity
+func (rb *RequestBreaker) FailureSeverities() (transient, persistent uint32) {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+	return rb.severities[SeverityTransient], rb.severities[SeverityPersistent]
+}
+
+//countSeverity count err by its severity, must be called with the mutex held
+func (rb *RequestBreaker) countSeverity(err error) {
+	severity := SeverityTransient
+	if classify := rb.options.Severity; classify != nil {
+		rb.guard("Severity", func() { severity = classify(err) })
+	}
+	if severity != SeverityPersistent {
+		severity = SeverityTransient
+	}
+	rb.severities[severity]++
+}
+
+//weightedTrip report whether the weighted failures reach the threshold, must be called with the mutex held
+func (rb *RequestBreaker) weightedTrip() bool {
+	threshold := rb.options.WeightedThreshold
+	if threshold == 0 || rb.now().Before(rb.warmUntil) {
+		return false
+	}
+	weighted := uint64(rb.severities[SeverityTransient]) +
+		uint64(rb.severities[SeverityPersistent])*uint64(rb.options.PersistentWeight)
+	if weighted < uint64(threshold) {
+		return false
+	}
+	rb.because("weighted failures %d >= %d: %d transient, %d persistent", weighted, threshold,
+		rb.severities[SeverityTransient], rb.severities[SeverityPersistent])
+	return true
+}
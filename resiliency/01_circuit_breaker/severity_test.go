@@ -0,0 +1,67 @@
+package circuit
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errRefused = errors.New("connection refused")
+
+func bySeverity(err error) Severity {
+	if errors.Is(err, errRefused) {
+		return SeverityPersistent
+	}
+	return SeverityTransient
+}
+
+//failuresToTrip feed errs in turn until the breaker opens, 0 if it never does
+func failuresToTrip(rb *RequestBreaker, errs ...error) int {
+	for i := 0; i < 20; i++ {
+		err := errs[i%len(errs)]
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, err })
+		if rb.State() == StateOpen {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func TestWeightedFailuresTripSooner(t *testing.T) {
+
+	//正常条件不会打开，只看加权和
+	newBreaker := func() *RequestBreaker {
+		return NewRequestBreaker(
+			WithBreakCondition(func(state State, cnt Counts) bool { return false }),
+			WithSeverityClassifier(bySeverity),
+			TripOnWeightedFailures(6, 3),
+		)
+	}
+
+	cases := []struct {
+		name string
+		errs []error
+		want int
+	}{
+		{"transient only", []error{errBackendDown}, 6},
+		{"mixed", []error{errBackendDown, errRefused}, 4},
+		{"persistent only", []error{errRefused}, 2},
+	}
+	for _, c := range cases {
+		if got := failuresToTrip(newBreaker(), c.errs...); got != c.want {
+			t.Errorf("%s: expected to trip after %d failures, got %d", c.name, c.want, got)
+		}
+	}
+}
+
+func TestFailureSeverities(t *testing.T) {
+
+	rb := NewRequestBreaker(WithSeverityClassifier(bySeverity))
+	for _, err := range []error{errBackendDown, errRefused} {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, err })
+	}
+
+	if transient, persistent := rb.FailureSeverities(); transient != 1 || persistent != 1 {
+		t.Errorf("expected 1 transient and 1 persistent failure, got %d and %d", transient, persistent)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:00:25
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:24:50
+ */
+
+package circuit
+
+import (
+	"context"
+	"sync"
+)
+
+////////////////////////////////
+/// singleflight + 断路器
+/// 同一个key同时只有一个请求真正执行，其他并发的调用者等待并共享结果
+/// 断路器只记录一次结果
+////////////////////////////////
+
+type sharedCall struct {
+	wg     sync.WaitGroup
+	result interface{}
+	err    error
+	dups   int
+	panic  interface{} //领头的 work panic 的值，等待的调用者同样 panic
+}
+
+type sharedGroup struct {
+	mutex sync.Mutex
+	calls map[string]*sharedCall
+}
+
+// DoShared run work through the breaker, concurrent callers with the same key
+// wait for the call in flight and share its result and error.
+// Only one outcome is counted by the breaker for all of them.
+// When work panics the panic is passed on to every caller waiting for it.
+func (rb *RequestBreaker) DoShared(key string, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	g := &rb.shared
+
+	g.mutex.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*sharedCall)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mutex.Unlock()
+		c.wg.Wait()
+		if c.panic != nil {
+			panic(c.panic)
+		}
+		return c.result, c.err
+	}
+	c := &sharedCall{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mutex.Unlock()
+
+	//work panic 的时候也要放开等待的调用者，不然它们永远等下去
+	finished := false
+	defer func() {
+		g.mutex.Lock()
+		delete(g.calls, key)
+		g.mutex.Unlock()
+		if !finished {
+			c.panic = recover()
+			if c.panic == nil {
+				c.err = errWorkPanicked
+			}
+		}
+		c.wg.Done()
+		if c.panic != nil {
+			panic(c.panic)
+		}
+	}()
+
+	c.result, c.err = rb.Do(work)
+	finished = true
+
+	return c.result, c.err
+}
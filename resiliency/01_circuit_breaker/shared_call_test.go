@@ -0,0 +1,109 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDoSharedRunsWorkOnce(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("shared"))
+
+	const callers = 50
+	var runs int32
+	release := make(chan struct{})
+
+	work := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&runs, 1)
+		<-release
+		return "payload", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := rb.DoShared("user:1", work)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = res
+		}(i)
+	}
+
+	//wait until every caller joined the call in flight
+	for {
+		rb.shared.mutex.Lock()
+		c := rb.shared.calls["user:1"]
+		joined := c != nil && c.dups == callers-1
+		rb.shared.mutex.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if runs != 1 {
+		t.Errorf("work should run once, ran %d times", runs)
+	}
+	for i, res := range results {
+		if res != "payload" {
+			t.Errorf("caller %d got %v", i, res)
+		}
+	}
+	if cnt := rb.Counts(); cnt.Requests != 1 {
+		t.Errorf("breaker should count one outcome, got %+v", cnt)
+	}
+}
+
+func TestDoSharedPassesPanicToWaiters(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	release := make(chan struct{})
+	work := func(ctx context.Context) (interface{}, error) {
+		<-release
+		panic("boom")
+	}
+
+	const callers = 3
+	panics := make(chan interface{}, callers)
+	for i := 0; i < callers; i++ {
+		go func() {
+			defer func() { panics <- recover() }()
+			rb.DoShared("key", work)
+		}()
+	}
+	for {
+		rb.shared.mutex.Lock()
+		c := rb.shared.calls["key"]
+		joined := c != nil && c.dups == callers-1
+		rb.shared.mutex.Unlock()
+		if joined {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	//领头的调用者和等待的调用者都收到同一个 panic
+	for i := 0; i < callers; i++ {
+		select {
+		case r := <-panics:
+			if r != "boom" {
+				t.Errorf("every caller should get the panic, got %v", r)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("waiters are blocked by the panicking call")
+		}
+	}
+	if _, err := rb.DoShared("key", succeedWork); err != nil {
+		t.Errorf("the key should be free again, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:08:54
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:08:54
+ */
+
+package circuit
+
+////////////////////////////////
+/// 打开和恢复的信号
+/// 事件循环风格的调用方不想轮询State()，也不想注册回调，用select等一个channel
+/// 和context.Done()一样，channel关闭就是信号，一个channel只用一次
+////////////////////////////////
+
+// OpenSignal returns a channel closed when the breaker opens, it is already closed while the breaker is open.
+// The channel fires once: after the breaker recovers, that is closes again, OpenSignal returns
+// a new channel for the next trip, so call it again for every cycle instead of keeping the old one.
+// A half-open breaker has not recovered yet, it keeps the closed channel of its trip.
+func (rb *RequestBreaker) OpenSignal() <-chan struct{} {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	if rb.openSignal == nil {
+		rb.openSignal = make(chan struct{})
+		rb.signal()
+	}
+	return rb.openSignal
+}
+
+// RecoverySignal returns a channel closed when the breaker closes, it is already closed while the breaker is closed.
+// Just like OpenSignal the channel fires once: after the next trip RecoverySignal returns a new channel
+// closed on the following recovery.
+func (rb *RequestBreaker) RecoverySignal() <-chan struct{} {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	if rb.recoverSignal == nil {
+		rb.recoverSignal = make(chan struct{})
+		rb.signal()
+	}
+	return rb.recoverSignal
+}
+
+//signal close the channel of current state and drop the fired one of the other, must be called with the mutex held
+func (rb *RequestBreaker) signal() {
+	switch rb.state {
+	case StateOpen:
+		fire(rb.openSignal)
+		if fired(rb.recoverSignal) {
+			rb.recoverSignal = nil
+		}
+	case StateClosed:
+		fire(rb.recoverSignal)
+		if fired(rb.openSignal) {
+			rb.openSignal = nil
+		}
+	}
+}
+
+func fire(ch chan struct{}) {
+	if ch != nil && !fired(ch) {
+		close(ch)
+	}
+}
+
+func fired(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,71 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOpenSignalFiresOnTrip(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	opened := rb.OpenSignal()
+	recovered := rb.RecoverySignal()
+
+	select {
+	case <-opened:
+		t.Fatal("open signal fired on a closed breaker")
+	case <-recovered:
+	default:
+		t.Fatal("recovery signal should already fire on a closed breaker")
+	}
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			rb.Do(failWork)
+		}
+	}()
+	select {
+	case <-opened:
+	case <-time.After(time.Second):
+		t.Fatal("open signal should fire when the breaker trips")
+	}
+	if rb.State() != StateOpen {
+		t.Errorf("expected open breaker, got %s", rb.State())
+	}
+}
+
+func TestSignalsAcrossCycles(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(time.Second))
+
+	tripBreaker(t, rb)
+	recovered := rb.RecoverySignal()
+	select {
+	case <-recovered:
+		t.Fatal("a tripped breaker should get a new recovery signal")
+	default:
+	}
+
+	//半开还不算恢复
+	clock.Advance(2 * time.Second)
+	rb.Do(succeedWork)
+	select {
+	case <-recovered:
+	default:
+		t.Fatalf("recovery signal should fire once the breaker closes, state %s", rb.State())
+	}
+
+	opened := rb.OpenSignal()
+	select {
+	case <-opened:
+		t.Fatal("a recovered breaker should get a new open signal")
+	default:
+	}
+	tripBreaker(t, rb)
+	select {
+	case <-opened:
+	default:
+		t.Fatal("new open signal should fire on the next trip")
+	}
+}
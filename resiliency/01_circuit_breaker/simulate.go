@@ -0,0 +1,83 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:27:49
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:27:49
+ */
+
+package circuit
+
+import (
+	"context"
+	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
+)
+
+////////////////////////////////
+/// 回放模拟
+/// 用线上记录的请求(时间+结果)驱动一个断路器，时钟跟着记录前进
+/// 离线比较不同的配置会打开多少次、打开多久、拒绝多少请求
+////////////////////////////////
+
+//TraceEvent is a recorded request
+type TraceEvent struct {
+	At     time.Time
+	Failed bool
+}
+
+//SimResult is what a breaker would have done with a trace
+type SimResult struct {
+	Trips    int           //转到打开状态的次数
+	TimeOpen time.Duration //从第一个到最后一个请求之间处于打开状态的时间
+	Rejected int
+	Admitted int
+}
+
+//errSimulated is the failure of a failed trace event
+var errSimulated = errSimulatedFailure{}
+
+type errSimulatedFailure struct{}
+
+func (errSimulatedFailure) Error() string { return "simulated failure" }
+
+//Simulate replay trace, ordered by time, against a breaker built with opts,
+//the breaker runs on a manual clock which follows the timestamps of trace
+func Simulate(trace []TraceEvent, opts ...Option) SimResult {
+
+	var result SimResult
+	if len(trace) == 0 {
+		return result
+	}
+
+	mc := clock.NewManual(trace[0].At)
+	rb := NewRequestBreaker(append(opts, WithClock(mc))...)
+	rb.Events().Subscribe(ObserverFunc[StateChange](func(change StateChange) {
+		if change.To == StateOpen {
+			result.Trips++
+		}
+	}))
+
+	for _, event := range trace {
+		if d := event.At.Sub(mc.Now()); d > 0 {
+			mc.Advance(d)
+		}
+
+		failed := event.Failed
+		admitted, _, _ := rb.TryDo(func(ctx context.Context) (interface{}, error) {
+			if failed {
+				return nil, errSimulated
+			}
+			return nil, nil
+		})
+		if admitted {
+			result.Admitted++
+		} else {
+			result.Rejected++
+		}
+	}
+
+	result.TimeOpen = rb.StateDurations()[StateOpen]
+	return result
+}
@@ -0,0 +1,49 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+//syntheticTrace return a request every second, failing during the given seconds
+func syntheticTrace(seconds int, failing func(second int) bool) []TraceEvent {
+	start := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	trace := make([]TraceEvent, 0, seconds)
+	for i := 0; i < seconds; i++ {
+		trace = append(trace, TraceEvent{At: start.Add(time.Duration(i) * time.Second), Failed: failing(i)})
+	}
+	return trace
+}
+
+func TestSimulateTrace(t *testing.T) {
+
+	//两次故障: 10s-29s 和 60s-64s
+	trace := syntheticTrace(100, func(s int) bool {
+		return (s >= 10 && s < 30) || (s >= 60 && s < 65)
+	})
+
+	res := Simulate(trace, Timeout(5*time.Second), Interval(time.Minute))
+
+	//10-12 trip at 12s, half-open at 18s, probes 18-20 reopen at 20s, half-open at 26s,
+	//probes 26-28 reopen at 28s, the probe at 34s closes. 60-62 trip at 62s, the probe at 68s closes.
+	//每次打开6秒，拒绝其中的5个请求
+	if res.Trips != 4 {
+		t.Errorf("expected 4 trips, got %d", res.Trips)
+	}
+	if res.Admitted+res.Rejected != len(trace) {
+		t.Errorf("every event should be admitted or rejected, got %+v", res)
+	}
+	if res.Rejected != 5+5+5+5 {
+		t.Errorf("expected 20 rejected requests, got %d", res.Rejected)
+	}
+	if res.TimeOpen != 4*(6*time.Second) {
+		t.Errorf("expected 24s open, got %v", res.TimeOpen)
+	}
+
+	//a more tolerant config trips less
+	tolerant := Simulate(trace, Timeout(5*time.Second), Interval(time.Minute),
+		WithBreakCondition(func(_ State, cnt Counts) bool { return cnt.ConsecutiveFailures > 10 }))
+	if tolerant.Trips >= res.Trips {
+		t.Errorf("tolerant config should trip less, got %d", tolerant.Trips)
+	}
+}
@@ -0,0 +1,93 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//checkCounts report what is torn in a snapshot, empty if it is coherent
+func checkCounts(cnt Counts) string {
+	switch {
+	case cnt.Requests != cnt.TotalSuccesses+cnt.TotalFailures+cnt.ProbeSuccesses+cnt.ProbeFailures:
+		return "Requests does not add up"
+	case cnt.ConsecutiveFailures > 0 && cnt.ConsecutiveSuccesses > 0:
+		return "consecutive failures and successes at once"
+	case cnt.ConsecutiveFailures > cnt.TotalFailures+cnt.ProbeFailures:
+		return "more consecutive failures than failures"
+	case cnt.ConsecutiveSuccesses > cnt.TotalSuccesses+cnt.ProbeSuccesses:
+		return "more consecutive successes than successes"
+	}
+	return ""
+}
+
+func TestTripConditionSeesCoherentSnapshot(t *testing.T) {
+
+	const workers, calls = 8, 500
+
+	var checked int64
+
+	rb := NewRequestBreaker(
+		Timeout(time.Millisecond),
+		WithBreakCondition(func(state State, cnt Counts) bool {
+			atomic.AddInt64(&checked, 1)
+			if torn := checkCounts(cnt); torn != "" {
+				t.Errorf("%s: %+v", torn, cnt)
+			}
+			return cnt.ConsecutiveFailures > 4
+		}),
+	)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < calls; i++ {
+				fail := (w+i)%3 == 0
+				rb.Do(func(ctx context.Context) (interface{}, error) {
+					if fail {
+						return nil, errBackendDown
+					}
+					return nil, nil
+				})
+				if torn := checkCounts(rb.Counts()); torn != "" {
+					t.Errorf("Counts: %s", torn)
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	if atomic.LoadInt64(&checked) == 0 {
+		t.Fatal("trip condition was never asked")
+	}
+}
+
+func TestSnapshotsAreIndependent(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	rb.Do(succeedWork)
+
+	first := rb.Counts()
+	rb.Do(failWork)
+	second := rb.Counts()
+
+	if first.Requests != 1 || first.TotalFailures != 0 {
+		t.Errorf("a snapshot should not change after it is returned, got %+v", first)
+	}
+	if second.Requests != 2 || second.TotalFailures != 1 {
+		t.Errorf("unexpected second snapshot %+v", second)
+	}
+
+	first.Requests = 100
+	if rb.Counts().Requests != 2 {
+		t.Error("changing a snapshot should not touch the breaker")
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { rb.Counts() }); allocs != 0 {
+		t.Errorf("Counts should not allocate, got %v allocs", allocs)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:53:57
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:53:57
+ */
+
+package circuit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+////////////////////////////////
+/// 断路器面板的实时推送(Server-Sent Events)
+/// 浏览器连上之后，注册表里每个断路器的状态变化马上推过去，计数每隔一段时间推一次
+/// 连接断开时取消所有订阅
+////////////////////////////////
+
+//sseCountsInterval is how often SSEHandler pushes the counts, it also picks up newly registered breakers
+const sseCountsInterval = time.Second
+
+//sseBuffer is how many transitions wait for a slow client before new ones are dropped
+const sseBuffer = 64
+
+type transitionEvent struct {
+	Name   string    `json:"name"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason"`
+	DryRun bool      `json:"dryRun,omitempty"`
+}
+
+type countsEvent struct {
+	Name                 string `json:"name"`
+	State                string `json:"state"`
+	Requests             uint32 `json:"requests"`
+	TotalSuccesses       uint32 `json:"totalSuccesses"`
+	TotalFailures        uint32 `json:"totalFailures"`
+	ConsecutiveFailures  uint32 `json:"consecutiveFailures"`
+	ConsecutiveSuccesses uint32 `json:"consecutiveSuccesses"`
+}
+
+// SSEHandler streams the breakers of reg as Server-Sent Events, for a dashboard updating live.
+// Every transition is sent at once as a "transition" event, the counts of every breaker are sent
+// on connect and then every second as "counts" events, the data of both is JSON.
+// Observers must not block the breakers, so the transitions a slow client cannot take are dropped.
+// Breakers registered later are picked up at the next counts, the subscriptions end with the request.
+func SSEHandler(reg *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		transitions := make(chan StateChange, sseBuffer)
+		subscribed := make(map[*RequestBreaker]func())
+		defer func() {
+			for _, unsubscribe := range subscribed {
+				unsubscribe()
+			}
+		}()
+
+		//订阅新登记的断路器，退订已经注销的，返回当前的断路器
+		follow := func() []*RequestBreaker {
+			breakers := reg.Breakers()
+			current := make(map[*RequestBreaker]bool, len(breakers))
+			for _, rb := range breakers {
+				current[rb] = true
+				if subscribed[rb] == nil {
+					subscribed[rb] = rb.Events().Subscribe(ObserverFunc[StateChange](func(change StateChange) {
+						select {
+						case transitions <- change:
+						default:
+						}
+					}))
+				}
+			}
+			for rb, unsubscribe := range subscribed {
+				if !current[rb] {
+					unsubscribe()
+					delete(subscribed, rb)
+				}
+			}
+			return breakers
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		pushCounts := func() error {
+			for _, rb := range follow() {
+				if err := writeEvent(w, "counts", countsOf(rb)); err != nil {
+					return err
+				}
+			}
+			flusher.Flush()
+			return nil
+		}
+		if pushCounts() != nil {
+			return
+		}
+
+		ticker := time.NewTicker(sseCountsInterval)
+		defer ticker.Stop()
+		for {
+			var err error
+			select {
+			case <-r.Context().Done():
+				return
+			case change := <-transitions:
+				err = writeEvent(w, "transition", transitionEvent{
+					Name: change.Name, From: change.From.String(), To: change.To.String(),
+					At: change.At, Reason: change.Reason, DryRun: change.DryRun,
+				})
+				flusher.Flush()
+			case <-ticker.C:
+				err = pushCounts()
+			}
+			if err != nil {
+				return
+			}
+		}
+	})
+}
+
+//countsOf read the state and counts of rb at the same moment
+func countsOf(rb *RequestBreaker) countsEvent {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	cnt := rb.snapshot()
+	return countsEvent{
+		Name:                 rb.options.Name,
+		State:                rb.state.String(),
+		Requests:             cnt.Requests,
+		TotalSuccesses:       cnt.TotalSuccesses,
+		TotalFailures:        cnt.TotalFailures,
+		ConsecutiveFailures:  cnt.ConsecutiveFailures,
+		ConsecutiveSuccesses: cnt.ConsecutiveSuccesses,
+	}
+}
+
+//writeEvent write one Server-Sent Event whose data is v as JSON
+func writeEvent(w http.ResponseWriter, event string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
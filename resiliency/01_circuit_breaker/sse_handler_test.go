@@ -0,0 +1,127 @@
+package circuit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+//sseEvent is one Server-Sent Event read by the test
+type sseEvent struct {
+	name string
+	data map[string]interface{}
+}
+
+//readEvent read the next event of the stream
+func readEvent(t *testing.T, r *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("stream ended: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			return ev
+		case strings.HasPrefix(line, "event: "):
+			ev.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev.data); err != nil {
+				t.Fatalf("data should be JSON, got %q: %v", line, err)
+			}
+		}
+	}
+}
+
+//observerCount return how many observers are subscribed to rb
+func observerCount(rb *RequestBreaker) int {
+	rb.events.mutex.RLock()
+	defer rb.events.mutex.RUnlock()
+	return len(rb.events.observers)
+}
+
+func TestSSEHandlerStreamsTransitions(t *testing.T) {
+
+	orders := NewRequestBreaker(ActionName("orders"))
+	payments := NewRequestBreaker(ActionName("payments"))
+	reg := NewRegistry()
+	reg.Register(payments)
+	reg.Register(orders)
+
+	server := httptest.NewServer(SSEHandler(reg))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	stream := bufio.NewReader(resp.Body)
+
+	//连上就推一次计数，按名字排序
+	for _, name := range []string{"orders", "payments"} {
+		ev := readEvent(t, stream)
+		if ev.name != "counts" || ev.data["name"] != name || ev.data["state"] != "closed" {
+			t.Fatalf("expected the counts of %s, got %s %v", name, ev.name, ev.data)
+		}
+		if _, ok := ev.data["requests"].(float64); !ok {
+			t.Errorf("counts should carry requests, got %v", ev.data)
+		}
+	}
+
+	tripBreaker(t, orders)
+	ev := readEvent(t, stream)
+	for ev.name == "counts" {
+		ev = readEvent(t, stream)
+	}
+	if ev.name != "transition" {
+		t.Fatalf("expected a transition, got %s", ev.name)
+	}
+	if ev.data["name"] != "orders" || ev.data["from"] != "closed" || ev.data["to"] != "open" {
+		t.Errorf("unexpected transition %v", ev.data)
+	}
+	if reason, _ := ev.data["reason"].(string); reason == "" {
+		t.Errorf("a transition should carry its reason, got %v", ev.data)
+	}
+	if at, _ := ev.data["at"].(string); at == "" {
+		t.Errorf("a transition should carry its time, got %v", ev.data)
+	}
+
+	//断开之后取消订阅
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for observerCount(orders)+observerCount(payments) > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := observerCount(orders) + observerCount(payments); n != 0 {
+		t.Errorf("subscriptions should end with the client, %d left", n)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+
+	reg := NewRegistry()
+	first := NewRequestBreaker(ActionName("orders"))
+	unregister := reg.Register(first)
+	second := NewRequestBreaker(ActionName("orders"))
+	reg.Register(second)
+
+	//被替换的断路器注销时不影响新的
+	unregister()
+	if breakers := reg.Breakers(); len(breakers) != 1 || breakers[0] != second {
+		t.Errorf("the breaker of the same name should replace the old one, got %v", breakers)
+	}
+}
@@ -0,0 +1,36 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStateDurations(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), Timeout(10*time.Second), Interval(time.Hour), WithShoulderHalfToOpen(2))
+
+	clock.Advance(time.Minute)
+	tripBreaker(t, rb)
+
+	clock.Advance(15 * time.Second)
+	rb.Do(succeedWork) //open -> half-open
+	clock.Advance(3 * time.Second)
+	rb.Do(succeedWork) //half-open -> closed
+	if rb.State() != StateClosed {
+		t.Fatalf("expected closed, got %s", rb.State())
+	}
+	clock.Advance(2 * time.Second)
+
+	want := map[State]time.Duration{
+		StateClosed:   time.Minute + 2*time.Second,
+		StateOpen:     15 * time.Second,
+		StateHalfOpen: 3 * time.Second,
+	}
+	got := rb.StateDurations()
+	for state, d := range want {
+		if got[state] != d {
+			t.Errorf("%s: expected %v, got %v", state, d, got[state])
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:24:55
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:24:55
+ */
+
+package circuit
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+)
+
+////////////////////////////////
+/// 分片计数
+/// 闭合状态下成功的请求不加锁，但是都去CAS同一个fast字，核多了就互相争抢
+/// 分片之后每次成功只写一个随机的分片，只读fast字，合并时在锁内把各个分片加起来
+////////////////////////////////
+
+//successShard 高32位是generation，低32位是这一代尚未合并的成功次数，独占一个缓存行
+type successShard struct {
+	word uint64
+	_    [56]byte
+}
+
+// WithSuccessShards spread the successes counted without lock over n shards, such as runtime.GOMAXPROCS(0),
+// instead of one shared word every core contends on. The shards are summed into the ICounter under the mutex
+// whenever the breaker reads its counts, n <= 1 keeps the single word.
+func WithSuccessShards(n int) Option {
+	return func(opts *Options) {
+		opts.SuccessShards = n
+	}
+}
+
+//shardSuccess count a success of closed state in a random shard,
+//it fails when the generation or state has changed since admission
+func (rb *RequestBreaker) shardSuccess(generation uint32) bool {
+	word := atomic.LoadUint64(&rb.fast)
+	if uint32(word>>32) != generation || word&fastClosed == 0 {
+		return false
+	}
+
+	shard := &rb.shards[rand.Uint32()%uint32(len(rb.shards))]
+	for {
+		old := atomic.LoadUint64(&shard.word)
+		//分片里是旧一代的成功，已经作废了，直接覆盖
+		next := uint64(generation)<<32 | 1
+		if uint32(old>>32) == generation {
+			if uint32(old) == math.MaxUint32 {
+				return false
+			}
+			next = old + 1
+		}
+		if atomic.CompareAndSwapUint64(&shard.word, old, next) {
+			return true
+		}
+	}
+}
+
+//flushShards fold the successes of current generation in the shards into the counter, must be called with the mutex held
+func (rb *RequestBreaker) flushShards() {
+	for i := range rb.shards {
+		shard := &rb.shards[i]
+		for {
+			word := atomic.LoadUint64(&shard.word)
+			if uint32(word>>32) != rb.generation || uint32(word) == 0 {
+				break
+			}
+			if atomic.CompareAndSwapUint64(&shard.word, word, word&^math.MaxUint32) {
+				rb.counter().CountSuccesses(uint32(word))
+				break
+			}
+		}
+	}
+}
+
+//resetShards drop the successes in the shards, must be called with the mutex held
+func (rb *RequestBreaker) resetShards() {
+	for i := range rb.shards {
+		atomic.StoreUint64(&rb.shards[i].word, 0)
+	}
+}
@@ -0,0 +1,55 @@
+package circuit
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSuccessShardsCountEverySuccess(t *testing.T) {
+
+	const workers, calls = 8, 1000
+
+	rb := NewRequestBreaker(Interval(time.Hour), Expiry(time.Now().Add(time.Hour)), WithSuccessShards(4))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < calls; i++ {
+				rb.Do(succeedWork)
+			}
+		}()
+	}
+	wg.Wait()
+
+	cnt := rb.Counts()
+	if cnt.Requests != workers*calls || cnt.TotalSuccesses != workers*calls {
+		t.Errorf("expected %d successes summed over the shards, got %+v", workers*calls, cnt)
+	}
+
+	//失败在锁内计数，之前分片里的成功要先合并
+	rb.Do(failWork)
+	if cnt := rb.Counts(); cnt.Requests != workers*calls+1 || cnt.ConsecutiveFailures != 1 {
+		t.Errorf("unexpected counts after a failure %+v", cnt)
+	}
+}
+
+func TestSuccessShardsDropStaleGeneration(t *testing.T) {
+
+	rb := NewRequestBreaker(Interval(time.Hour), Expiry(time.Now().Add(time.Hour)), WithSuccessShards(4))
+	rb.Do(succeedWork)
+
+	generation, ok := rb.fastAdmit()
+	if !ok {
+		t.Fatal("closed breaker should admit without lock")
+	}
+	rb.Reset()
+	if rb.fastSuccess(generation) {
+		t.Error("a success admitted before Reset should not be counted")
+	}
+	if cnt := rb.Counts(); cnt.Requests != 0 {
+		t.Errorf("Reset should drop the successes in the shards, got %+v", cnt)
+	}
+}
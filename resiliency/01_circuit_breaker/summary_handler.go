@@ -0,0 +1,42 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 19:03:38
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:04:02
+ */
+
+package circuit
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+////////////////////////////////
+/// 断路器面板的总览
+/// 注册表里有多少断路器打开、半开、闭合，整体健康分是多少，适合挂在 /breakers/summary 上轮询
+////////////////////////////////
+
+type summaryBody struct {
+	Closed           int     `json:"closed"`
+	HalfOpen         int     `json:"halfOpen"`
+	Open             int     `json:"open"`
+	HealthScore      float64 `json:"healthScore"`
+	WorstHealthScore float64 `json:"worstHealthScore"`
+}
+
+//SummaryHandler serve the Summary of the breakers of reg as JSON, mount it at /breakers/summary next to SSEHandler
+func SummaryHandler(reg *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sum := reg.Summary()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(summaryBody{
+			Closed: sum.Closed, HalfOpen: sum.HalfOpen, Open: sum.Open,
+			HealthScore: sum.HealthScore, WorstHealthScore: sum.WorstHealthScore,
+		})
+	})
+}
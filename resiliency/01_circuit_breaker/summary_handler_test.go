@@ -0,0 +1,58 @@
+package circuit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegistrySummary(t *testing.T) {
+
+	reg := NewRegistry()
+	if sum := reg.Summary(); sum != (Summary{HealthScore: 1, WorstHealthScore: 1}) {
+		t.Errorf("an empty registry should be healthy, got %+v", sum)
+	}
+
+	orders := NewRequestBreaker(ActionName("orders"))
+	reg.Register(orders)
+	unregister := reg.Register(NewRequestBreaker(ActionName("users")))
+	tripBreaker(t, orders)
+
+	sum := reg.Summary()
+	if sum.Closed != 1 || sum.Open != 1 || sum.HalfOpen != 0 {
+		t.Errorf("expected one closed and one open, got %+v", sum)
+	}
+	if sum.WorstHealthScore != orders.HealthScore() || sum.HealthScore <= sum.WorstHealthScore {
+		t.Errorf("the tripped breaker should be the worst, got %+v", sum)
+	}
+
+	unregister()
+	if sum := reg.Summary(); sum.Closed != 0 || sum.Open != 1 {
+		t.Errorf("an unregistered breaker should not be counted, got %+v", sum)
+	}
+}
+
+func TestSummaryHandler(t *testing.T) {
+
+	reg := NewRegistry()
+	rb := NewRequestBreaker(ActionName("orders"))
+	reg.Register(rb)
+	reg.Register(NewRequestBreaker(ActionName("users")))
+	tripBreaker(t, rb)
+
+	rec := httptest.NewRecorder()
+	SummaryHandler(reg).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/breakers/summary", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("expected 200 JSON, got %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var body summaryBody
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	sum := reg.Summary()
+	if body.Closed != 1 || body.Open != 1 || body.HealthScore != sum.HealthScore || body.WorstHealthScore != sum.WorstHealthScore {
+		t.Errorf("expected the summary of the registry, got %+v", body)
+	}
+}
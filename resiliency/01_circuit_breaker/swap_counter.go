@@ -0,0 +1,59 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:26:03
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+// SwapCounter replaces the ICounter of breaker at runtime.
+// migrate, if not nil, is called with the mutex held to carry state from the old counter over to the new one.
+// The swap starts a new generation without resetting the new counter, and every request is recorded
+// by the counter it was admitted under: requests in flight at the swap are recorded by the old counter
+// when they return, after migrate has run, and never change the state. A half-open breaker starts a new
+// round of probes with the new counter. Like any new generation it drops the failure severities
+// and latencies recorded before.
+func (rb *RequestBreaker) SwapCounter(newCounter ICounter, migrate func(old, new ICounter)) {
+	rb.lazyInit()
+	rb.mutex.Lock()
+	defer rb.mutex.Unlock()
+
+	rb.flushFast()
+	old := rb.counter()
+	useClock(newCounter, rb.options.Clock)
+	if migrate != nil {
+		migrate(old, newCounter)
+	}
+
+	//新的一代，放行于旧计数器的请求结果记回旧计数器，不会记到新计数器上
+	rb.retired = retiredCounter{generation: rb.generation, counter: old, halfOpen: rb.state == StateHalfOpen}
+	rb.cnter = newCounter
+	rb.nextGeneration()
+	//旧的试探的结果不再决定状态，它们的名额也不会归还，新计数器重新开始一轮试探
+	rb.probes = 0
+}
+
+//retiredCounter is the counter SwapCounter replaced last, with the generation it served
+type retiredCounter struct {
+	generation uint32
+	counter    ICounter
+	halfOpen   bool //换下时是半开状态，结果按试探记
+}
+
+//record count the outcome of a request admitted in generation if it belongs to the retired counter, must be called with the mutex held
+func (r *retiredCounter) record(generation uint32, err error, countProbes bool) {
+	if r.counter == nil || r.generation != generation {
+		return
+	}
+	statue := SuccessState
+	if err != nil {
+		statue = FailureState
+	}
+	if r.halfOpen && !countProbes {
+		r.counter.CountProbe(statue)
+		return
+	}
+	r.counter.Count(statue, true)
+}
@@ -0,0 +1,144 @@
+package circuit
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+//taggedCounter tell which counter recorded a request
+type taggedCounter struct {
+	Counts
+	calls int64
+}
+
+func (c *taggedCounter) Count(s OperationState, isConsecutive bool) {
+	atomic.AddInt64(&c.calls, 1)
+	c.Counts.Count(s, isConsecutive)
+}
+
+func (c *taggedCounter) CountSuccesses(n uint32) {
+	atomic.AddInt64(&c.calls, int64(n))
+	c.Counts.CountSuccesses(n)
+}
+
+func TestSwapCounterMidTraffic(t *testing.T) {
+
+	old := &taggedCounter{}
+	rb := NewRequestBreaker(WithCounter(old))
+
+	traffic := func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					rb.Do(succeedWork)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	//一个请求在切换计数器时还在执行
+	release := make(chan struct{})
+	inFlight := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			close(inFlight)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-inFlight
+
+	traffic()
+
+	fresh := &taggedCounter{}
+	rb.SwapCounter(fresh, func(o, n ICounter) {
+		n.(*taggedCounter).TotalSuccesses = o.Snapshot().TotalSuccesses
+	})
+	before := atomic.LoadInt64(&old.calls)
+	if before != 400 {
+		t.Errorf("old counter should have recorded 400 requests, got %d", before)
+	}
+
+	close(release)
+	<-done
+	traffic()
+	rb.Counts()
+
+	//切换时还在执行的请求记到放行它的旧计数器上，之后的请求都不会
+	if got := atomic.LoadInt64(&old.calls); got != before+1 {
+		t.Errorf("old counter should only record the request in flight at the swap, %d -> %d", before, got)
+	}
+	if got := atomic.LoadInt64(&fresh.calls); got != 400 {
+		t.Errorf("new counter should record only requests admitted after the swap, got %d", got)
+	}
+	if fresh.TotalSuccesses != 800 {
+		t.Errorf("migrated successes should be kept, got %d", fresh.TotalSuccesses)
+	}
+}
+
+func TestSwapCounterStartsFreshGeneration(t *testing.T) {
+
+	rb := NewRequestBreaker(
+		WithBreakCondition(func(state State, cnt Counts) bool { return false }),
+		WithSeverityClassifier(bySeverity),
+		TripOnWeightedFailures(3, 1),
+	)
+	fail := func(err error) {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, err })
+	}
+	fail(errRefused)
+	fail(errRefused)
+
+	//换了计数器，旧一代的失败等级也不再计入
+	rb.SwapCounter(&Counts{}, nil)
+	if transient, persistent := rb.FailureSeverities(); transient != 0 || persistent != 0 {
+		t.Errorf("the swap should reset the severities, got %d and %d", transient, persistent)
+	}
+	fail(errRefused)
+	if rb.State() != StateClosed {
+		t.Error("failures before the swap should not count towards the weighted threshold")
+	}
+}
+
+func TestSwapCounterDuringHalfOpen(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := halfOpenBreaker(t, clock)
+
+	release := make(chan struct{})
+	inFlight := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rb.Do(func(ctx context.Context) (interface{}, error) {
+			close(inFlight)
+			<-release
+			return nil, nil
+		})
+	}()
+	<-inFlight
+
+	//换计数器时试探还在执行，新的计数器重新开始一轮试探
+	old := rb.counter()
+	rb.SwapCounter(&Counts{}, nil)
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Fatalf("the swap should give the new counter a probe slot, got %v", err)
+	}
+
+	close(release)
+	<-done
+	if cnt := old.Snapshot(); cnt.ProbeSuccesses != 1 {
+		t.Errorf("the probe in flight should be recorded by the old counter, got %+v", cnt)
+	}
+	if cnt := rb.Counts(); cnt.ProbeSuccesses != 1 {
+		t.Errorf("the new counter should only record the probe admitted after the swap, got %+v", cnt)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:52:10
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:27:44
+ */
+
+package circuit
+
+import "fmt"
+
+////////////////////////////////
+/// 运行时可以调整的打开阈值
+/// Options 构造之后就不再改变，阈值单独放在一个原子指针里
+/// 调整阈值只替换指针，不需要断路器的锁，读的一方也不用加锁
+////////////////////////////////
+
+//thresholdTripPolicy is reported as TripPolicy while a TripThreshold decides when to trip
+const thresholdTripPolicy = "threshold"
+
+// TripThreshold is a break condition made of numbers, so it can be changed while the breaker runs.
+// The breaker opens once ConsecutiveFailures failures happened in a row, or once at least MinRequests
+// requests were counted and FailureRatio of them failed. A zero field disables its check.
+type TripThreshold struct {
+	ConsecutiveFailures uint32
+	FailureRatio        float64
+	MinRequests         uint32
+}
+
+func (t TripThreshold) String() string {
+	return fmt.Sprintf("threshold(consecutiveFailures=%d, failureRatio=%v, minRequests=%d)",
+		t.ConsecutiveFailures, t.FailureRatio, t.MinRequests)
+}
+
+//met report whether cnt reaches the threshold
+func (t *TripThreshold) met(cnt Counts) bool {
+	if t.ConsecutiveFailures > 0 && cnt.ConsecutiveFailures >= t.ConsecutiveFailures {
+		return true
+	}
+	failures := cnt.TotalFailures + cnt.ProbeFailures
+	return t.FailureRatio > 0 && cnt.Requests > 0 && cnt.Requests >= t.MinRequests &&
+		float64(failures)/float64(cnt.Requests) >= t.FailureRatio
+}
+
+//WithTripThreshold decide when to trip by threshold instead of CanOpen, see Reconfigure to change it later.
+//CanOpenFor and CanOpenContext still take precedence
+func WithTripThreshold(threshold TripThreshold) Option {
+	return func(opts *Options) {
+		opts.TripThreshold = &threshold
+	}
+}
+
+// Reconfigure replaces the trip threshold of a running breaker, the next trip check uses it.
+// It swaps an atomic pointer and never waits for the breaker, so it is safe at any rate.
+// A breaker built without WithTripThreshold switches from CanOpen to threshold.
+func (rb *RequestBreaker) Reconfigure(threshold TripThreshold) {
+	rb.lazyInit()
+	rb.threshold.Store(&threshold)
+}
+
+//Threshold return the current trip threshold without locking, false when CanOpen decides instead
+func (rb *RequestBreaker) Threshold() (TripThreshold, bool) {
+	rb.lazyInit()
+	if t := rb.threshold.Load(); t != nil {
+		return *t, true
+	}
+	return TripThreshold{}, false
+}
@@ -0,0 +1,93 @@
+package circuit
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReconfigureUnderLoad(t *testing.T) {
+
+	const workers, calls = 8, 300
+
+	rb := NewRequestBreaker(WithTripThreshold(TripThreshold{ConsecutiveFailures: 1000}))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := uint32(0); ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			rb.Reconfigure(TripThreshold{ConsecutiveFailures: 1000 + i%7, FailureRatio: 0.99, MinRequests: 1000})
+			rb.Threshold()
+		}
+	}()
+
+	var workersDone sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		workersDone.Add(1)
+		go func(w int) {
+			defer workersDone.Done()
+			for i := 0; i < calls; i++ {
+				if (w+i)%2 == 0 {
+					rb.Do(failWork)
+				} else {
+					rb.Do(succeedWork)
+				}
+			}
+		}(w)
+	}
+	workersDone.Wait()
+	close(stop)
+	wg.Wait()
+
+	if rb.State() != StateClosed {
+		t.Fatalf("no threshold in the loop can be met, got %s", rb.State())
+	}
+
+	//最后一次设置的阈值生效
+	rb.Reconfigure(TripThreshold{ConsecutiveFailures: 2})
+	rb.Do(succeedWork)
+	rb.Do(failWork)
+	if rb.State() != StateClosed {
+		t.Fatal("one failure should not meet a threshold of 2")
+	}
+	rb.Do(failWork)
+	if rb.State() != StateOpen {
+		t.Fatalf("the latest threshold should govern, got %s", rb.State())
+	}
+	if reason := rb.LastTransitionReason(); !strings.Contains(reason, "threshold(consecutiveFailures=2") {
+		t.Errorf("unexpected reason %q", reason)
+	}
+}
+
+func TestReconfigureReplacesCanOpen(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	if _, ok := rb.Threshold(); ok || rb.Config().TripPolicy != defaultTripPolicy {
+		t.Fatal("a breaker without threshold should use CanOpen")
+	}
+
+	rb.Reconfigure(TripThreshold{FailureRatio: 0.5, MinRequests: 4})
+	if c := rb.Config(); c.TripPolicy != thresholdTripPolicy || c.TripThreshold.MinRequests != 4 {
+		t.Errorf("Config should report the live threshold, got %q %+v", c.TripPolicy, c.TripThreshold)
+	}
+
+	//默认条件是连续3次失败，阈值按比例：4个请求里2个失败
+	rb.Do(failWork)
+	rb.Do(succeedWork)
+	rb.Do(failWork)
+	if rb.State() != StateClosed {
+		t.Fatal("the ratio needs MinRequests first")
+	}
+	rb.Do(succeedWork)
+	rb.Do(failWork)
+	if rb.State() != StateOpen {
+		t.Errorf("3 of 5 failed, the threshold should trip, got %s", rb.State())
+	}
+}
@@ -0,0 +1,35 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:37:19
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:37:19
+ */
+
+package circuit
+
+import "context"
+
+////////////////////////////////
+/// 链路追踪
+/// 断路器只定义接口，不依赖任何追踪库
+/// OpenTelemetry 的实现在 otelbreaker 子包里，需要 otel 构建标签
+////////////////////////////////
+
+//RequestSpan is the span of one request, End is called once the request is admitted and done or rejected
+type RequestSpan interface {
+	//End finish the span, state is the state of breaker afterwards, admitted is false if the request was short-circuited
+	End(state State, admitted bool, err error)
+}
+
+//RequestTracer start a span for every request, the returned ctx is handed to the work
+type RequestTracer interface {
+	Start(ctx context.Context, name string) (context.Context, RequestSpan)
+}
+
+//WithRequestTracer wrap every DoContext in a span started by tracer
+func WithRequestTracer(tracer RequestTracer) Option {
+	return func(opts *Options) {
+		opts.Tracer = tracer
+	}
+}
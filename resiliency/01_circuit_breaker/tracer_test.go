@@ -0,0 +1,59 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+)
+
+type recordedSpan struct {
+	name     string
+	state    State
+	admitted bool
+	err      error
+}
+
+func (s *recordedSpan) End(state State, admitted bool, err error) {
+	s.state, s.admitted, s.err = state, admitted, err
+}
+
+type spanKey struct{}
+
+//recordTracer keep every span it started
+type recordTracer struct {
+	spans []*recordedSpan
+}
+
+func (t *recordTracer) Start(ctx context.Context, name string) (context.Context, RequestSpan) {
+	span := &recordedSpan{name: name}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func TestRequestTracerSpans(t *testing.T) {
+
+	tracer := &recordTracer{}
+	rb := NewRequestBreaker(ActionName("traced"), WithRequestTracer(tracer))
+
+	rb.DoContext(context.Background(), func(ctx context.Context) (interface{}, error) {
+		if ctx.Value(spanKey{}) == nil {
+			t.Error("work should get the context of its span")
+		}
+		return nil, nil
+	})
+	tripBreaker(t, rb)
+	_, rejection := rb.Do(succeedWork)
+
+	if len(tracer.spans) != 5 {
+		t.Fatalf("every request should get a span, got %d", len(tracer.spans))
+	}
+	ok, failed, rejected := tracer.spans[0], tracer.spans[3], tracer.spans[4]
+	if ok.name != "traced" || !ok.admitted || ok.err != nil || ok.state != StateClosed {
+		t.Errorf("unexpected span of success: %+v", ok)
+	}
+	if !failed.admitted || failed.err != errBackendDown || failed.state != StateOpen {
+		t.Errorf("unexpected span of the tripping failure: %+v", failed)
+	}
+	if rejected.admitted || rejected.err != rejection || rejected.state != StateOpen {
+		t.Errorf("unexpected span of rejection: %+v", rejected)
+	}
+}
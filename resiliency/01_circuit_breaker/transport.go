@@ -0,0 +1,93 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:58:59
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:58:59
+ */
+
+package circuit
+
+import (
+	"fmt"
+	"net/http"
+)
+
+////////////////////////////////
+/// HTTP 客户端的断路器
+/// 包装 http.RoundTripper，每个请求先经过断路器，响应再分类成成功、失败或者忽略
+////////////////////////////////
+
+//ResponseClassifier decide what a response means to the breaker, err is the error of RoundTrip
+type ResponseClassifier func(resp *http.Response, err error) Outcome
+
+//DefaultResponseClassifier count transport errors and 5xx responses as failures, everything else as success
+func DefaultResponseClassifier(resp *http.Response, err error) Outcome {
+	if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
+
+//Transport is an http.RoundTripper guarded by a breaker
+type Transport struct {
+	rb       *RequestBreaker
+	next     http.RoundTripper
+	classify ResponseClassifier
+}
+
+//TransportOption set Transport
+type TransportOption func(t *Transport)
+
+//WithResponseClassifier replace DefaultResponseClassifier,
+//the classifier must not consume the response body, or must put back an unread copy of it
+func WithResponseClassifier(classify ResponseClassifier) TransportOption {
+	return func(t *Transport) {
+		t.classify = classify
+	}
+}
+
+//NewTransport guard next with rb, a nil next means http.DefaultTransport
+func NewTransport(rb *RequestBreaker, next http.RoundTripper, opts ...TransportOption) *Transport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	t := &Transport{rb: rb, next: next, classify: DefaultResponseClassifier}
+	for _, setOption := range opts {
+		setOption(t)
+	}
+	return t
+}
+
+// RoundTrip sends req through the breaker, a rejected request returns the rejection error without being sent.
+// A response classified as failure is still returned to the caller, only the breaker counts it as failure.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	token, err := t.rb.Prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+
+	switch t.classify(resp, err) {
+	case OutcomeIgnore:
+		t.rb.Rollback(token)
+	case OutcomeFailure:
+		t.rb.Commit(token, responseError(resp, err))
+	default:
+		t.rb.Commit(token, nil)
+	}
+	return resp, err
+}
+
+//responseError is the failure counted for resp, responses of the same status fall in the same error group
+func responseError(resp *http.Response, err error) error {
+	if err != nil {
+		return err
+	}
+	if resp == nil {
+		return errClassifiedFailure
+	}
+	return fmt.Errorf("http response %s", resp.Status)
+}
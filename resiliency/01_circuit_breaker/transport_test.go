@@ -0,0 +1,86 @@
+package circuit
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+//statusServer answer every request with the status code in its path, such as /429
+func statusServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		code, _ := strconv.Atoi(r.URL.Path[1:])
+		w.WriteHeader(code)
+		io.WriteString(w, "body")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, error) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err == nil {
+		defer resp.Body.Close()
+		if body, _ := io.ReadAll(resp.Body); string(body) != "body" {
+			t.Errorf("body should reach the caller unread, got %q", body)
+		}
+	}
+	return resp, err
+}
+
+func TestTransportDefaultClassifier(t *testing.T) {
+
+	server := statusServer(t)
+	rb := NewRequestBreaker()
+	client := &http.Client{Transport: NewTransport(rb, nil)}
+
+	get(t, client, server.URL+"/200")
+	get(t, client, server.URL+"/404")
+	resp, err := get(t, client, server.URL+"/503")
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("a failed response should still be returned, got %v", err)
+	}
+
+	if cnt := rb.Counts(); cnt.TotalSuccesses != 2 || cnt.TotalFailures != 1 {
+		t.Errorf("only 5xx should fail, got %+v", cnt)
+	}
+}
+
+func TestTransportResponseClassifier(t *testing.T) {
+
+	server := statusServer(t)
+	rb := NewRequestBreaker()
+	classify := func(resp *http.Response, err error) Outcome {
+		switch {
+		case err != nil || resp.StatusCode == http.StatusTooManyRequests:
+			return OutcomeFailure
+		case resp.StatusCode == http.StatusNotFound:
+			return OutcomeSuccess
+		case resp.StatusCode == http.StatusNotModified:
+			return OutcomeIgnore
+		}
+		return DefaultResponseClassifier(resp, err)
+	}
+	client := &http.Client{Transport: NewTransport(rb, nil, WithResponseClassifier(classify))}
+
+	get(t, client, server.URL+"/404")
+	get(t, client, server.URL+"/429")
+	if resp, err := client.Get(server.URL + "/304"); err == nil {
+		resp.Body.Close()
+	}
+
+	if cnt := rb.Counts(); cnt.Requests != 2 || cnt.TotalSuccesses != 1 || cnt.TotalFailures != 1 {
+		t.Fatalf("counts should follow the classifier, got %+v", cnt)
+	}
+
+	//连续的429打开断路器，之后的请求不发出去
+	get(t, client, server.URL+"/429")
+	get(t, client, server.URL+"/429")
+	if _, err := client.Get(server.URL + "/200"); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("open breaker should reject, got %v", err)
+	}
+}
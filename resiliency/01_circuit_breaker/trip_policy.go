@@ -0,0 +1,165 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:34:37
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:27:44
+ */
+
+package circuit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+////////////////////////////////
+/// 工厂方法模式
+/// 打开条件按名字注册构造函数，配置里只需要写名字和参数
+////////////////////////////////
+
+//ErrUnknownTripPolicy is returned by NewTripPolicy for a name nobody registered
+var ErrUnknownTripPolicy = errors.New("unknown trip policy")
+
+//TripPolicyFactory build a break condition from its parameters
+type TripPolicyFactory func(params map[string]interface{}) (BreakConditionWatcher, error)
+
+var tripPolicies = struct {
+	sync.RWMutex
+	factories map[string]TripPolicyFactory
+}{factories: make(map[string]TripPolicyFactory)}
+
+// RegisterTripPolicy makes a trip policy available by name.
+// It panics if factory is nil or name is already registered, just like database/sql.Register.
+// The built-in policies are "consecutive", "ratio" and "budget".
+func RegisterTripPolicy(name string, factory TripPolicyFactory) {
+	tripPolicies.Lock()
+	defer tripPolicies.Unlock()
+
+	if factory == nil {
+		panic("circuit: RegisterTripPolicy factory is nil")
+	}
+	if _, dup := tripPolicies.factories[name]; dup {
+		panic("circuit: RegisterTripPolicy called twice for " + name)
+	}
+	tripPolicies.factories[name] = factory
+}
+
+//NewTripPolicy build the trip policy registered as name, use it with WithBreakCondition
+func NewTripPolicy(name string, params map[string]interface{}) (BreakConditionWatcher, error) {
+	tripPolicies.RLock()
+	factory, ok := tripPolicies.factories[name]
+	tripPolicies.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownTripPolicy, name)
+	}
+	return factory(params)
+}
+
+//WithTripPolicy build the trip policy registered as name and use it as the break condition,
+//the policy is then identified by name and params in Config
+func WithTripPolicy(name string, params map[string]interface{}) (Option, error) {
+	condition, err := NewTripPolicy(name, params)
+	if err != nil {
+		return nil, err
+	}
+	policy := describePolicy(name, params)
+	return func(opts *Options) {
+		opts.CanOpen = condition
+		opts.TripPolicy = policy
+	}, nil
+}
+
+//describePolicy return name(key=value, ...) with the keys in order
+func describePolicy(name string, params map[string]interface{}) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	args := make([]string, 0, len(keys))
+	for _, key := range keys {
+		args = append(args, fmt.Sprintf("%s=%v", key, params[key]))
+	}
+	return name + "(" + strings.Join(args, ", ") + ")"
+}
+
+//TripPolicies return the registered names in order
+func TripPolicies() []string {
+	tripPolicies.RLock()
+	defer tripPolicies.RUnlock()
+
+	names := make([]string, 0, len(tripPolicies.factories))
+	for name := range tripPolicies.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	//连续失败 failures 次，默认3次，和默认的打开条件一样
+	RegisterTripPolicy("consecutive", func(params map[string]interface{}) (BreakConditionWatcher, error) {
+		failures, err := paramNumber(params, "failures", 3)
+		if err != nil {
+			return nil, err
+		}
+		return func(state State, cnter Counts) bool {
+			return float64(cnter.ConsecutiveFailures) >= failures
+		}, nil
+	})
+
+	//至少 minRequests 个请求之后，失败的比例达到 ratio
+	RegisterTripPolicy("ratio", func(params map[string]interface{}) (BreakConditionWatcher, error) {
+		ratio, err := paramNumber(params, "ratio", 0.5)
+		if err != nil {
+			return nil, err
+		}
+		minRequests, err := paramNumber(params, "minRequests", 10)
+		if err != nil {
+			return nil, err
+		}
+		return func(state State, cnter Counts) bool {
+			return cnter.Requests > 0 && float64(cnter.Requests) >= minRequests &&
+				float64(cnter.TotalFailures)/float64(cnter.Requests) >= ratio
+		}, nil
+	})
+
+	//一个统计周期内允许 failures 次失败，用完就打开
+	RegisterTripPolicy("budget", func(params map[string]interface{}) (BreakConditionWatcher, error) {
+		budget, err := paramNumber(params, "failures", -1)
+		if err != nil {
+			return nil, err
+		}
+		if budget < 0 {
+			return nil, errors.New("budget trip policy needs failures")
+		}
+		return func(state State, cnter Counts) bool {
+			return float64(cnter.TotalFailures) > budget
+		}, nil
+	})
+}
+
+//paramNumber read a number of any numeric type from params, def if key is absent
+func paramNumber(params map[string]interface{}, key string, def float64) (float64, error) {
+	v, ok := params[key]
+	if !ok {
+		return def, nil
+	}
+	switch n := v.(type) {
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case uint32:
+		return float64(n), nil
+	case float64:
+		return n, nil
+	}
+	return 0, fmt.Errorf("trip policy parameter %s: want a number, got %T", key, v)
+}
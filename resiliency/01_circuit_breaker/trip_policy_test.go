@@ -0,0 +1,73 @@
+package circuit
+
+import (
+	"errors"
+	"testing"
+)
+
+func init() {
+	//包级注册表只能注册一次，放在init里以便 -count 多次运行
+	RegisterTripPolicy("test-probe-failures", func(params map[string]interface{}) (BreakConditionWatcher, error) {
+		n, err := paramNumber(params, "probes", 1)
+		if err != nil {
+			return nil, err
+		}
+		return func(state State, cnter Counts) bool {
+			return state == StateHalfOpen && float64(cnter.ProbeFailures) >= n
+		}, nil
+	})
+}
+
+func TestNewTripPolicyByName(t *testing.T) {
+
+	trip, err := NewTripPolicy("test-probe-failures", map[string]interface{}{"probes": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trip(StateHalfOpen, Counts{ProbeFailures: 1}) || !trip(StateHalfOpen, Counts{ProbeFailures: 2}) {
+		t.Error("custom policy should use its parameters")
+	}
+
+	if _, err := NewTripPolicy("test-probe-failures", map[string]interface{}{"probes": "two"}); err == nil {
+		t.Error("a non-numeric parameter should fail")
+	}
+}
+
+func TestBuiltinTripPolicies(t *testing.T) {
+
+	ratio, err := NewTripPolicy("ratio", map[string]interface{}{"ratio": 0.5, "minRequests": 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratio(StateClosed, Counts{Requests: 2, TotalFailures: 2}) {
+		t.Error("ratio should wait for minRequests")
+	}
+	if !ratio(StateClosed, Counts{Requests: 4, TotalFailures: 2}) {
+		t.Error("ratio should trip at half failures")
+	}
+
+	budget, err := NewTripPolicy("budget", map[string]interface{}{"failures": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budget(StateClosed, Counts{TotalFailures: 2}) || !budget(StateClosed, Counts{TotalFailures: 3}) {
+		t.Error("budget should trip once exceeded")
+	}
+	if _, err := NewTripPolicy("budget", nil); err == nil {
+		t.Error("budget needs failures")
+	}
+
+	consecutive, err := NewTripPolicy("consecutive", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb := NewRequestBreaker(WithBreakCondition(consecutive))
+	tripBreaker(t, rb)
+}
+
+func TestUnknownTripPolicy(t *testing.T) {
+
+	if _, err := NewTripPolicy("no-such-policy", nil); !errors.Is(err, ErrUnknownTripPolicy) {
+		t.Errorf("expected ErrUnknownTripPolicy, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:26:29
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:26:29
+ */
+
+package circuit
+
+import "sort"
+
+////////////////////////////////
+/// 访问者模式 + 组合模式
+/// 断路器是叶子，VotingBreaker、MultiBreaker、BreakerGroup 是组合节点
+/// 访问者遍历整棵树，用来汇总报告，比如找出所有打开的断路器
+////////////////////////////////
+
+//Visitor visit the nodes of a breaker tree
+type Visitor interface {
+	VisitBreaker(rb *RequestBreaker)
+	VisitComposite(c Composite)
+}
+
+//Node is a node of breaker tree
+type Node interface {
+	Accept(v Visitor)
+}
+
+//Composite is a node holding other nodes
+type Composite interface {
+	Node
+	Children() []Node
+}
+
+//acceptComposite visit c first, then its children in order
+func acceptComposite(c Composite, v Visitor) {
+	v.VisitComposite(c)
+	for _, child := range c.Children() {
+		child.Accept(v)
+	}
+}
+
+//Accept let v visit the breaker
+func (rb *RequestBreaker) Accept(v Visitor) {
+	v.VisitBreaker(rb)
+}
+
+//Children return the child breakers, heaviest first
+func (vb *VotingBreaker) Children() []Node {
+	nodes := make([]Node, 0, len(vb.children))
+	for _, child := range vb.children {
+		nodes = append(nodes, child.rb)
+	}
+	return nodes
+}
+
+//Accept let v visit the voting breaker and its children
+func (vb *VotingBreaker) Accept(v Visitor) {
+	acceptComposite(vb, v)
+}
+
+//Children return the breakers created so far, ordered by key
+func (mb *MultiBreaker) Children() []Node {
+	var keys []string
+	breakers := make(map[string]*RequestBreaker)
+	for i := range mb.shards {
+		sh := &mb.shards[i]
+		sh.mutex.Lock()
+		for key, rb := range sh.breakers {
+			keys = append(keys, key)
+			breakers[key] = rb
+		}
+		sh.mutex.Unlock()
+	}
+	sort.Strings(keys)
+
+	nodes := make([]Node, 0, len(keys))
+	for _, key := range keys {
+		nodes = append(nodes, breakers[key])
+	}
+	return nodes
+}
+
+//Accept let v visit the multi breaker and its children
+func (mb *MultiBreaker) Accept(v Visitor) {
+	acceptComposite(mb, v)
+}
+
+//BreakerGroup is a named group of nodes, use it to build a tree for reporting
+type BreakerGroup struct {
+	name     string
+	children []Node
+}
+
+//NewBreakerGroup return a group of children
+func NewBreakerGroup(name string, children ...Node) *BreakerGroup {
+	return &BreakerGroup{name: name, children: children}
+}
+
+//Name return the name of group
+func (g *BreakerGroup) Name() string {
+	return g.name
+}
+
+//Children return the nodes of group
+func (g *BreakerGroup) Children() []Node {
+	return g.children
+}
+
+//Accept let v visit the group and its children
+func (g *BreakerGroup) Accept(v Visitor) {
+	acceptComposite(g, v)
+}
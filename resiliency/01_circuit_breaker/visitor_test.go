@@ -0,0 +1,54 @@
+package circuit
+
+import (
+	"reflect"
+	"testing"
+)
+
+//leafCollector collect the names of all leaf breakers and the open ones
+type leafCollector struct {
+	names, open []string
+	composites  int
+}
+
+func (c *leafCollector) VisitBreaker(rb *RequestBreaker) {
+	c.names = append(c.names, rb.options.Name)
+	if rb.State() == StateOpen {
+		c.open = append(c.open, rb.options.Name)
+	}
+}
+
+func (c *leafCollector) VisitComposite(Composite) {
+	c.composites++
+}
+
+func TestVisitorWalksTree(t *testing.T) {
+
+	primary := NewRequestBreaker(ActionName("primary"))
+	backup := NewRequestBreaker(ActionName("backup"))
+	voting := NewVotingBreaker(map[*RequestBreaker]float64{primary: 2, backup: 1})
+
+	multi := NewMultiBreaker(nil, func(key string) *RequestBreaker { return NewRequestBreaker(ActionName(key)) })
+	tripBreaker(t, multi.Breaker("users"))
+	multi.Breaker("orders")
+
+	tree := NewBreakerGroup("root",
+		NewBreakerGroup("storage", voting),
+		multi,
+		NewRequestBreaker(ActionName("search")),
+	)
+
+	c := &leafCollector{}
+	tree.Accept(c)
+
+	want := []string{"primary", "backup", "orders", "users", "search"}
+	if !reflect.DeepEqual(c.names, want) {
+		t.Errorf("expected leaves %v, got %v", want, c.names)
+	}
+	if len(c.open) != 1 || c.open[0] != "users" {
+		t.Errorf("expected users to be open, got %v", c.open)
+	}
+	if c.composites != 4 {
+		t.Errorf("expected 4 composites, got %d", c.composites)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:18:43
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 18:24:12
+ */
+
+package circuit
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+////////////////////////////////
+/// 断路器的组合: 加权投票
+/// 服务依赖多个后端，每个后端一个断路器和一个权重
+/// 打开状态的权重超过一半时，整体才算打开
+////////////////////////////////
+
+//voteThreshold is the share of weight above which the vote opens
+const voteThreshold = 0.5
+
+type weightedBreaker struct {
+	rb     *RequestBreaker
+	weight float64
+}
+
+//OpenChildrenError is returned by a composite rejecting because of its open children,
+//it matches ErrServiceUnavailable with errors.Is
+type OpenChildrenError struct {
+	Names []string //打开的子断路器的名字，按权重从大到小
+}
+
+func (e *OpenChildrenError) Error() string {
+	return ErrServiceUnavailable.Error() + ": open " + strings.Join(e.Names, ", ")
+}
+
+//Unwrap return ErrServiceUnavailable
+func (e *OpenChildrenError) Unwrap() error {
+	return ErrServiceUnavailable
+}
+
+//VotingBreaker decide by a weighted vote of child breakers
+type VotingBreaker struct {
+	children []weightedBreaker //按权重从大到小
+	total    float64
+}
+
+//NewVotingBreaker return a VotingBreaker over children and their weights, weights <= 0 are ignored
+func NewVotingBreaker(children map[*RequestBreaker]float64) *VotingBreaker {
+
+	vb := &VotingBreaker{}
+	for rb, weight := range children {
+		if weight <= 0 {
+			continue
+		}
+		vb.children = append(vb.children, weightedBreaker{rb: rb, weight: weight})
+		vb.total += weight
+	}
+	sort.Slice(vb.children, func(i, j int) bool {
+		if vb.children[i].weight != vb.children[j].weight {
+			return vb.children[i].weight > vb.children[j].weight
+		}
+		return vb.children[i].rb.options.Name < vb.children[j].rb.options.Name
+	})
+
+	return vb
+}
+
+// State returns the result of the vote.
+// It is StateOpen when open children weigh more than half of the total,
+// StateHalfOpen when open and half-open children together do, and StateClosed otherwise.
+// A WithDryRun child only pretends to change state and always votes closed.
+func (vb *VotingBreaker) State() State {
+	if vb.total == 0 {
+		return StateClosed
+	}
+
+	var open, halfOpen float64
+	for _, child := range vb.children {
+		switch child.rb.enforced() {
+		case StateOpen:
+			open += child.weight
+		case StateHalfOpen:
+			halfOpen += child.weight
+		}
+	}
+
+	switch {
+	case open/vb.total > voteThreshold:
+		return StateOpen
+	case (open+halfOpen)/vb.total > voteThreshold:
+		return StateHalfOpen
+	}
+	return StateClosed
+}
+
+//AllowRequest report whether the vote lets requests go
+func (vb *VotingBreaker) AllowRequest() bool {
+	return vb.State() != StateOpen
+}
+
+//Do run work through the heaviest closed child, or the heaviest half-open one if none is closed,
+//it fails fast with an *OpenChildrenError naming the open children when the vote is open
+func (vb *VotingBreaker) Do(work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if !vb.AllowRequest() {
+		return nil, vb.openChildren()
+	}
+
+	var chosen *RequestBreaker
+	for _, child := range vb.children {
+		state := child.rb.enforced()
+		if state == StateClosed {
+			chosen = child.rb
+			break
+		}
+		if state == StateHalfOpen && chosen == nil {
+			chosen = child.rb
+		}
+	}
+	if chosen == nil {
+		return nil, vb.openChildren()
+	}
+
+	return chosen.Do(work)
+}
+
+//openChildren return the error listing the children open right now
+func (vb *VotingBreaker) openChildren() error {
+	err := &OpenChildrenError{}
+	for _, child := range vb.children {
+		if child.rb.enforced() == StateOpen {
+			err.Names = append(err.Names, child.rb.Name())
+		}
+	}
+	return err
+}
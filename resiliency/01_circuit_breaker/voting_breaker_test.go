@@ -0,0 +1,80 @@
+package circuit
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVotingBreakerThreshold(t *testing.T) {
+
+	primary := NewRequestBreaker(ActionName("primary"))
+	secondary := NewRequestBreaker(ActionName("secondary"))
+	backup := NewRequestBreaker(ActionName("backup"))
+
+	vb := NewVotingBreaker(map[*RequestBreaker]float64{primary: 0.5, secondary: 0.3, backup: 0.2})
+
+	vb.Do(succeedWork)
+	if primary.Counts().TotalSuccesses != 1 {
+		t.Error("heaviest closed child should be chosen")
+	}
+
+	//恰好一半的权重打开，不超过阈值
+	tripBreaker(t, primary)
+	if vb.State() != StateClosed || !vb.AllowRequest() {
+		t.Errorf("0.5 open is not above the threshold, got %s", vb.State())
+	}
+	if _, err := vb.Do(succeedWork); err != nil {
+		t.Fatal(err)
+	}
+	if secondary.Counts().TotalSuccesses != 1 {
+		t.Error("secondary should take over from the open primary")
+	}
+
+	tripBreaker(t, backup)
+	if vb.State() != StateOpen || vb.AllowRequest() {
+		t.Errorf("0.7 open is above the threshold, got %s", vb.State())
+	}
+	if _, err := vb.Do(succeedWork); !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("open vote should fail fast, got %v", err)
+	}
+}
+
+func TestVotingBreakerNamesOpenChildren(t *testing.T) {
+
+	primary := NewRequestBreaker(ActionName("primary"))
+	backup := NewRequestBreaker(ActionName("backup"))
+	vb := NewVotingBreaker(map[*RequestBreaker]float64{primary: 0.7, backup: 0.3})
+
+	tripBreaker(t, primary)
+	_, err := vb.Do(succeedWork)
+
+	var open *OpenChildrenError
+	if !errors.As(err, &open) || len(open.Names) != 1 || open.Names[0] != "primary" {
+		t.Fatalf("rejection should name the open child, got %v", err)
+	}
+	if !errors.Is(err, ErrServiceUnavailable) {
+		t.Errorf("rejection should still match ErrServiceUnavailable, got %v", err)
+	}
+	if err.Error() != "service unavailable: open primary" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestVotingBreakerIgnoresDryRunChildren(t *testing.T) {
+
+	shadow := NewRequestBreaker(ActionName("a"), WithDryRun(true))
+	backup := NewRequestBreaker(ActionName("b"))
+	vb := NewVotingBreaker(map[*RequestBreaker]float64{shadow: 0.7, backup: 0.3})
+
+	//影子状态打开的子断路器照常放行，投票时算作闭合
+	tripBreaker(t, shadow)
+	if vb.State() != StateClosed {
+		t.Errorf("a dry-run child should vote closed, got %s", vb.State())
+	}
+	if _, err := vb.Do(succeedWork); err != nil {
+		t.Fatalf("a dry-run child should not reject, got %v", err)
+	}
+	if shadow.Counts().RejectedOpen != 1 || backup.Counts().TotalSuccesses != 0 {
+		t.Error("the heaviest child should still take the request")
+	}
+}
@@ -0,0 +1,38 @@
+package circuit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWarmupSuppressesTrip(t *testing.T) {
+
+	clock := newFakeClock()
+	rb := NewRequestBreaker(WithClock(clock), WithWarmup(time.Minute), Interval(time.Hour))
+
+	for i := 0; i < 10; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	}
+	if rb.State() != StateClosed {
+		t.Fatalf("breaker should not trip during warm-up, got %s", rb.State())
+	}
+	if cnt := rb.Counts(); cnt.TotalFailures != 10 {
+		t.Errorf("failures should still be counted, got %+v", cnt)
+	}
+
+	clock.Advance(time.Minute)
+	tripBreaker(t, rb)
+
+	//Reset 之后重新预热
+	rb.Reset()
+	if rb.State() != StateClosed || rb.Counts().TotalFailures != 0 {
+		t.Fatalf("Reset should close with fresh counters, got %s %+v", rb.State(), rb.Counts())
+	}
+	for i := 0; i < 5; i++ {
+		rb.Do(func(ctx context.Context) (interface{}, error) { return nil, errBackendDown })
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("warm-up should start again after Reset, got %s", rb.State())
+	}
+}
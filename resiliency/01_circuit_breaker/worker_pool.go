@@ -0,0 +1,143 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:00:57
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 19:03:05
+ */
+
+package circuit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+////////////////////////////////
+/// 工作池 + 断路器
+/// 固定数量的worker处理后台任务，所有任务共用一个断路器
+/// 断路器打开时，队列中的任务被拒绝(或者等待后重新尝试)
+////////////////////////////////
+
+//ErrPoolClosed is returned for jobs submitted after Shutdown
+var ErrPoolClosed = NewRejectionError("worker pool closed")
+
+//PoolOption set WorkerPool
+type PoolOption func(p *WorkerPool)
+
+//RetryWhenOpen make a worker retry a rejected job every interval instead of rejecting it,
+//until the breaker admits it or the pool is shut down
+func RetryWhenOpen(interval time.Duration) PoolOption {
+	return func(p *WorkerPool) {
+		p.retryInterval = interval
+	}
+}
+
+type poolJob struct {
+	fn     func() error
+	result chan error
+}
+
+//WorkerPool run jobs on a bounded set of workers guarded by a shared breaker
+type WorkerPool struct {
+	rb            *RequestBreaker
+	jobs          chan poolJob
+	quit          chan struct{}
+	quitOnce      sync.Once
+	workers       sync.WaitGroup
+	mutex         sync.RWMutex
+	closed        bool
+	retryInterval time.Duration
+}
+
+//NewWorkerPool start size workers running jobs through rb
+func NewWorkerPool(size int, rb *RequestBreaker, opts ...PoolOption) *WorkerPool {
+
+	p := &WorkerPool{
+		rb:   rb,
+		jobs: make(chan poolJob, size),
+		quit: make(chan struct{}),
+	}
+
+	for _, setOption := range opts {
+		setOption(p)
+	}
+
+	p.workers.Add(size)
+	for i := 0; i < size; i++ {
+		go p.worker()
+	}
+
+	return p
+}
+
+// Submit queue job and return a channel receiving its result.
+// The result is ErrServiceUnavailable when the breaker rejects the job,
+// or ErrPoolClosed when the pool has been shut down, also while Submit waits for room in a full queue.
+func (p *WorkerPool) Submit(job func() error) <-chan error {
+
+	result := make(chan error, 1)
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if p.closed {
+		result <- ErrPoolClosed
+		return result
+	}
+
+	//队列满的时候一边等一边看是否在关闭，不然持有读锁阻塞住 Shutdown
+	select {
+	case p.jobs <- poolJob{fn: job, result: result}:
+	case <-p.quit:
+		result <- ErrPoolClosed
+	}
+	return result
+}
+
+//Shutdown stop accepting jobs and wait for queued jobs to drain
+func (p *WorkerPool) Shutdown() {
+
+	//先关 quit 放开等着入队的 Submit，它们释放读锁之后才能拿到写锁
+	p.quitOnce.Do(func() { close(p.quit) })
+
+	p.mutex.Lock()
+	if p.closed {
+		p.mutex.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mutex.Unlock()
+
+	p.workers.Wait()
+}
+
+func (p *WorkerPool) worker() {
+	defer p.workers.Done()
+
+	for job := range p.jobs {
+		job.result <- p.run(job.fn)
+	}
+}
+
+func (p *WorkerPool) run(fn func() error) error {
+
+	work := func(ctx context.Context) (interface{}, error) {
+		return nil, fn()
+	}
+
+	for {
+		_, err := p.rb.Do(work)
+		if err != ErrServiceUnavailable || p.retryInterval <= 0 {
+			return err
+		}
+
+		select {
+		case <-p.rb.clock().After(p.retryInterval):
+		case <-p.quit:
+			return err
+		}
+	}
+}
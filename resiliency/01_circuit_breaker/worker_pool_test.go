@@ -0,0 +1,103 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolRejectsWhileOpen(t *testing.T) {
+
+	rb := NewRequestBreaker(ActionName("jobs"), Timeout(10*time.Millisecond))
+	pool := NewWorkerPool(2, rb)
+	defer pool.Shutdown()
+
+	failing := func() error { return errBackendDown }
+	ok := func() error { return nil }
+
+	//flood failures until the breaker trips
+	var results []<-chan error
+	for i := 0; i < 3; i++ {
+		results = append(results, pool.Submit(failing))
+	}
+	for _, res := range results {
+		if err := <-res; err != errBackendDown {
+			t.Fatalf("expected backend error, got %v", err)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := <-pool.Submit(ok); err != ErrServiceUnavailable {
+			t.Fatalf("job should be rejected while open, got %v", err)
+		}
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if err := <-pool.Submit(ok); err != nil {
+		t.Fatalf("job should run after recovery, got %v", err)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("breaker should be closed, got %s", rb.State())
+	}
+}
+
+func TestWorkerPoolRetryWhenOpen(t *testing.T) {
+
+	rb := NewRequestBreaker(Timeout(10 * time.Millisecond))
+	tripBreaker(t, rb)
+
+	pool := NewWorkerPool(1, rb, RetryWhenOpen(2*time.Millisecond))
+
+	if err := <-pool.Submit(func() error { return nil }); err != nil {
+		t.Fatalf("job should be retried until the breaker recovers, got %v", err)
+	}
+
+	pool.Shutdown()
+	if err := <-pool.Submit(func() error { return nil }); err != ErrPoolClosed {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestWorkerPoolShutdownWithFullQueue(t *testing.T) {
+
+	pool := NewWorkerPool(1, NewRequestBreaker())
+	release := make(chan struct{})
+	running := make(chan struct{})
+	busy := pool.Submit(func() error {
+		close(running)
+		<-release
+		return nil
+	})
+	<-running
+	queued := pool.Submit(func() error { return nil })
+
+	//队列满了，这个 Submit 一直等着入队
+	blocked := make(chan (<-chan error))
+	go func() { blocked <- pool.Submit(func() error { return nil }) }()
+	time.Sleep(10 * time.Millisecond)
+
+	shutdown := make(chan struct{})
+	go func() {
+		pool.Shutdown()
+		close(shutdown)
+	}()
+
+	select {
+	case res := <-blocked:
+		if err := <-res; err != ErrPoolClosed {
+			t.Errorf("the waiting Submit should fail with ErrPoolClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Shutdown deadlocked with a Submit waiting on the full queue")
+	}
+
+	//已经入队的任务照常执行完
+	close(release)
+	<-shutdown
+	if err := <-busy; err != nil {
+		t.Error(err)
+	}
+	if err := <-queued; err != nil {
+		t.Errorf("queued jobs should drain, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 17:39:25
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:39:25
+ */
+
+package circuit
+
+import "context"
+
+////////////////////////////////
+/// 装饰器模式，泛型版本
+/// 被保护的函数是什么签名就返回什么签名，调用方不用改成 interface{} 的 Do
+/// 每种参数个数一个包装函数，没有反射
+////////////////////////////////
+
+//call run fn through rb, the result is the zero value of R when rb rejects the request
+func call[R any](rb *RequestBreaker, fn func() (R, error)) (R, error) {
+	res, err := rb.Do(func(ctx context.Context) (interface{}, error) {
+		return fn()
+	})
+	value, _ := res.(R)
+	return value, err
+}
+
+// Wrap1 returns fn protected by rb, with the same signature.
+// A rejected call returns the zero value of R and the rejection error such as ErrServiceUnavailable,
+// otherwise it returns what fn returned.
+func Wrap1[A, R any](rb *RequestBreaker, fn func(A) (R, error)) func(A) (R, error) {
+	return func(a A) (R, error) {
+		return call(rb, func() (R, error) { return fn(a) })
+	}
+}
+
+//Wrap2 is Wrap1 for a func of two arguments
+func Wrap2[A, B, R any](rb *RequestBreaker, fn func(A, B) (R, error)) func(A, B) (R, error) {
+	return func(a A, b B) (R, error) {
+		return call(rb, func() (R, error) { return fn(a, b) })
+	}
+}
+
+//Wrap3 is Wrap1 for a func of three arguments
+func Wrap3[A, B, C, R any](rb *RequestBreaker, fn func(A, B, C) (R, error)) func(A, B, C) (R, error) {
+	return func(a A, b B, c C) (R, error) {
+		return call(rb, func() (R, error) { return fn(a, b, c) })
+	}
+}
+
+//WrapErr1 is Wrap1 for a func returning only an error
+func WrapErr1[A any](rb *RequestBreaker, fn func(A) error) func(A) error {
+	return func(a A) error {
+		_, err := call(rb, func() (struct{}, error) { return struct{}{}, fn(a) })
+		return err
+	}
+}
+
+//WrapErr2 is WrapErr1 for a func of two arguments
+func WrapErr2[A, B any](rb *RequestBreaker, fn func(A, B) error) func(A, B) error {
+	return func(a A, b B) error {
+		_, err := call(rb, func() (struct{}, error) { return struct{}{}, fn(a, b) })
+		return err
+	}
+}
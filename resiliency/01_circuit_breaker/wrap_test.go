@@ -0,0 +1,61 @@
+package circuit
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestWrap1(t *testing.T) {
+
+	rb := NewRequestBreaker()
+	parse := Wrap1(rb, strconv.Atoi)
+
+	if n, err := parse("42"); n != 42 || err != nil {
+		t.Fatalf("an admitted call should return what fn returned, got %d %v", n, err)
+	}
+	if _, err := parse("x"); !errors.Is(err, strconv.ErrSyntax) {
+		t.Errorf("the error of fn should reach the caller, got %v", err)
+	}
+
+	tripBreaker(t, rb)
+	if n, err := parse("42"); n != 0 || err != ErrServiceUnavailable {
+		t.Errorf("a rejected call should return the zero value, got %d %v", n, err)
+	}
+}
+
+func TestWrap2AndWrapErr2(t *testing.T) {
+
+	type point struct{ X, Y int }
+
+	rb := NewRequestBreaker()
+	calls := 0
+	makePoint := Wrap2(rb, func(x, y int) (*point, error) {
+		calls++
+		return &point{x, y}, nil
+	})
+	store := WrapErr2(rb, func(key string, p *point) error {
+		calls++
+		return nil
+	})
+
+	p, err := makePoint(1, 2)
+	if err != nil || *p != (point{1, 2}) {
+		t.Fatalf("unexpected %v %v", p, err)
+	}
+	if err := store("a", p); err != nil {
+		t.Fatalf("unexpected %v", err)
+	}
+
+	tripBreaker(t, rb)
+	calls = 0
+	if p, err := makePoint(3, 4); p != nil || err != ErrServiceUnavailable {
+		t.Errorf("a rejected call should return a nil pointer, got %v %v", p, err)
+	}
+	if err := store("b", nil); err != ErrServiceUnavailable {
+		t.Errorf("a rejected call should return the rejection error, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("fn should not run when rejected, ran %d times", calls)
+	}
+}
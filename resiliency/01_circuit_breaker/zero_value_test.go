@@ -0,0 +1,35 @@
+package circuit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestZeroValueBreaker(t *testing.T) {
+
+	var rb RequestBreaker
+
+	if rb.State() != StateClosed || rb.HealthScore() != 1 {
+		t.Fatalf("zero value should start closed and healthy, got %v %v", rb.State(), rb.HealthScore())
+	}
+	if rb.options.Name != "defaultBreakerName" || rb.options.Timeout != time.Minute || rb.options.MaxRequests != 5 {
+		t.Errorf("defaults are not applied: %+v", rb.options)
+	}
+
+	tripBreaker(t, &rb)
+	if _, err := rb.Do(succeedWork); err != ErrServiceUnavailable {
+		t.Fatalf("open breaker should reject, got %v", err)
+	}
+
+	//不等默认的60秒，直接让打开状态到期
+	rb.mutex.Lock()
+	rb.setExpiry(time.Now().Add(-time.Second))
+	rb.mutex.Unlock()
+
+	if _, err := rb.Do(succeedWork); err != nil {
+		t.Fatalf("probe should be admitted, got %v", err)
+	}
+	if rb.State() != StateClosed {
+		t.Errorf("breaker should recover, got %v", rb.State())
+	}
+}
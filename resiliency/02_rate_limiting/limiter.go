@@ -0,0 +1,97 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:16:48
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:16:48
+ */
+
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
+)
+
+////////////////////////////////
+/// 令牌桶限流器
+/// 每 every 时间补充一个令牌，最多积攒 burst 个令牌
+/// 令牌按时钟的当前时间计算，不需要后台的 ticker
+////////////////////////////////
+
+//Option set up a Limiter
+type Option func(l *Limiter)
+
+//WithClock set the clock of limiter, clock.Real by default
+func WithClock(c clock.Clock) Option {
+	return func(l *Limiter) {
+		l.clock = c
+	}
+}
+
+//Limiter is a token bucket rate limiter
+type Limiter struct {
+	mutex  sync.Mutex
+	clock  clock.Clock
+	every  time.Duration
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+//NewLimiter return a full limiter which allows one request every interval and bursts of burst requests
+func NewLimiter(every time.Duration, burst int, opts ...Option) *Limiter {
+	l := &Limiter{clock: clock.Real, every: every, burst: burst, tokens: float64(burst)}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.last = l.clock.Now()
+	return l
+}
+
+//Allow take a token if there is one
+func (l *Limiter) Allow() bool {
+	return l.reserve() == 0
+}
+
+//Wait block until a token is taken or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve()
+		if wait == 0 {
+			return nil
+		}
+
+		t := l.clock.NewTimer(wait)
+		select {
+		case <-t.C():
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+//reserve take a token and return 0, or return how long until the next token
+func (l *Limiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.clock.Now()
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += float64(elapsed) / float64(l.every)
+		if l.tokens > float64(l.burst) {
+			l.tokens = float64(l.burst)
+		}
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) * float64(l.every))
+}
@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
+)
+
+func TestLimiterBurstAndRefill(t *testing.T) {
+
+	c := clock.NewManual(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	l := NewLimiter(200*time.Millisecond, 3, WithClock(c))
+
+	for i := 0; i < 3; i++ {
+		if !l.Allow() {
+			t.Fatalf("burst request %d should be allowed", i)
+		}
+	}
+	if l.Allow() {
+		t.Fatal("burst is used up")
+	}
+
+	c.Advance(200 * time.Millisecond)
+	if !l.Allow() || l.Allow() {
+		t.Error("one token should be refilled after one interval")
+	}
+}
+
+func TestLimiterWaitOnClock(t *testing.T) {
+
+	c := clock.NewManual(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	l := NewLimiter(time.Second, 1, WithClock(c))
+	l.Allow()
+
+	done := make(chan error)
+	go func() { done <- l.Wait(context.Background()) }()
+
+	for c.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(time.Second)
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
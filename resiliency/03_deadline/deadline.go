@@ -3,7 +3,7 @@
  * @Author: Edward
  * @Date: 2020-06-05 12:43:39
  * @Last Modified by: Edward
- * @Last Modified time: 2020-06-05 17:34:37
+ * @Last Modified time: 2026-10-14 19:03:05
  */
 
 // Package deadline implements deadline (also known as "timeout") resiliency pattern for Go.
@@ -12,6 +12,8 @@ package deadline
 import (
 	"errors"
 	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
 )
 
 // ErrTimedOut is the error returned from Run when the Worker expires.
@@ -22,14 +24,30 @@ var ErrTimedOut = errors.New("timed out waiting for function to finish")
 type Worker struct {
 	timeout time.Duration
 	action  string
+	clock   clock.Clock
+}
+
+// Option set up a Worker
+type Option func(d *Worker)
+
+// WithClock set the clock measuring the timeout, clock.Real by default
+func WithClock(c clock.Clock) Option {
+	return func(d *Worker) {
+		d.clock = c
+	}
 }
 
 // New create a new Worker with the given timeout.and tile
-func New(timeout time.Duration, someActionTitle string) *Worker {
-	return &Worker{
+func New(timeout time.Duration, someActionTitle string, opts ...Option) *Worker {
+	d := &Worker{
 		timeout: timeout,
 		action:  someActionTitle,
+		clock:   clock.Real,
+	}
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
 }
 
 // Run runs the given function, passing it a stopper channel. If the Worker passes before
@@ -54,11 +72,13 @@ func (d *Worker) Run(work func(stopperSignal chan error) error) error {
 	}()
 
 	//handle result
+	timer := d.clock.NewTimer(d.timeout)
+	defer timer.Stop()
 
 	select {
 	case ret := <-result:
 		return ret
-	case <-time.After(d.timeout):
+	case <-timer.C():
 		close(stopper)
 		return ErrTimedOut
 	}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"testing"
 	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
 )
 
 func workerTakes5ms(stopper chan error) error {
@@ -74,3 +76,35 @@ func TestDeadline(t *testing.T) {
 	<-done
 
 }
+
+func TestDeadlineWithClock(t *testing.T) {
+
+	c := clock.NewManual(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	dl := New(time.Minute, "deadline on a manual clock", WithClock(c))
+
+	release := make(chan struct{})
+	result := make(chan error, 1)
+	go func() {
+		result <- dl.Run(func(stopper chan error) error {
+			<-release
+			return nil
+		})
+	}()
+
+	//等 Run 在时钟上开始计时
+	for c.Waiters() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Advance(59 * time.Second)
+	select {
+	case err := <-result:
+		t.Fatalf("should not time out before the deadline, got %v", err)
+	default:
+	}
+
+	c.Advance(time.Second)
+	if err := <-result; err != ErrTimedOut {
+		t.Errorf("expected ErrTimedOut, got %v", err)
+	}
+	close(release)
+}
@@ -6,6 +6,8 @@ import (
 	"math/rand"
 	"sync"
 	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
 )
 
 // Retrier implements the "retriable" resiliency pattern, abstracting out the process of retrying a failed action
@@ -16,6 +18,7 @@ type Retrier struct {
 	jitter  float64
 	rand    *rand.Rand
 	randMu  sync.Mutex
+	clock   clock.Clock
 }
 
 // New constructs a Retrier with the given backoff pattern and classifier. The length of the backoff pattern
@@ -31,6 +34,7 @@ func New(backoff []time.Duration, class Classifier) *Retrier {
 		backoff: backoff,
 		class:   class,
 		rand:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		clock:   clock.Real,
 	}
 }
 
@@ -60,7 +64,7 @@ func (r *Retrier) RunCtx(ctx context.Context, work func(ctx context.Context) err
 				return ret
 			}
 
-			timeout := time.After(r.calcSleep(retries))
+			timeout := r.clock.After(r.calcSleep(retries))
 			if err := r.sleep(ctx, timeout); err != nil {
 				return err
 			}
@@ -95,3 +99,8 @@ func (r *Retrier) SetJitter(jit float64) {
 	}
 	r.jitter = jit
 }
+
+// SetClock sets the clock used to wait between retries, which is clock.Real by default.
+func (r *Retrier) SetClock(c clock.Clock) {
+	r.clock = c
+}
@@ -3,7 +3,7 @@
  * @Author: Edward
  * @Date: 2020-05-11 17:43:55
  * @Last Modified by: Edward
- * @Last Modified time: 2020-05-11 17:43:55
+ * @Last Modified time: 2026-10-14 16:16:48
  */
 
 package retrier
@@ -13,6 +13,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
 )
 
 var (
@@ -190,3 +192,33 @@ func ExampleRetrier() {
 		// handle the case where the work failed three times
 	}
 }
+
+func TestRetrierClock(t *testing.T) {
+	c := clock.NewManual(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+	r := New(ConstantBackoff(2, time.Minute), nil)
+	r.SetClock(c)
+
+	calls := 0
+	done := make(chan error)
+	go func() {
+		done <- r.Run(func() error {
+			calls++
+			return errFoo
+		})
+	}()
+
+	//每次退避都在手动时钟上等待
+	for i := 0; i < 2; i++ {
+		for c.Waiters() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		c.Advance(time.Minute)
+	}
+
+	if err := <-done; err != errFoo {
+		t.Error(err)
+	}
+	if calls != 3 {
+		t.Error("expected 3 calls, got", calls)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:52:51
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 17:48:14
+ */
+
+package resilient
+
+import (
+	"context"
+	"time"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	retrier "github.com/crazybber/go-fucking-patterns/resiliency/04_retrier"
+)
+
+////////////////////////////////
+/// 外观模式
+/// 大多数应用要的是一个"有弹性的执行器"，而不是自己把四种弹性模式拼起来
+/// 顺序是固定的：隔板(bulkhead)放行 → 断路器 → 超时 → 遇到暂时性错误重试
+////////////////////////////////
+
+//ErrBulkheadFull is returned by Do when MaxConcurrent requests are already running
+var ErrBulkheadFull = circuit.NewRejectionError("bulkhead full")
+
+//Options for ResilientExecutor
+type Options struct {
+	MaxConcurrent int           //隔板，同时执行的请求数，0表示不限制
+	Timeout       time.Duration //断路器放行之后，整个请求(包括所有重试)的超时，0表示不限制
+	Backoff       []time.Duration
+	Classifier    retrier.Classifier //哪些错误是暂时性的，为空时所有错误都重试
+	Breaker       []circuit.Option
+}
+
+//Option set Options
+type Option func(opts *Options)
+
+//WithBulkhead limit how many requests run at the same time
+func WithBulkhead(maxConcurrent int) Option {
+	return func(opts *Options) {
+		opts.MaxConcurrent = maxConcurrent
+	}
+}
+
+//WithTimeout give every admitted request a deadline, the retries share it
+func WithTimeout(d time.Duration) Option {
+	return func(opts *Options) {
+		opts.Timeout = d
+	}
+}
+
+//WithRetry retry the errors classifier says Retry, waiting backoff[i] before the retry i
+func WithRetry(backoff []time.Duration, classifier retrier.Classifier) Option {
+	return func(opts *Options) {
+		opts.Backoff = backoff
+		opts.Classifier = classifier
+	}
+}
+
+//WithBreaker set the options of the inner breaker
+func WithBreaker(opts ...circuit.Option) Option {
+	return func(options *Options) {
+		options.Breaker = append(options.Breaker, opts...)
+	}
+}
+
+//ResilientExecutor run work behind a bulkhead, a breaker, a timeout and a retrier
+type ResilientExecutor struct {
+	options Options
+	slots   chan struct{} //隔板的名额，为空表示不限制
+	breaker *circuit.RequestBreaker
+	retrier *retrier.Retrier
+}
+
+//New return an executor, with no options it only has a default breaker
+func New(opts ...Option) *ResilientExecutor {
+
+	var options Options
+	for _, setOption := range opts {
+		setOption(&options)
+	}
+
+	e := &ResilientExecutor{
+		options: options,
+		breaker: circuit.NewRequestBreaker(options.Breaker...),
+		retrier: retrier.New(options.Backoff, options.Classifier),
+	}
+	if options.MaxConcurrent > 0 {
+		e.slots = make(chan struct{}, options.MaxConcurrent)
+	}
+	return e
+}
+
+//Breaker return the inner breaker, such as to watch its state
+func (e *ResilientExecutor) Breaker() *circuit.RequestBreaker {
+	return e.breaker
+}
+
+// Do runs work through the bulkhead, the breaker, the timeout and the retrier in that order.
+// A request over the bulkhead fails with ErrBulkheadFull and never reaches the breaker,
+// the breaker sees one outcome per request, after all of its retries.
+func (e *ResilientExecutor) Do(ctx context.Context, work func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+
+	//隔板放行，满了直接失败，不排队
+	if e.slots != nil {
+		select {
+		case e.slots <- struct{}{}:
+			defer func() { <-e.slots }()
+		default:
+			return nil, ErrBulkheadFull
+		}
+	}
+
+	return e.breaker.DoContext(ctx, func(ctx context.Context) (interface{}, error) {
+
+		if e.options.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, e.options.Timeout)
+			defer cancel()
+		}
+
+		var result interface{}
+		err := e.retrier.RunCtx(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = work(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	})
+}
@@ -0,0 +1,77 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	retrier "github.com/crazybber/go-fucking-patterns/resiliency/04_retrier"
+)
+
+var errTransient = errors.New("connection reset")
+
+func TestTransientFailureIsRetried(t *testing.T) {
+
+	e := New(
+		WithBulkhead(2),
+		WithTimeout(time.Second),
+		WithRetry(retrier.ConstantBackoff(2, time.Millisecond), retrier.WhitelistClassifier{errTransient}),
+		WithBreaker(circuit.ActionName("orders")),
+	)
+
+	calls := 0
+	res, err := e.Do(context.Background(), func(ctx context.Context) (interface{}, error) {
+		calls++
+		if calls < 3 {
+			return nil, errTransient
+		}
+		return "order", nil
+	})
+
+	if err != nil || res != "order" {
+		t.Fatalf("retries should succeed, got %v %v", res, err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+	if cnt := e.Breaker().Counts(); cnt.TotalSuccesses != 1 || cnt.TotalFailures != 0 {
+		t.Errorf("breaker should see one success for the retried request, got %+v", cnt)
+	}
+}
+
+func TestBulkheadRejectsBeforeBreaker(t *testing.T) {
+
+	e := New(WithBulkhead(1))
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go e.Do(context.Background(), func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+
+	_, err := e.Do(context.Background(), func(ctx context.Context) (interface{}, error) { return nil, nil })
+	close(release)
+
+	if err != ErrBulkheadFull || !circuit.IsRejection(err) {
+		t.Errorf("expected ErrBulkheadFull as a rejection, got %v", err)
+	}
+	if cnt := e.Breaker().Counts(); cnt.Requests != 0 {
+		t.Errorf("rejected request should not reach the breaker, got %+v", cnt)
+	}
+}
+
+func TestTimeoutCoversRetries(t *testing.T) {
+
+	e := New(WithTimeout(20*time.Millisecond), WithRetry(retrier.ConstantBackoff(100, 10*time.Millisecond), nil))
+
+	_, err := e.Do(context.Background(), func(ctx context.Context) (interface{}, error) {
+		return nil, errTransient
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("retries should stop at the timeout, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * @Description: https://github.com/crazybber
+ * @Author: Edward
+ * @Date: 2026-10-14 16:16:48
+ * @Last Modified by: Edward
+ * @Last Modified time: 2026-10-14 16:16:48
+ */
+
+// Package clock is the time source shared by the resiliency patterns,
+// replace the real clock with a ManualClock to travel in time in tests.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//Clock tell the time and make timers
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+//Timer is the part of time.Timer used by the patterns
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+//Real is the clock of the system
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTimer struct{ t *time.Timer }
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+
+func (t realTimer) Stop() bool { return t.t.Stop() }
+
+////////////////////////////////
+/// 手动时钟
+/// 时间只在调用 Advance 时前进，到期的定时器按到期时间的先后依次触发
+////////////////////////////////
+
+//ManualClock only moves when Advance is called
+type ManualClock struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+//NewManual return a ManualClock starting at start
+func NewManual(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+//Now return the current time of clock
+func (c *ManualClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+//After return a channel which receives the time once the clock has advanced d
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+//NewTimer return a timer firing once the clock has advanced d, it fires at once when d <= 0
+func (c *ManualClock) NewTimer(d time.Duration) Timer {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	t := &manualTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+//Waiters return the number of timers which have not fired yet,
+//tests wait on it before Advance to be sure a goroutine is blocked on the clock
+func (c *ManualClock) Waiters() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+//Advance move the clock forward by d, firing due timers in the order of their deadlines,
+//Now() reports the deadline of each timer as it fires
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	target := c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].at.Before(c.timers[j].at) })
+
+	for len(c.timers) > 0 && !c.timers[0].at.After(target) {
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.at
+		t.c <- t.at
+	}
+	c.now = target
+}
+
+//remove drop t from the pending timers, it reports whether t was pending
+func (c *ManualClock) remove(t *manualTimer) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type manualTimer struct {
+	clock *ManualClock
+	at    time.Time
+	c     chan time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time { return t.c }
+
+func (t *manualTimer) Stop() bool { return t.clock.remove(t) }
@@ -0,0 +1,58 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func TestManualClockFiresInOrder(t *testing.T) {
+
+	c := NewManual(start)
+
+	late := c.NewTimer(3 * time.Second)
+	early := c.After(time.Second)
+	stopped := c.NewTimer(2 * time.Second)
+
+	if !stopped.Stop() || stopped.Stop() {
+		t.Error("Stop should report only the first stop of a pending timer")
+	}
+
+	c.Advance(500 * time.Millisecond)
+	select {
+	case <-early:
+		t.Fatal("timer fired too early")
+	default:
+	}
+
+	c.Advance(5 * time.Second)
+	if at := <-early; !at.Equal(start.Add(time.Second)) {
+		t.Errorf("early timer should fire at its deadline, got %v", at)
+	}
+	if at := <-late.C(); !at.Equal(start.Add(3 * time.Second)) {
+		t.Errorf("late timer should fire at its deadline, got %v", at)
+	}
+	select {
+	case <-stopped.C():
+		t.Error("stopped timer should not fire")
+	default:
+	}
+
+	if got := c.Now(); !got.Equal(start.Add(5500 * time.Millisecond)) {
+		t.Errorf("unexpected now %v", got)
+	}
+	if c.Waiters() != 0 || late.Stop() {
+		t.Error("fired timers should not be pending")
+	}
+}
+
+func TestManualClockZeroTimer(t *testing.T) {
+
+	c := NewManual(start)
+	select {
+	case <-c.After(0):
+	default:
+		t.Error("timer of zero duration should fire at once")
+	}
+}
@@ -0,0 +1,57 @@
+package clock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	circuit "github.com/crazybber/go-fucking-patterns/resiliency/01_circuit_breaker"
+	ratelimit "github.com/crazybber/go-fucking-patterns/resiliency/02_rate_limiting"
+	"github.com/crazybber/go-fucking-patterns/resiliency/clock"
+)
+
+var errDown = errors.New("down")
+
+func TestManualClockMovesBreakerAndLimiterTogether(t *testing.T) {
+
+	c := clock.NewManual(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	rb := circuit.NewRequestBreaker(circuit.WithClock(c), circuit.Timeout(time.Second))
+	limiter := ratelimit.NewLimiter(time.Second, 1, ratelimit.WithClock(c))
+	simple := circuit.BreakerWithState(func(ctx context.Context) error { return errDown }, 1, circuit.WithClock(c))
+
+	fail := func(ctx context.Context) (interface{}, error) { return nil, errDown }
+	ok := func(ctx context.Context) (interface{}, error) { return nil, nil }
+
+	for i := 0; i < 3; i++ {
+		rb.Do(fail)
+	}
+	simple(context.Background())
+	limiter.Allow()
+
+	//同一时刻，三者都在拒绝
+	if _, err := rb.Do(ok); err != circuit.ErrServiceUnavailable {
+		t.Fatalf("breaker should be open, got %v", err)
+	}
+	var openErr *circuit.OpenCircuitError
+	if err := simple(context.Background()); !errors.As(err, &openErr) || openErr.RetryAfter() != time.Second {
+		t.Fatalf("simple breaker should wait one second, got %v", err)
+	}
+	if limiter.Allow() {
+		t.Fatal("limiter should be empty")
+	}
+
+	c.Advance(time.Second + time.Nanosecond)
+
+	//one second later, all of them let a request go
+	if _, err := rb.Do(ok); err != nil {
+		t.Errorf("breaker should let a probe go, got %v", err)
+	}
+	if err := simple(context.Background()); err != errDown {
+		t.Errorf("simple breaker should retry the circuit, got %v", err)
+	}
+	if !limiter.Allow() {
+		t.Error("limiter should have a new token")
+	}
+}